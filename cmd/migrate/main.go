@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/thienel/tlog"
+	"go.uber.org/zap"
+
+	"github.com/thienel/go-backend-template/internal/infra/database"
+	"github.com/thienel/go-backend-template/pkg/config"
+)
+
+const usage = `Usage: migrate <command>
+
+Commands:
+  up          Apply all pending migrations
+  down [n]    Revert the last n applied migrations (default 1)
+  status      List migrations and whether they are applied`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Println(usage)
+		os.Exit(2)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	err = tlog.Init(tlog.Config{
+		Environment:   cfg.Server.Env,
+		Level:         cfg.Log.Level,
+		AppName:       cfg.Server.ServiceName,
+		EnableConsole: true,
+	})
+	if err != nil {
+		log.Fatalf("Failed to initialize logger: %v", err)
+	}
+	defer tlog.Sync()
+
+	if err := database.Init(&cfg.Database); err != nil {
+		tlog.Fatal("Failed to initialize database", zap.Error(err))
+	}
+	defer database.Close()
+
+	migrator, err := database.NewMigrator(&cfg.Database)
+	if err != nil {
+		tlog.Fatal("Failed to load migrations", zap.Error(err))
+	}
+
+	ctx := context.Background()
+	switch os.Args[1] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			tlog.Fatal("Migration failed", zap.Error(err))
+		}
+		fmt.Printf("Applied %d migration(s)\n", len(applied))
+
+	case "down":
+		steps := 1
+		if len(os.Args) > 2 {
+			if steps, err = strconv.Atoi(os.Args[2]); err != nil || steps < 1 {
+				fmt.Println(usage)
+				os.Exit(2)
+			}
+		}
+		reverted, err := migrator.Down(ctx, steps)
+		if err != nil {
+			tlog.Fatal("Migration failed", zap.Error(err))
+		}
+		fmt.Printf("Reverted %d migration(s)\n", len(reverted))
+
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			tlog.Fatal("Failed to read migration status", zap.Error(err))
+		}
+		for _, s := range statuses {
+			applied := "pending"
+			if s.AppliedAt != nil {
+				applied = s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d  %-40s %s\n", s.Version, s.Name, applied)
+		}
+
+	default:
+		fmt.Println(usage)
+		os.Exit(2)
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
@@ -11,16 +12,22 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
 	"github.com/thienel/tlog"
 	"go.uber.org/zap"
 
-	"github.com/thienel/go-backend-template/internal/domain/entity"
+	"github.com/thienel/go-backend-template/internal/domain/event"
 	"github.com/thienel/go-backend-template/internal/infra/database"
+	"github.com/thienel/go-backend-template/internal/infra/eventbus"
+	"github.com/thienel/go-backend-template/internal/infra/outbox"
 	"github.com/thienel/go-backend-template/internal/infra/persistence"
+	"github.com/thienel/go-backend-template/internal/infra/webhook"
 	"github.com/thienel/go-backend-template/internal/interface/api/handler"
 	"github.com/thienel/go-backend-template/internal/interface/api/middleware"
 	"github.com/thienel/go-backend-template/internal/interface/api/router"
+	"github.com/thienel/go-backend-template/internal/interface/job"
 	"github.com/thienel/go-backend-template/internal/usecase/service/serviceimpl"
+	"github.com/thienel/go-backend-template/pkg/cache"
 	"github.com/thienel/go-backend-template/pkg/config"
 )
 
@@ -61,15 +68,35 @@ func main() {
 	}
 	defer database.Close()
 
-	// Auto migrate
-	if err := database.AutoMigrate(&entity.User{}); err != nil {
-		tlog.Fatal("Failed to run auto migration", zap.Error(err))
+	// Migrations
+	if err := checkMigrations(&cfg.Database); err != nil {
+		tlog.Fatal("Database migrations failed", zap.Error(err))
 	}
-	tlog.Info("Database migration completed")
 
 	// Initialize repositories
 	db := database.GetDB()
-	userRepo := persistence.NewUserRepository(db)
+	userRepo := persistence.NewUserRepository(db, cfg.Database.SearchTrigram)
+	userStatusHistoryRepo := persistence.NewUserStatusHistoryRepository(db)
+	authEventRepo := persistence.NewAuthEventRepository(db)
+	savedViewRepo := persistence.NewSavedViewRepository(db)
+	auditLogRepo := persistence.NewAuditLogRepository(db)
+	outboxRepo := persistence.NewOutboxRepository(db)
+	webhookSubscriptionRepo := persistence.NewWebhookSubscriptionRepository(db)
+	webhookDeliveryRepo := persistence.NewWebhookDeliveryRepository(db)
+	txManager := persistence.NewTxManager(db)
+
+	// Cache users, read on every authenticated request
+	cacheMetrics := cache.NewMetrics()
+	cacheStore, closeCache := newCacheStore(cfg)
+	defer closeCache()
+	if cacheStore != nil {
+		ttl := time.Duration(cfg.Cache.TTLSeconds) * time.Second
+		userRepo = persistence.NewCachedUserRepository(userRepo, cacheStore, ttl, cacheMetrics)
+	}
+
+	// Initialize event bus; services publish to the outbox, which feeds the bus
+	eventBus := eventbus.New()
+	eventPublisher := outbox.NewPublisher(outboxRepo)
 
 	// Initialize services
 	jwtService := serviceimpl.NewJWTService(
@@ -77,16 +104,48 @@ func main() {
 		cfg.JWT.AccessExpiryMinutes,
 		cfg.JWT.RefreshExpiryHours,
 	)
-	authService := serviceimpl.NewAuthService(userRepo, jwtService)
-	userService := serviceimpl.NewUserService(userRepo)
+	authEventService := serviceimpl.NewAuthEventService(authEventRepo, userRepo)
+	authService := serviceimpl.NewAuthService(userRepo, jwtService, authEventService)
+	userService := serviceimpl.NewUserService(userRepo, userStatusHistoryRepo, txManager, eventPublisher)
+	savedViewService := serviceimpl.NewSavedViewService(savedViewRepo, userRepo)
+	auditLogService := serviceimpl.NewAuditLogService(auditLogRepo, userRepo)
+	webhookService := serviceimpl.NewWebhookService(webhookSubscriptionRepo, webhookDeliveryRepo)
 
 	// Initialize middleware
 	origins := strings.Join(cfg.CORSAllowedOrigins, ",")
-	mw := middleware.New(jwtService, origins)
+	mw := middleware.New(jwtService, authService, origins)
 
 	// Initialize handlers
 	authHandler := handler.NewAuthHandler(authService, userService)
-	userHandler := handler.NewUserHandler(userService)
+	authEventHandler := handler.NewAuthEventHandler(authEventService, savedViewService)
+	userHandler := handler.NewUserHandler(userService, savedViewService)
+	savedViewHandler := handler.NewSavedViewHandler(savedViewService)
+	auditLogHandler := handler.NewAuditLogHandler(auditLogService)
+	webhookHandler := handler.NewWebhookHandler(webhookService)
+	healthHandler := handler.NewHealthHandler(database.NewMonitor(), cacheMetrics)
+
+	// Start background jobs
+	if cfg.Retention.Enabled {
+		retentionJob := job.NewRetentionJob(userService, cfg.Retention)
+		retentionJob.Start()
+		defer retentionJob.Stop()
+	}
+	if cfg.Outbox.DispatchEnabled {
+		targets := []event.Publisher{eventBus, webhook.NewFanout(webhookSubscriptionRepo, webhookDeliveryRepo)}
+		if sink := newEventSink(cfg.Outbox.Sink); sink != nil {
+			defer sink.Close()
+			targets = append(targets, sink)
+		}
+		dispatcher := outbox.NewDispatcher(outboxRepo, cfg.Outbox, targets...)
+		dispatcher.Start()
+		defer dispatcher.Stop()
+	}
+	if cfg.Webhook.DeliveryEnabled {
+		client := webhook.NewClient(time.Duration(cfg.Webhook.TimeoutSeconds)*time.Second, cfg.Webhook.AllowPrivateNetworks)
+		webhookWorker := webhook.NewWorker(webhookSubscriptionRepo, webhookDeliveryRepo, client, cfg.Webhook)
+		webhookWorker.Start()
+		defer webhookWorker.Stop()
+	}
 
 	// Set Gin mode
 	if cfg.IsProduction() {
@@ -94,7 +153,7 @@ func main() {
 	}
 
 	// Setup router
-	engine := router.SetupRouter(authHandler, userHandler, mw)
+	engine := router.SetupRouter(authHandler, authEventHandler, userHandler, savedViewHandler, auditLogHandler, webhookHandler, healthHandler, mw)
 
 	// Create HTTP server
 	srv := &http.Server{
@@ -116,6 +175,23 @@ func main() {
 		}
 	}()
 
+	// Metrics listen apart from the API, so that they can be kept off the public network
+	var metricsSrv *http.Server
+	if cfg.Server.MetricsPort != "" {
+		metricsSrv = &http.Server{
+			Addr:         ":" + cfg.Server.MetricsPort,
+			Handler:      router.SetupMetricsRouter(healthHandler),
+			ReadTimeout:  5 * time.Second,
+			WriteTimeout: 10 * time.Second,
+		}
+		go func() {
+			tlog.Info("Metrics server starting", zap.String("port", cfg.Server.MetricsPort))
+			if err := metricsSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				tlog.Fatal("Failed to start metrics server", zap.Error(err))
+			}
+		}()
+	}
+
 	// Graceful shutdown
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -126,9 +202,93 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
+	if metricsSrv != nil {
+		if err := metricsSrv.Shutdown(ctx); err != nil {
+			tlog.Warn("Metrics server forced to shutdown", zap.Error(err))
+		}
+	}
 	if err := srv.Shutdown(ctx); err != nil {
 		tlog.Fatal("Server forced to shutdown", zap.Error(err))
 	}
 
 	tlog.Info("Server exited gracefully")
 }
+
+// checkMigrations applies pending migrations when configured to, then makes sure none
+// are left pending. Several replicas may migrate at once; the migrator serializes them.
+func checkMigrations(cfg *config.DatabaseConfig) error {
+	migrator, err := database.NewMigrator(cfg)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	if cfg.MigrateOnStart {
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			return err
+		}
+		tlog.Info("Database migration completed", zap.Int("applied", len(applied)))
+	}
+
+	pending, err := migrator.Pending(ctx)
+	if err != nil {
+		return err
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	versions := make([]int64, len(pending))
+	for i, m := range pending {
+		versions[i] = m.Version
+	}
+	if cfg.RequireMigrations {
+		return fmt.Errorf("%d pending migrations %v; run cmd/migrate up", len(pending), versions)
+	}
+	tlog.Warn("Database has pending migrations", zap.Int64s("versions", versions))
+	return nil
+}
+
+// newEventSink opens the sink that also receives domain events: "stdout", a file path,
+// or nil if target is empty
+func newEventSink(target string) *outbox.WriterSink {
+	switch target {
+	case "":
+		return nil
+	case "stdout":
+		return outbox.NewStdoutSink()
+	default:
+		sink, err := outbox.NewFileSink(target)
+		if err != nil {
+			tlog.Fatal("Failed to open event sink", zap.String("path", target), zap.Error(err))
+		}
+		return sink
+	}
+}
+
+// newCacheStore opens the backend of the repository cache, or returns nil when caching
+// is disabled, along with the function that closes it
+func newCacheStore(cfg *config.Config) (cache.Store, func()) {
+	switch cfg.Cache.Backend {
+	case "", "none":
+		return nil, func() {}
+	case "memory":
+		return cache.NewMemory(cfg.Cache.MemoryCapacity), func() {}
+	case "redis":
+		opts, err := redis.ParseURL(cfg.RedisURL)
+		if err != nil {
+			tlog.Fatal("Invalid Redis URL", zap.Error(err))
+		}
+		client := redis.NewClient(opts)
+		closeClient := func() {
+			if err := client.Close(); err != nil {
+				tlog.Warn("Failed to close Redis client", zap.Error(err))
+			}
+		}
+		return cache.NewRedis(client, cfg.Server.ServiceName+":"), closeClient
+	default:
+		tlog.Fatal("Unknown cache backend", zap.String("backend", cfg.Cache.Backend))
+		return nil, nil
+	}
+}
@@ -0,0 +1,58 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Audit actions
+const (
+	AuditActionCreate  = "create"
+	AuditActionUpdate  = "update"
+	AuditActionDelete  = "delete"
+	AuditActionRestore = "restore"
+	AuditActionPurge   = "purge"
+)
+
+// AuditEntityUser is the entity type of audit log entries of users
+const AuditEntityUser = "user"
+
+// AuditRedacted replaces the values of sensitive fields, tagged audit:"redact", in changes
+const AuditRedacted = "[REDACTED]"
+
+// AuditLog records a change to an entity: who made it, from where, and what changed
+type AuditLog struct {
+	ID         uint     `gorm:"primaryKey" json:"id"`
+	PublicID   PublicID `gorm:"uniqueIndex;not null" json:"public_id"`
+	ActorID    *uint    `gorm:"index" json:"actor_id,omitempty"`
+	Action     string   `gorm:"size:20;not null;index" json:"action"`
+	EntityType string   `gorm:"size:50;not null;index:idx_audit_logs_entity" json:"entity_type"`
+	// EntityID is the public ID of entities that have one, and the primary key of others
+	EntityID string `gorm:"size:64;not null;index:idx_audit_logs_entity" json:"entity_id"`
+	// Changes maps each changed column to its values before and after
+	Changes       map[string]AuditChange `gorm:"type:json;serializer:json" json:"changes"`
+	ChangedFields []string               `gorm:"type:json;serializer:json" json:"changed_fields"`
+	RequestID     string                 `gorm:"size:64" json:"request_id,omitempty"`
+	IP            string                 `gorm:"size:45" json:"ip,omitempty"`
+	CreatedAt     time.Time              `gorm:"index" json:"created_at"`
+
+	// ActorPublicID is the public ID of the actor, set when listed
+	ActorPublicID *PublicID `gorm:"-" json:"-"`
+}
+
+// BeforeCreate assigns the public ID of a new entry
+func (l *AuditLog) BeforeCreate(*gorm.DB) error {
+	if l.PublicID.IsZero() {
+		l.PublicID = NewPublicID()
+	}
+	return nil
+}
+
+// AuditChange is the JSON value of a column before and after a change; Before is
+// absent for creations and After for deletions
+type AuditChange struct {
+	Before json.RawMessage `json:"before,omitempty"`
+	After  json.RawMessage `json:"after,omitempty"`
+}
@@ -0,0 +1,45 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Auth event types
+const (
+	AuthEventLoginSuccess   = "LOGIN_SUCCESS"
+	AuthEventLoginFailure   = "LOGIN_FAILURE"
+	AuthEventLogout         = "LOGOUT"
+	AuthEventTokenRefresh   = "TOKEN_REFRESH"
+	AuthEventPasswordChange = "PASSWORD_CHANGE"
+	AuthEventMFAChallenge   = "MFA_CHALLENGE"
+	AuthEventMFASuccess     = "MFA_SUCCESS"
+	AuthEventMFAFailure     = "MFA_FAILURE"
+)
+
+// AuthEvent records an authentication-related action for auditing and login history
+type AuthEvent struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	PublicID  PublicID  `gorm:"uniqueIndex;not null" json:"public_id"`
+	UserID    *uint     `gorm:"index" json:"user_id,omitempty"`
+	Username  string    `gorm:"size:50;index" json:"username"`
+	Type      string    `gorm:"size:30;index;not null" json:"type"`
+	Success   bool      `gorm:"not null" json:"success"`
+	Reason    string    `gorm:"size:255" json:"reason,omitempty"`
+	IP        string    `gorm:"size:45" json:"ip"`
+	UserAgent string    `gorm:"size:255" json:"user_agent"`
+	RequestID string    `gorm:"size:64" json:"request_id,omitempty"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+
+	// UserPublicID is the public ID of the user, set when listed
+	UserPublicID *PublicID `gorm:"-" json:"-"`
+}
+
+// BeforeCreate assigns the public ID of a new event
+func (e *AuthEvent) BeforeCreate(*gorm.DB) error {
+	if e.PublicID.IsZero() {
+		e.PublicID = NewPublicID()
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Outbox message statuses
+const (
+	OutboxStatusPending   = "pending"
+	OutboxStatusDelivered = "delivered"
+	OutboxStatusFailed    = "failed"
+)
+
+// OutboxMessage is a domain event stored in the transaction that raised it, waiting to be
+// delivered. A failed message ran out of delivery attempts.
+type OutboxMessage struct {
+	ID         uint            `gorm:"primaryKey" json:"id"`
+	EventName  string          `gorm:"size:100;not null;index" json:"event_name"`
+	Payload    json.RawMessage `gorm:"type:json;not null" json:"payload"`
+	OccurredAt time.Time       `gorm:"not null" json:"occurred_at"`
+	Status     string          `gorm:"size:20;not null;default:pending;index:idx_outbox_messages_due" json:"status"`
+	Attempts   int             `gorm:"not null;default:0" json:"attempts"`
+	// NextAttemptAt is when the message is due; claiming a message pushes it forward by a lease
+	NextAttemptAt time.Time  `gorm:"not null;index:idx_outbox_messages_due" json:"next_attempt_at"`
+	LastError     string     `gorm:"size:500" json:"last_error,omitempty"`
+	DeliveredAt   *time.Time `json:"delivered_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
@@ -0,0 +1,67 @@
+package entity
+
+import (
+	"database/sql/driver"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// PublicID identifies an entity to API clients in place of its sequential primary key,
+// which would reveal how many rows there are and invite enumeration. New IDs are
+// UUIDv7: unguessable, yet ordered by creation time, which keeps their index compact.
+type PublicID uuid.UUID
+
+// NewPublicID returns a new time-ordered public ID
+func NewPublicID() PublicID {
+	return PublicID(uuid.Must(uuid.NewV7()))
+}
+
+// ParsePublicID parses the textual form of a public ID
+func ParsePublicID(s string) (PublicID, error) {
+	id, err := uuid.Parse(s)
+	if err != nil {
+		return PublicID{}, err
+	}
+	return PublicID(id), nil
+}
+
+// IsZero reports whether the ID has not been assigned
+func (id PublicID) IsZero() bool {
+	return id == PublicID{}
+}
+
+func (id PublicID) String() string {
+	return uuid.UUID(id).String()
+}
+
+// MarshalText encodes the ID in its canonical form, also in JSON
+func (id PublicID) MarshalText() ([]byte, error) {
+	return uuid.UUID(id).MarshalText()
+}
+
+func (id *PublicID) UnmarshalText(data []byte) error {
+	return (*uuid.UUID)(id).UnmarshalText(data)
+}
+
+// Value stores the ID as text, which Postgres converts to its uuid type
+func (id PublicID) Value() (driver.Value, error) {
+	return id.String(), nil
+}
+
+func (id *PublicID) Scan(src any) error {
+	return (*uuid.UUID)(id).Scan(src)
+}
+
+// GormDBDataType is the column type of public IDs on each database
+func (PublicID) GormDBDataType(db *gorm.DB, _ *schema.Field) string {
+	switch db.Dialector.Name() {
+	case "postgres":
+		return "uuid"
+	case "mysql":
+		return "char(36)"
+	default:
+		return "text"
+	}
+}
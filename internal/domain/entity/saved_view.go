@@ -0,0 +1,55 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/thienel/go-backend-template/pkg/query"
+)
+
+// Saved view resources, i.e. the list endpoints a view can be applied to
+const (
+	SavedViewResourceUsers      = "users"
+	SavedViewResourceAuthEvents = "auth_events"
+)
+
+// SavedView is a named filter and sort combination for a list endpoint. Views are
+// private to their owner unless shared.
+type SavedView struct {
+	ID        uint               `gorm:"primaryKey" json:"id"`
+	PublicID  PublicID           `gorm:"uniqueIndex;not null" json:"public_id"`
+	OwnerID   uint               `gorm:"not null;uniqueIndex:idx_saved_views_owner_resource_name" json:"owner_id"`
+	Resource  string             `gorm:"size:50;not null;uniqueIndex:idx_saved_views_owner_resource_name" json:"resource"`
+	Name      string             `gorm:"size:100;not null;uniqueIndex:idx_saved_views_owner_resource_name" json:"name"`
+	Shared    bool               `gorm:"not null;default:false;index" json:"shared"`
+	Options   query.QueryOptions `gorm:"type:json;serializer:json;not null" json:"options"`
+	CreatedAt time.Time          `json:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at"`
+
+	// OwnerPublicID is the public ID of the owner, set when read through the service
+	OwnerPublicID PublicID `gorm:"-" json:"-"`
+}
+
+// BeforeCreate assigns the public ID of a new view
+func (v *SavedView) BeforeCreate(*gorm.DB) error {
+	if v.PublicID.IsZero() {
+		v.PublicID = NewPublicID()
+	}
+	return nil
+}
+
+// IsValidSavedViewResource checks if the resource accepts saved views
+func IsValidSavedViewResource(resource string) bool {
+	switch resource {
+	case SavedViewResourceUsers, SavedViewResourceAuthEvents:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsVisibleTo reports whether the user may see and apply the view
+func (v *SavedView) IsVisibleTo(userID uint) bool {
+	return v.Shared || v.OwnerID == userID
+}
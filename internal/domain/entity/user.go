@@ -13,23 +13,40 @@ const (
 	UserRoleSystemAdmin = "SYSTEM_ADMIN"
 )
 
-// User statuses
-const (
-	UserStatusActive   = "ACTIVE"
-	UserStatusInactive = "INACTIVE"
-)
-
 // User represents the user entity
 type User struct {
 	ID        uint           `gorm:"primaryKey" json:"id"`
+	PublicID  PublicID       `gorm:"uniqueIndex;not null" json:"public_id"`
 	Username  string         `gorm:"uniqueIndex;size:50;not null" json:"username"`
 	Email     string         `gorm:"uniqueIndex;size:255;not null" json:"email"`
-	Password  string         `gorm:"size:255;not null" json:"-"`
+	Password  string         `gorm:"size:255;not null" json:"-" audit:"redact"`
 	Role      string         `gorm:"size:20;default:'USER'" json:"role"`
 	Status    string         `gorm:"size:20;default:'ACTIVE'" json:"status"`
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+
+	// Version increases with every update, for optimistic locking
+	Version uint `gorm:"not null;default:1" json:"version"`
+
+	// Status details, set by the last status transition
+	StatusReason   string     `gorm:"size:255" json:"status_reason,omitempty"`
+	SuspendedUntil *time.Time `json:"suspended_until,omitempty"`
+
+	// Last successful login; written on login without bumping the version
+	LastLoginAt *time.Time `json:"last_login_at,omitempty" version:"skip"`
+	LastLoginIP string     `gorm:"size:45" json:"last_login_ip,omitempty" version:"skip"`
+
+	// Loaded only on request; history rows outlive purged users, so no foreign key constraint
+	StatusHistory []UserStatusHistory `gorm:"foreignKey:UserID;constraint:-" json:"status_history,omitempty"`
+}
+
+// BeforeCreate assigns the public ID of a new user
+func (u *User) BeforeCreate(*gorm.DB) error {
+	if u.PublicID.IsZero() {
+		u.PublicID = NewPublicID()
+	}
+	return nil
 }
 
 // IsValidUserRole checks if the role is valid
@@ -41,13 +58,3 @@ func IsValidUserRole(role string) bool {
 		return false
 	}
 }
-
-// IsValidUserStatus checks if the status is valid
-func IsValidUserStatus(status string) bool {
-	switch status {
-	case UserStatusActive, UserStatusInactive:
-		return true
-	default:
-		return false
-	}
-}
@@ -0,0 +1,119 @@
+package entity
+
+import (
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// User statuses
+const (
+	UserStatusPending     = "PENDING"
+	UserStatusActive      = "ACTIVE"
+	UserStatusSuspended   = "SUSPENDED"
+	UserStatusLocked      = "LOCKED"
+	UserStatusDeactivated = "DEACTIVATED"
+)
+
+// UserStatusTransition describes an allowed move in the user status state machine
+type UserStatusTransition struct {
+	Name  string
+	Event string
+	From  []string
+	To    string
+}
+
+// User status transitions
+var (
+	UserTransitionActivate = UserStatusTransition{
+		Name:  "activate",
+		Event: "user.activated",
+		From:  []string{UserStatusPending},
+		To:    UserStatusActive,
+	}
+	UserTransitionSuspend = UserStatusTransition{
+		Name:  "suspend",
+		Event: "user.suspended",
+		From:  []string{UserStatusActive},
+		To:    UserStatusSuspended,
+	}
+	UserTransitionUnsuspend = UserStatusTransition{
+		Name:  "unsuspend",
+		Event: "user.unsuspended",
+		From:  []string{UserStatusSuspended},
+		To:    UserStatusActive,
+	}
+	UserTransitionLock = UserStatusTransition{
+		Name:  "lock",
+		Event: "user.locked",
+		From:  []string{UserStatusActive, UserStatusSuspended},
+		To:    UserStatusLocked,
+	}
+	UserTransitionUnlock = UserStatusTransition{
+		Name:  "unlock",
+		Event: "user.unlocked",
+		From:  []string{UserStatusLocked},
+		To:    UserStatusActive,
+	}
+	UserTransitionDeactivate = UserStatusTransition{
+		Name:  "deactivate",
+		Event: "user.deactivated",
+		From:  []string{UserStatusPending, UserStatusActive, UserStatusSuspended, UserStatusLocked},
+		To:    UserStatusDeactivated,
+	}
+	UserTransitionReactivate = UserStatusTransition{
+		Name:  "reactivate",
+		Event: "user.reactivated",
+		From:  []string{UserStatusDeactivated},
+		To:    UserStatusActive,
+	}
+)
+
+// Allows checks if the transition can start from the given status
+func (t UserStatusTransition) Allows(from string) bool {
+	return slices.Contains(t.From, from)
+}
+
+// UserStatusHistory records a single status transition of a user
+type UserStatusHistory struct {
+	ID             uint       `gorm:"primaryKey" json:"id"`
+	PublicID       PublicID   `gorm:"uniqueIndex;not null" json:"public_id"`
+	UserID         uint       `gorm:"index;not null" json:"user_id"`
+	Transition     string     `gorm:"size:20;not null" json:"transition"`
+	FromStatus     string     `gorm:"size:20;not null" json:"from_status"`
+	ToStatus       string     `gorm:"size:20;not null" json:"to_status"`
+	Reason         string     `gorm:"size:255" json:"reason,omitempty"`
+	SuspendedUntil *time.Time `json:"suspended_until,omitempty"`
+	ActorID        *uint      `json:"actor_id,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+
+	// ActorPublicID is the public ID of the actor, set when read through the user service
+	ActorPublicID *PublicID `gorm:"-" json:"-"`
+}
+
+// BeforeCreate assigns the public ID of a new entry
+func (h *UserStatusHistory) BeforeCreate(*gorm.DB) error {
+	if h.PublicID.IsZero() {
+		h.PublicID = NewPublicID()
+	}
+	return nil
+}
+
+// IsValidUserStatus checks if the status is valid
+func IsValidUserStatus(status string) bool {
+	switch status {
+	case UserStatusPending, UserStatusActive, UserStatusSuspended, UserStatusLocked, UserStatusDeactivated:
+		return true
+	default:
+		return false
+	}
+}
+
+// EffectiveStatus returns the user status at the given time, treating an expired suspension as active
+func (u *User) EffectiveStatus(now time.Time) string {
+	if u.Status == UserStatusSuspended && u.SuspendedUntil != nil && !now.Before(*u.SuspendedUntil) {
+		return UserStatusActive
+	}
+	return u.Status
+}
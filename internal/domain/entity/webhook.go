@@ -0,0 +1,80 @@
+package entity
+
+import (
+	"encoding/json"
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// WebhookEventAll subscribes a webhook to every event
+const WebhookEventAll = "*"
+
+// Webhook delivery statuses; a dead delivery ran out of attempts and is only sent again
+// when redelivered by hand
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliveryDelivered = "delivered"
+	WebhookDeliveryDead      = "dead"
+)
+
+// WebhookSubscription is an endpoint that receives the domain events it subscribed to,
+// signed with its secret
+type WebhookSubscription struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	PublicID    PublicID  `gorm:"uniqueIndex;not null" json:"public_id"`
+	URL         string    `gorm:"size:500;not null" json:"url"`
+	EventTypes  []string  `gorm:"type:json;serializer:json;not null" json:"event_types"`
+	Secret      string    `gorm:"size:100;not null" json:"-" audit:"redact"`
+	Description string    `gorm:"size:255" json:"description,omitempty"`
+	Active      bool      `gorm:"not null;default:true;index" json:"active"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// BeforeCreate assigns the public ID of a new subscription
+func (s *WebhookSubscription) BeforeCreate(*gorm.DB) error {
+	if s.PublicID.IsZero() {
+		s.PublicID = NewPublicID()
+	}
+	return nil
+}
+
+// Subscribes checks if the subscription receives events with the given name
+func (s *WebhookSubscription) Subscribes(eventName string) bool {
+	return slices.Contains(s.EventTypes, eventName) || slices.Contains(s.EventTypes, WebhookEventAll)
+}
+
+// WebhookDelivery is an event sent, or to be sent, to a subscription, with the outcome of
+// its last attempt
+type WebhookDelivery struct {
+	ID             uint     `gorm:"primaryKey" json:"id"`
+	PublicID       PublicID `gorm:"uniqueIndex;not null" json:"public_id"`
+	SubscriptionID uint     `gorm:"not null;uniqueIndex:idx_webhook_deliveries_subscription_message" json:"subscription_id"`
+	MessageID      uint     `gorm:"not null;uniqueIndex:idx_webhook_deliveries_subscription_message" json:"message_id"`
+	EventName      string   `gorm:"size:100;not null;index" json:"event_name"`
+	// Payload is the request body, as signed
+	Payload       json.RawMessage `gorm:"type:json;not null" json:"payload"`
+	Status        string          `gorm:"size:20;not null;default:pending;index:idx_webhook_deliveries_due" json:"status"`
+	Attempts      int             `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt time.Time       `gorm:"not null;index:idx_webhook_deliveries_due" json:"next_attempt_at"`
+	ResponseCode  *int            `json:"response_code,omitempty"`
+	ResponseBody  string          `gorm:"size:1000" json:"response_body,omitempty"`
+	LastError     string          `gorm:"size:500" json:"last_error,omitempty"`
+	DurationMs    int64           `json:"duration_ms"`
+	DeliveredAt   *time.Time      `json:"delivered_at,omitempty"`
+	CreatedAt     time.Time       `gorm:"index" json:"created_at"`
+	UpdatedAt     time.Time       `json:"updated_at"`
+
+	// SubscriptionPublicID is the public ID of the subscription, set when read through the service
+	SubscriptionPublicID PublicID `gorm:"-" json:"-"`
+}
+
+// BeforeCreate assigns the public ID of a new delivery
+func (d *WebhookDelivery) BeforeCreate(*gorm.DB) error {
+	if d.PublicID.IsZero() {
+		d.PublicID = NewPublicID()
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// Event is a domain event raised by the use case layer
+type Event interface {
+	EventName() string
+	OccurredAt() time.Time
+}
+
+// Handler handles a published domain event
+type Handler func(ctx context.Context, evt Event) error
+
+// Publisher publishes domain events to interested subscribers
+type Publisher interface {
+	Publish(ctx context.Context, events ...Event) error
+}
+
+// Subscriber registers handlers for domain events by name ("*" matches all events)
+type Subscriber interface {
+	Subscribe(eventName string, handler Handler)
+}
+
+// Message is an event as delivered from the outbox, with its payload still encoded.
+// Delivery is at least once, so handlers should use ID to recognize redeliveries.
+type Message struct {
+	ID      uint            `json:"id"`
+	Name    string          `json:"event"`
+	Payload json.RawMessage `json:"payload"`
+	Time    time.Time       `json:"occurred_at"`
+}
+
+func (m Message) EventName() string     { return m.Name }
+func (m Message) OccurredAt() time.Time { return m.Time }
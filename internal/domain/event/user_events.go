@@ -0,0 +1,91 @@
+package event
+
+import "time"
+
+// UserStatusChanged is raised whenever a user goes through a status transition
+type UserStatusChanged struct {
+	// UserID and ActorID stay internal; payloads carry the IDs that API clients know
+	// users by, here and in the other user events
+	UserID         uint       `json:"-"`
+	PublicID       string     `json:"public_id"`
+	Transition     string     `json:"transition"`
+	Name           string     `json:"-"`
+	FromStatus     string     `json:"from_status"`
+	ToStatus       string     `json:"to_status"`
+	Reason         string     `json:"reason,omitempty"`
+	SuspendedUntil *time.Time `json:"suspended_until,omitempty"`
+	ActorID        uint       `json:"-"`
+	ActorPublicID  string     `json:"actor_id,omitempty"`
+	Timestamp      time.Time  `json:"timestamp"`
+}
+
+func (e UserStatusChanged) EventName() string     { return e.Name }
+func (e UserStatusChanged) OccurredAt() time.Time { return e.Timestamp }
+
+// User event names other than status transitions, which are named by the transition
+const (
+	UserCreatedEvent     = "user.created"
+	UserUpdatedEvent     = "user.updated"
+	UserRoleChangedEvent = "user.role_changed"
+	UserDeletedEvent     = "user.deleted"
+	UserRestoredEvent    = "user.restored"
+	UserPurgedEvent      = "user.purged"
+)
+
+// UserCreated is raised when a user is created
+type UserCreated struct {
+	UserID        uint      `json:"-"`
+	PublicID      string    `json:"public_id"`
+	Username      string    `json:"username"`
+	Email         string    `json:"email"`
+	Role          string    `json:"role"`
+	Status        string    `json:"status"`
+	ActorID       uint      `json:"-"`
+	ActorPublicID string    `json:"actor_id,omitempty"`
+	Timestamp     time.Time `json:"timestamp"`
+}
+
+func (e UserCreated) EventName() string     { return UserCreatedEvent }
+func (e UserCreated) OccurredAt() time.Time { return e.Timestamp }
+
+// UserUpdated is raised when the profile of a user changes
+type UserUpdated struct {
+	UserID        uint      `json:"-"`
+	PublicID      string    `json:"public_id"`
+	ChangedFields []string  `json:"changed_fields"`
+	Version       uint      `json:"version"`
+	ActorID       uint      `json:"-"`
+	ActorPublicID string    `json:"actor_id,omitempty"`
+	Timestamp     time.Time `json:"timestamp"`
+}
+
+func (e UserUpdated) EventName() string     { return UserUpdatedEvent }
+func (e UserUpdated) OccurredAt() time.Time { return e.Timestamp }
+
+// UserRoleChanged is raised along with UserUpdated when the role of a user changes
+type UserRoleChanged struct {
+	UserID        uint      `json:"-"`
+	PublicID      string    `json:"public_id"`
+	FromRole      string    `json:"from_role"`
+	ToRole        string    `json:"to_role"`
+	ActorID       uint      `json:"-"`
+	ActorPublicID string    `json:"actor_id,omitempty"`
+	Timestamp     time.Time `json:"timestamp"`
+}
+
+func (e UserRoleChanged) EventName() string     { return UserRoleChangedEvent }
+func (e UserRoleChanged) OccurredAt() time.Time { return e.Timestamp }
+
+// UserLifecycleChanged is raised when a user is deleted, restored or purged; Name is one
+// of UserDeletedEvent, UserRestoredEvent and UserPurgedEvent
+type UserLifecycleChanged struct {
+	Name          string    `json:"-"`
+	UserID        uint      `json:"-"`
+	PublicID      string    `json:"public_id"`
+	ActorID       uint      `json:"-"`
+	ActorPublicID string    `json:"actor_id,omitempty"`
+	Timestamp     time.Time `json:"timestamp"`
+}
+
+func (e UserLifecycleChanged) EventName() string     { return e.Name }
+func (e UserLifecycleChanged) OccurredAt() time.Time { return e.Timestamp }
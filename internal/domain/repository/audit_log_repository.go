@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"github.com/thienel/go-backend-template/internal/domain/entity"
+	"github.com/thienel/go-backend-template/pkg/query"
+)
+
+// AuditLogQuerySchema declares the audit log fields that can be filtered and sorted on.
+// actor_id takes public user IDs, resolved by the service; entity_id takes the IDs
+// recorded in the log; changed_fields takes a JSON array, e.g.
+// changed_fields[contains]=["role"].
+var AuditLogQuerySchema = query.Schema{
+	"id":       {Type: query.FieldUUID, Column: "public_id"},
+	"actor_id": {Type: query.FieldUUID, Operators: []string{"eq", "ne", "in", "nin", "isnull", "notnull"}, Nullable: true},
+	"action": {Type: query.FieldEnum, Values: []string{
+		entity.AuditActionCreate, entity.AuditActionUpdate, entity.AuditActionDelete,
+		entity.AuditActionRestore, entity.AuditActionPurge,
+	}, Facetable: true},
+	"entity_type":    {Type: query.FieldString, Operators: []string{"eq", "ne", "in", "nin"}, Facetable: true},
+	"entity_id":      {Type: query.FieldString, Operators: []string{"eq", "ne", "in", "nin"}},
+	"changed_fields": {Type: query.FieldJSON, Operators: []string{"contains"}},
+	"ip":             {Type: query.FieldString, Operators: []string{"eq", "ne", "in", "nin"}},
+	"request_id":     {Type: query.FieldString, Operators: []string{"eq"}},
+	"created_at":     {Type: query.FieldTime, Operators: timeOperators, Aggregatable: true},
+}
+
+// AuditLogProjection declares the audit log fields clients may select
+var AuditLogProjection = query.Projection{
+	Fields: map[string]string{
+		"id":             "public_id",
+		"actor_id":       "actor_id",
+		"action":         "action",
+		"entity_type":    "entity_type",
+		"entity_id":      "entity_id",
+		"changes":        "changes",
+		"changed_fields": "changed_fields",
+		"request_id":     "request_id",
+		"ip":             "ip",
+		"created_at":     "created_at",
+	},
+	Required: []string{"id"},
+}
+
+// AuditLogRepository stores the audit trail of entity changes
+type AuditLogRepository interface {
+	BaseRepository[entity.AuditLog, uint]
+}
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"github.com/thienel/go-backend-template/internal/domain/entity"
+	"github.com/thienel/go-backend-template/pkg/query"
+)
+
+// AuthEventQuerySchema declares the auth event fields that can be filtered and sorted on.
+// user_id takes public user IDs, resolved by the service.
+var AuthEventQuerySchema = query.Schema{
+	"id":       {Type: query.FieldUUID, Column: "public_id"},
+	"user_id":  {Type: query.FieldUUID, Operators: []string{"eq", "ne", "in", "nin", "isnull", "notnull"}, Nullable: true},
+	"username": {Type: query.FieldString, Operators: textOperators},
+	"type": {Type: query.FieldEnum, Values: []string{
+		entity.AuthEventLoginSuccess, entity.AuthEventLoginFailure, entity.AuthEventLogout,
+		entity.AuthEventTokenRefresh, entity.AuthEventPasswordChange,
+		entity.AuthEventMFAChallenge, entity.AuthEventMFASuccess, entity.AuthEventMFAFailure,
+	}, Facetable: true},
+	"success":    {Type: query.FieldBool, Facetable: true},
+	"ip":         {Type: query.FieldString, Operators: []string{"eq", "ne", "in", "nin"}},
+	"request_id": {Type: query.FieldString, Operators: []string{"eq"}},
+	"created_at": {Type: query.FieldTime, Operators: timeOperators, Aggregatable: true},
+}
+
+// AuthEventProjection declares the auth event fields clients may select
+var AuthEventProjection = query.Projection{
+	Fields: map[string]string{
+		"id":         "public_id",
+		"user_id":    "user_id",
+		"username":   "username",
+		"type":       "type",
+		"success":    "success",
+		"reason":     "reason",
+		"ip":         "ip",
+		"user_agent": "user_agent",
+		"request_id": "request_id",
+		"created_at": "created_at",
+	},
+	Required: []string{"id"},
+}
+
+// AuthEventRepository stores authentication events
+type AuthEventRepository interface {
+	BaseRepository[entity.AuthEvent, uint]
+}
@@ -6,12 +6,22 @@ import (
 	"github.com/thienel/go-backend-template/pkg/query"
 )
 
-// BaseRepository is a generic repository interface
-type BaseRepository[T any] interface {
+// Operator sets shared by the query schemas
+var (
+	textOperators = []string{"eq", "ne", "like", "clike", "startswith", "endswith", "in", "nin"}
+	timeOperators = []string{"eq", "ne", "gt", "gte", "lt", "lte", "between"}
+)
+
+// BaseRepository is a generic repository interface for entities of type T with primary
+// keys of type ID
+type BaseRepository[T any, ID comparable] interface {
 	Create(ctx context.Context, entity *T) error
-	FindByID(ctx context.Context, id uint) (*T, error)
+	FindByID(ctx context.Context, id ID) (*T, error)
+	FindByIDWithOptions(ctx context.Context, id ID, opts query.QueryOptions) (*T, error)
 	Update(ctx context.Context, entity *T) error
-	Delete(ctx context.Context, id uint) error
-	List(ctx context.Context, offset, limit int, opts query.QueryOptions) ([]T, int64, error)
-	Exists(ctx context.Context, id uint) (bool, error)
+	Delete(ctx context.Context, id ID) error
+	// DeleteVersion deletes an entity only if its version matches; see optimistic locking in Update
+	DeleteVersion(ctx context.Context, id ID, version uint) error
+	List(ctx context.Context, page query.Pagination, opts query.QueryOptions) (query.Page[T], error)
+	Exists(ctx context.Context, id ID) (bool, error)
 }
@@ -0,0 +1,17 @@
+package repository
+
+import "context"
+
+type readYourWritesKey struct{}
+
+// WithReadYourWrites returns a context whose reads go to the primary database, so that
+// they see writes made just before, which read replicas may not have applied yet
+func WithReadYourWrites(ctx context.Context) context.Context {
+	return context.WithValue(ctx, readYourWritesKey{}, true)
+}
+
+// ReadsYourWrites checks if reads in ctx must go to the primary database
+func ReadsYourWrites(ctx context.Context) bool {
+	v, _ := ctx.Value(readYourWritesKey{}).(bool)
+	return v
+}
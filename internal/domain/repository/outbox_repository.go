@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/thienel/go-backend-template/internal/domain/entity"
+)
+
+// OutboxRepository stores domain events until they are delivered
+type OutboxRepository interface {
+	// Add stores messages, in the transaction of ctx if there is one
+	Add(ctx context.Context, messages ...*entity.OutboxMessage) error
+
+	// ClaimDue returns up to limit pending messages that are due and counts an attempt for
+	// each. They are not due again until lease has passed, so concurrent dispatchers do not
+	// claim the same message and a crashed dispatcher's messages are retried.
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]entity.OutboxMessage, error)
+
+	MarkDelivered(ctx context.Context, id uint) error
+
+	// MarkRetry schedules another attempt, or marks the message failed if next is nil
+	MarkRetry(ctx context.Context, id uint, next *time.Time, lastError string) error
+}
@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/thienel/go-backend-template/internal/domain/entity"
+	"github.com/thienel/go-backend-template/pkg/query"
+)
+
+// SavedViewQuerySchema declares the saved view fields that can be filtered and sorted on.
+// owner_id takes public user IDs, resolved by the service.
+var SavedViewQuerySchema = query.Schema{
+	"id":       {Type: query.FieldUUID, Column: "public_id"},
+	"owner_id": {Type: query.FieldUUID},
+	"resource": {Type: query.FieldEnum, Values: []string{
+		entity.SavedViewResourceUsers, entity.SavedViewResourceAuthEvents,
+	}},
+	"name":       {Type: query.FieldString, Operators: textOperators},
+	"shared":     {Type: query.FieldBool},
+	"created_at": {Type: query.FieldTime, Operators: timeOperators},
+	"updated_at": {Type: query.FieldTime, Operators: timeOperators},
+}
+
+// SavedViewRepository stores saved views
+type SavedViewRepository interface {
+	BaseRepository[entity.SavedView, uint]
+
+	// FindIDByPublicID returns the ID of the view with the given public ID
+	FindIDByPublicID(ctx context.Context, publicID entity.PublicID) (uint, error)
+}
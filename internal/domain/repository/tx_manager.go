@@ -0,0 +1,13 @@
+package repository
+
+import "context"
+
+// TxManager runs a unit of work that spans several repositories in one transaction.
+// Repository calls made with the ctx passed to fn join the transaction.
+type TxManager interface {
+	// WithinTx runs fn in a transaction, committing if it returns nil and rolling back
+	// otherwise. Called inside another WithinTx, fn runs in a savepoint, so its failure
+	// only undoes its own changes. The outermost transaction is retried on serialization
+	// failures, deadlocks and lock timeouts, so fn may run more than once.
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
@@ -2,22 +2,78 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/thienel/go-backend-template/internal/domain/entity"
 	"github.com/thienel/go-backend-template/pkg/query"
 )
 
+// UserQuerySchema declares the user fields that can be filtered and sorted on.
+// id is the public ID of a user; the primary key is never exposed.
+// search is a full-text search over username and email (see persistence.userSearch).
+// role and status can be faceted, and the timestamps aggregated with min/max.
+var UserQuerySchema = query.Schema{
+	"id":       {Type: query.FieldUUID, Column: "public_id"},
+	"username": {Type: query.FieldString, Operators: textOperators},
+	"email":    {Type: query.FieldString, Operators: textOperators},
+	"role": {Type: query.FieldEnum, Values: []string{
+		entity.UserRoleUser, entity.UserRoleAdmin, entity.UserRoleSystemAdmin,
+	}, Facetable: true},
+	"status":        {Type: query.FieldEnum, Values: userStatuses, Facetable: true},
+	"last_login_at": {Type: query.FieldTime, Operators: append(timeOperators, "isnull", "notnull"), Nullable: true, Aggregatable: true},
+	"created_at":    {Type: query.FieldTime, Operators: timeOperators, Aggregatable: true},
+	"updated_at":    {Type: query.FieldTime, Operators: timeOperators, Aggregatable: true},
+	"search":        {Type: query.FieldString, Operators: []string{"eq"}, Virtual: true},
+}
+
+// UserProjection declares the user fields clients may select and the relations they may include
+var UserProjection = query.Projection{
+	Fields: map[string]string{
+		"id":              "public_id",
+		"username":        "username",
+		"email":           "email",
+		"role":            "role",
+		"status":          "status",
+		"status_reason":   "status_reason",
+		"suspended_until": "suspended_until",
+		"last_login_at":   "last_login_at",
+		"last_login_ip":   "last_login_ip",
+		"created_at":      "created_at",
+		"updated_at":      "updated_at",
+		"deleted_at":      "deleted_at",
+		"version":         "version",
+	},
+	// The primary key loads included relations; version is returned as the ETag
+	Required: []string{"id", "version"},
+	Relations: map[string]query.Relation{
+		"status_history": {Association: "StatusHistory", Order: "created_at DESC"},
+	},
+}
+
 // UserRepository extends BaseRepository for User entity
 type UserRepository interface {
-	BaseRepository[entity.User]
+	BaseRepository[entity.User, uint]
 
 	// Additional user-specific methods
 	FindByUsername(ctx context.Context, username string) (*entity.User, error)
 	FindByEmail(ctx context.Context, email string) (*entity.User, error)
 	FindByUsernameIncludingDeleted(ctx context.Context, username string) (*entity.User, error)
 	FindByEmailIncludingDeleted(ctx context.Context, email string) (*entity.User, error)
+	FindByIDIncludingDeleted(ctx context.Context, id uint) (*entity.User, error)
+	// FindIDByPublicID returns the ID of the user with the given public ID, deleted or not
+	FindIDByPublicID(ctx context.Context, publicID entity.PublicID) (uint, error)
+	// FindPublicIDs maps the given user IDs to public IDs, deleted or not; purged users
+	// are left out
+	FindPublicIDs(ctx context.Context, ids []uint) (map[uint]entity.PublicID, error)
 	Restore(ctx context.Context, id uint) error
+	UpdateLastLogin(ctx context.Context, id uint, at time.Time, ip string) error
+
+	// Purge permanently removes a user, bypassing soft delete, only if its stored version
+	// matches; see DeleteVersion
+	Purge(ctx context.Context, id uint, version uint) error
+	// PurgeDeletedBefore permanently removes users soft-deleted before the given time
+	PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error)
 
 	// ListWithQuery supports search filter across multiple fields
-	ListWithQuery(ctx context.Context, offset, limit int, opts query.QueryOptions) ([]entity.User, int64, error)
+	ListWithQuery(ctx context.Context, page query.Pagination, opts query.QueryOptions) (query.Page[entity.User], error)
 }
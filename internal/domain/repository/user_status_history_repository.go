@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"github.com/thienel/go-backend-template/internal/domain/entity"
+	"github.com/thienel/go-backend-template/pkg/query"
+)
+
+// userStatuses lists every user status, for enum fields
+var userStatuses = []string{
+	entity.UserStatusPending, entity.UserStatusActive, entity.UserStatusSuspended,
+	entity.UserStatusLocked, entity.UserStatusDeactivated,
+}
+
+// UserStatusHistoryQuerySchema declares the status history fields that can be filtered and sorted on
+var UserStatusHistoryQuerySchema = query.Schema{
+	"id":          {Type: query.FieldUUID, Column: "public_id"},
+	"user_id":     {Type: query.FieldInt},
+	"transition":  {Type: query.FieldString, Operators: []string{"eq", "ne", "in", "nin"}},
+	"from_status": {Type: query.FieldEnum, Values: userStatuses},
+	"to_status":   {Type: query.FieldEnum, Values: userStatuses},
+	"created_at":  {Type: query.FieldTime, Operators: timeOperators},
+}
+
+// UserStatusHistoryRepository stores user status transitions
+type UserStatusHistoryRepository interface {
+	BaseRepository[entity.UserStatusHistory, uint]
+}
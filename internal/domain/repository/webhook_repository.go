@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/thienel/go-backend-template/internal/domain/entity"
+	"github.com/thienel/go-backend-template/pkg/query"
+)
+
+// WebhookSubscriptionQuerySchema declares the webhook subscription fields that can be filtered and sorted on
+var WebhookSubscriptionQuerySchema = query.Schema{
+	"id":          {Type: query.FieldUUID, Column: "public_id"},
+	"url":         {Type: query.FieldString, Operators: textOperators},
+	"event_types": {Type: query.FieldJSON, Operators: []string{"contains"}},
+	"active":      {Type: query.FieldBool, Facetable: true},
+	"created_at":  {Type: query.FieldTime, Operators: timeOperators},
+	"updated_at":  {Type: query.FieldTime, Operators: timeOperators},
+}
+
+// WebhookDeliveryQuerySchema declares the webhook delivery fields that can be filtered and
+// sorted on. subscription_id takes public subscription IDs, resolved by the service.
+var WebhookDeliveryQuerySchema = query.Schema{
+	"id":              {Type: query.FieldUUID, Column: "public_id"},
+	"subscription_id": {Type: query.FieldUUID},
+	"event_name":      {Type: query.FieldString, Operators: []string{"eq", "ne", "in", "nin"}, Facetable: true},
+	"status": {Type: query.FieldEnum, Values: []string{
+		entity.WebhookDeliveryPending, entity.WebhookDeliveryDelivered, entity.WebhookDeliveryDead,
+	}, Facetable: true},
+	"response_code":   {Type: query.FieldInt, Operators: []string{"eq", "ne", "gt", "gte", "lt", "lte", "in", "nin", "isnull", "notnull"}, Nullable: true, Facetable: true},
+	"attempts":        {Type: query.FieldInt},
+	"next_attempt_at": {Type: query.FieldTime, Operators: timeOperators},
+	"created_at":      {Type: query.FieldTime, Operators: timeOperators, Aggregatable: true},
+}
+
+// WebhookSubscriptionRepository stores webhook subscriptions
+type WebhookSubscriptionRepository interface {
+	BaseRepository[entity.WebhookSubscription, uint]
+
+	// FindIDByPublicID returns the ID of the subscription with the given public ID
+	FindIDByPublicID(ctx context.Context, publicID entity.PublicID) (uint, error)
+
+	// FindActiveByEvent returns the active subscriptions that receive the event
+	FindActiveByEvent(ctx context.Context, eventName string) ([]entity.WebhookSubscription, error)
+}
+
+// WebhookDeliveryRepository stores webhook deliveries and their outcomes
+type WebhookDeliveryRepository interface {
+	BaseRepository[entity.WebhookDelivery, uint]
+
+	// FindIDByPublicID returns the ID of the delivery with the given public ID
+	FindIDByPublicID(ctx context.Context, publicID entity.PublicID) (uint, error)
+
+	// Enqueue stores new deliveries, skipping those of a message already queued for the
+	// same subscription, so that redelivered events are not sent twice
+	Enqueue(ctx context.Context, deliveries ...*entity.WebhookDelivery) error
+
+	// ClaimDue returns up to limit pending deliveries that are due and counts an attempt
+	// for each; they are not due again until lease has passed
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]entity.WebhookDelivery, error)
+}
@@ -0,0 +1,27 @@
+package valueobject
+
+import "context"
+
+type requestMetaKey struct{}
+
+// RequestMeta carries request-scoped metadata from the API layer into use cases
+type RequestMeta struct {
+	RequestID string
+	IP        string
+	UserAgent string
+	ActorID   uint
+	// ActorPublicID is the ID API clients know the actor by
+	ActorPublicID string
+	ActorRole     string
+}
+
+// WithRequestMeta returns a copy of ctx carrying the request metadata
+func WithRequestMeta(ctx context.Context, meta RequestMeta) context.Context {
+	return context.WithValue(ctx, requestMetaKey{}, meta)
+}
+
+// RequestMetaFromContext returns the request metadata stored in ctx, if any
+func RequestMetaFromContext(ctx context.Context) RequestMeta {
+	meta, _ := ctx.Value(requestMetaKey{}).(RequestMeta)
+	return meta
+}
@@ -1,43 +1,39 @@
 package database
 
 import (
+	"database/sql"
 	"fmt"
+	"net"
+	"strconv"
 	"sync"
+	"time"
 
 	"github.com/thienel/tlog"
 	"go.uber.org/zap"
-	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 
+	"github.com/thienel/go-backend-template/internal/infra/database/migrations"
+	"github.com/thienel/go-backend-template/pkg/backoff"
 	"github.com/thienel/go-backend-template/pkg/config"
+	"github.com/thienel/go-backend-template/pkg/migrate"
+	"github.com/thienel/go-backend-template/pkg/query"
 )
 
+// maxConnectRetryDelay caps the wait between connection attempts at startup
+const maxConnectRetryDelay = 30 * time.Second
+
 var (
-	db   *gorm.DB
-	once sync.Once
+	db     *gorm.DB
+	router *replicaRouter
+	once   sync.Once
 )
 
-// Init initializes the database connection
+// Init initializes the database connection, and the read replicas if any are configured
 func Init(cfg *config.DatabaseConfig) error {
 	var initErr error
 
 	once.Do(func() {
-		dsn := fmt.Sprintf(
-			"host=%s port=%d user=%s password=%s dbname=%s sslmode=%s TimeZone=%s",
-			cfg.Host,
-			cfg.Port,
-			cfg.User,
-			cfg.Password,
-			cfg.DBName,
-			cfg.SSLMode,
-			cfg.TimeZone,
-		)
-
-		gormConfig := &gorm.Config{
-			Logger: tlog.NewGormLogger(),
-		}
-
-		db, initErr = gorm.Open(postgres.Open(dsn), gormConfig)
+		db, initErr = openPrimary(cfg)
 		if initErr != nil {
 			return
 		}
@@ -47,27 +43,127 @@ func Init(cfg *config.DatabaseConfig) error {
 			initErr = err
 			return
 		}
-
-		sqlDB.SetMaxIdleConns(10)
-		sqlDB.SetMaxOpenConns(100)
+		configurePool(cfg, sqlDB)
 
 		tlog.Info("Database connection established",
+			zap.String("driver", cfg.Driver),
 			zap.String("host", cfg.Host),
 			zap.Int("port", cfg.Port),
 			zap.String("database", cfg.DBName),
 		)
+
+		if len(cfg.ReplicaHosts) > 0 {
+			initErr = initReplicas(cfg, sqlDB)
+		}
 	})
 
 	return initErr
 }
 
+// openPrimary connects to the primary, retrying with exponential backoff while it is
+// not ready, e.g. when it starts along with the service
+func openPrimary(cfg *config.DatabaseConfig) (*gorm.DB, error) {
+	base := time.Duration(cfg.ConnectRetryDelayMs) * time.Millisecond
+	dial, err := dialector(cfg, cfg.Host, cfg.Port)
+	if err != nil {
+		return nil, err
+	}
+
+	for attempt := 1; ; attempt++ {
+		conn, err := gorm.Open(dial, &gorm.Config{
+			Logger: tlog.NewGormLogger(),
+		})
+		if err == nil || attempt > cfg.ConnectRetries {
+			return conn, err
+		}
+
+		delay := backoff.Exponential(attempt, base, maxConnectRetryDelay)
+		tlog.Warn("Database not ready, retrying",
+			zap.Int("attempt", attempt),
+			zap.Duration("delay", delay),
+			zap.Error(err),
+		)
+		time.Sleep(delay)
+	}
+}
+
+// configurePool applies the pool settings to a primary or replica pool
+func configurePool(cfg *config.DatabaseConfig, pool *sql.DB) {
+	pool.SetMaxOpenConns(cfg.MaxOpenConns)
+	pool.SetMaxIdleConns(cfg.MaxIdleConns)
+	pool.SetConnMaxLifetime(time.Duration(cfg.ConnMaxLifetimeSeconds) * time.Second)
+	pool.SetConnMaxIdleTime(time.Duration(cfg.ConnMaxIdleTimeSeconds) * time.Second)
+}
+
+// initReplicas opens the read replicas and routes reads to them. A replica that is down
+// does not fail startup; reads avoid it until a health check passes.
+func initReplicas(cfg *config.DatabaseConfig, primary *sql.DB) error {
+	if cfg.Driver == query.DialectSQLite {
+		return fmt.Errorf("database: read replicas are not supported with %s", cfg.Driver)
+	}
+
+	router = &replicaRouter{primary: primary}
+	for _, addr := range cfg.ReplicaHosts {
+		host, port := addr, cfg.Port
+		if h, p, err := net.SplitHostPort(addr); err == nil {
+			n, err := strconv.Atoi(p)
+			if err != nil {
+				return fmt.Errorf("database: invalid replica address %q", addr)
+			}
+			host, port = h, n
+		}
+
+		dial, err := dialector(cfg, host, port)
+		if err != nil {
+			return err
+		}
+		// Opening a pool does not connect, so this only fails on a bad DSN
+		replicaDB, err := gorm.Open(dial, &gorm.Config{
+			Logger:               tlog.NewGormLogger(),
+			DisableAutomaticPing: true,
+		})
+		if err != nil {
+			return fmt.Errorf("database: replica %s: %w", addr, err)
+		}
+		pool, err := replicaDB.DB()
+		if err != nil {
+			return err
+		}
+		configurePool(cfg, pool)
+
+		router.replicas = append(router.replicas, &replica{
+			name: fmt.Sprintf("replica-%d", len(router.replicas)),
+			addr: addr,
+			pool: pool,
+		})
+	}
+
+	if err := db.Use(router); err != nil {
+		return err
+	}
+
+	interval := time.Duration(cfg.ReplicaHealthIntervalSeconds) * time.Second
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+	router.start(interval)
+
+	tlog.Info("Database read replicas configured", zap.Strings("replicas", cfg.ReplicaHosts))
+	return nil
+}
+
 // GetDB returns the database instance
 func GetDB() *gorm.DB {
 	return db
 }
 
-// Close closes the database connection
+// Close closes the database connection and the read replicas
 func Close() error {
+	if router != nil {
+		if err := router.stop(); err != nil {
+			tlog.Warn("Failed to close database replicas", zap.Error(err))
+		}
+	}
 	if db != nil {
 		sqlDB, err := db.DB()
 		if err != nil {
@@ -78,10 +174,15 @@ func Close() error {
 	return nil
 }
 
-// AutoMigrate runs auto migration
-func AutoMigrate(models ...interface{}) error {
+// NewMigrator creates a migrator for the service migrations, including the opt-in ones
+// that cfg enables
+func NewMigrator(cfg *config.DatabaseConfig) (*migrate.Migrator, error) {
 	if db == nil {
-		return fmt.Errorf("database not initialized")
+		return nil, fmt.Errorf("database not initialized")
+	}
+	all, err := migrations.All(db.Dialector.Name(), cfg.SearchTrigram)
+	if err != nil {
+		return nil, err
 	}
-	return db.AutoMigrate(models...)
+	return migrate.New(db, all)
 }
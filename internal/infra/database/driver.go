@@ -0,0 +1,95 @@
+package database
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	mysqldriver "github.com/go-sql-driver/mysql"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/thienel/go-backend-template/pkg/config"
+	"github.com/thienel/go-backend-template/pkg/query"
+)
+
+// dialectorFunc builds the GORM dialector of a driver for the server at host:port
+type dialectorFunc func(cfg *config.DatabaseConfig, host string, port int) (gorm.Dialector, error)
+
+// drivers are the supported values of DB_DRIVER, named as GORM names their dialects so
+// that pkg/query compiles its SQL for the right one
+var drivers = map[string]dialectorFunc{
+	query.DialectPostgres: postgresDialector,
+	query.DialectMySQL:    mysqlDialector,
+	query.DialectSQLite:   sqliteDialector,
+}
+
+// dialector returns the dialector of the configured driver
+func dialector(cfg *config.DatabaseConfig, host string, port int) (gorm.Dialector, error) {
+	open, ok := drivers[cfg.Driver]
+	if !ok {
+		return nil, fmt.Errorf("database: unsupported driver %q", cfg.Driver)
+	}
+	return open(cfg, host, port)
+}
+
+func postgresDialector(cfg *config.DatabaseConfig, host string, port int) (gorm.Dialector, error) {
+	dsn := fmt.Sprintf(
+		"host=%s port=%d user=%s password=%s dbname=%s sslmode=%s TimeZone=%s",
+		host,
+		port,
+		cfg.User,
+		cfg.Password,
+		cfg.DBName,
+		cfg.SSLMode,
+		cfg.TimeZone,
+	)
+	if cfg.ConnectTimeoutSeconds > 0 {
+		dsn += fmt.Sprintf(" connect_timeout=%d", cfg.ConnectTimeoutSeconds)
+	}
+	if cfg.StatementTimeoutMs > 0 {
+		// Unknown keys are sent to the server as session settings
+		dsn += fmt.Sprintf(" statement_timeout=%d", cfg.StatementTimeoutMs)
+	}
+	return postgres.Open(dsn), nil
+}
+
+func mysqlDialector(cfg *config.DatabaseConfig, host string, port int) (gorm.Dialector, error) {
+	loc, err := time.LoadLocation(cfg.TimeZone)
+	if err != nil {
+		return nil, fmt.Errorf("database: time zone %q: %w", cfg.TimeZone, err)
+	}
+
+	dsn := mysqldriver.NewConfig()
+	dsn.User = cfg.User
+	dsn.Passwd = cfg.Password
+	dsn.Net = "tcp"
+	dsn.Addr = net.JoinHostPort(host, strconv.Itoa(port))
+	dsn.DBName = cfg.DBName
+	dsn.ParseTime = true
+	dsn.Loc = loc
+	dsn.Timeout = time.Duration(cfg.ConnectTimeoutSeconds) * time.Second
+	dsn.Params = map[string]string{"charset": "utf8mb4"}
+	if cfg.StatementTimeoutMs > 0 {
+		// Unknown parameters are set as session variables; this one bounds SELECTs only
+		dsn.Params["max_execution_time"] = strconv.Itoa(cfg.StatementTimeoutMs)
+	}
+	return mysql.Open(dsn.FormatDSN()), nil
+}
+
+// sqliteDialector opens the database file named by DBName. Foreign keys are enforced as
+// on the other databases, and a writer waits for the file lock for up to the connect
+// timeout rather than failing at once.
+func sqliteDialector(cfg *config.DatabaseConfig, _ string, _ int) (gorm.Dialector, error) {
+	separator := "?"
+	if strings.Contains(cfg.DBName, "?") {
+		separator = "&"
+	}
+	busyTimeout := cfg.ConnectTimeoutSeconds * 1000
+	dsn := fmt.Sprintf("%s%s_foreign_keys=1&_busy_timeout=%d", cfg.DBName, separator, busyTimeout)
+	return sqlite.Open(dsn), nil
+}
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS auth_events;
+DROP TABLE IF EXISTS user_status_histories;
+DROP TABLE IF EXISTS users;
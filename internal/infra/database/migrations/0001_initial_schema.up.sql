@@ -0,0 +1,51 @@
+-- Tables as previously created by AutoMigrate; IF NOT EXISTS keeps this a no-op on
+-- databases that were set up before migrations were introduced.
+
+CREATE TABLE IF NOT EXISTS users (
+    id              BIGSERIAL PRIMARY KEY,
+    username        VARCHAR(50)  NOT NULL,
+    email           VARCHAR(255) NOT NULL,
+    password        VARCHAR(255) NOT NULL,
+    role            VARCHAR(20)  DEFAULT 'USER',
+    status          VARCHAR(20)  DEFAULT 'ACTIVE',
+    created_at      TIMESTAMPTZ,
+    updated_at      TIMESTAMPTZ,
+    deleted_at      TIMESTAMPTZ,
+    status_reason   VARCHAR(255),
+    suspended_until TIMESTAMPTZ,
+    last_login_at   TIMESTAMPTZ,
+    last_login_ip   VARCHAR(45)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_username ON users (username);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email ON users (email);
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users (deleted_at);
+
+CREATE TABLE IF NOT EXISTS user_status_histories (
+    id              BIGSERIAL PRIMARY KEY,
+    user_id         BIGINT      NOT NULL,
+    transition      VARCHAR(20) NOT NULL,
+    from_status     VARCHAR(20) NOT NULL,
+    to_status       VARCHAR(20) NOT NULL,
+    reason          VARCHAR(255),
+    suspended_until TIMESTAMPTZ,
+    actor_id        BIGINT,
+    created_at      TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_user_status_histories_user_id ON user_status_histories (user_id);
+
+CREATE TABLE IF NOT EXISTS auth_events (
+    id         BIGSERIAL PRIMARY KEY,
+    user_id    BIGINT,
+    username   VARCHAR(50),
+    type       VARCHAR(30) NOT NULL,
+    success    BOOLEAN     NOT NULL,
+    reason     VARCHAR(255),
+    ip         VARCHAR(45),
+    user_agent VARCHAR(255),
+    request_id VARCHAR(64),
+    created_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_auth_events_user_id ON auth_events (user_id);
+CREATE INDEX IF NOT EXISTS idx_auth_events_username ON auth_events (username);
+CREATE INDEX IF NOT EXISTS idx_auth_events_type ON auth_events (type);
+CREATE INDEX IF NOT EXISTS idx_auth_events_created_at ON auth_events (created_at);
@@ -0,0 +1,8 @@
+-- Full-text search over username and email (see persistence.userSearch); trigram
+-- matching is opted into separately by 0010_user_search_trigram
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('simple', coalesce(username, '')), 'A') ||
+    setweight(to_tsvector('simple', coalesce(email, '')), 'B')
+) STORED;
+CREATE INDEX IF NOT EXISTS idx_users_search_vector ON users USING GIN (search_vector);
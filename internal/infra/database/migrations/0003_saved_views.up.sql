@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS saved_views (
+    id         BIGSERIAL PRIMARY KEY,
+    owner_id   BIGINT       NOT NULL,
+    resource   VARCHAR(50)  NOT NULL,
+    name       VARCHAR(100) NOT NULL,
+    shared     BOOLEAN      NOT NULL DEFAULT false,
+    options    JSONB        NOT NULL,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_saved_views_owner_resource_name ON saved_views (owner_id, resource, name);
+CREATE INDEX IF NOT EXISTS idx_saved_views_shared ON saved_views (shared);
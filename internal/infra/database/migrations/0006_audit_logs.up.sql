@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS audit_logs (
+    id             BIGSERIAL PRIMARY KEY,
+    actor_id       BIGINT,
+    action         VARCHAR(20) NOT NULL,
+    entity_type    VARCHAR(50) NOT NULL,
+    entity_id      BIGINT      NOT NULL,
+    changes        JSONB,
+    changed_fields JSONB,
+    request_id     VARCHAR(64),
+    ip             VARCHAR(45),
+    created_at     TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_actor_id ON audit_logs (actor_id);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_action ON audit_logs (action);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_entity ON audit_logs (entity_type, entity_id);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_created_at ON audit_logs (created_at);
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS outbox_messages (
+    id              BIGSERIAL PRIMARY KEY,
+    event_name      VARCHAR(100) NOT NULL,
+    payload         JSONB        NOT NULL,
+    occurred_at     TIMESTAMPTZ  NOT NULL,
+    status          VARCHAR(20)  NOT NULL DEFAULT 'pending',
+    attempts        BIGINT       NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ  NOT NULL,
+    last_error      VARCHAR(500),
+    delivered_at    TIMESTAMPTZ,
+    created_at      TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_outbox_messages_event_name ON outbox_messages (event_name);
+CREATE INDEX IF NOT EXISTS idx_outbox_messages_due ON outbox_messages (status, next_attempt_at);
@@ -0,0 +1,33 @@
+CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+    id          BIGSERIAL PRIMARY KEY,
+    url         VARCHAR(500) NOT NULL,
+    event_types JSONB        NOT NULL,
+    secret      VARCHAR(100) NOT NULL,
+    description VARCHAR(255),
+    active      BOOLEAN      NOT NULL DEFAULT true,
+    created_at  TIMESTAMPTZ,
+    updated_at  TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_webhook_subscriptions_active ON webhook_subscriptions (active);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id              BIGSERIAL PRIMARY KEY,
+    subscription_id BIGINT       NOT NULL,
+    message_id      BIGINT       NOT NULL,
+    event_name      VARCHAR(100) NOT NULL,
+    payload         JSONB        NOT NULL,
+    status          VARCHAR(20)  NOT NULL DEFAULT 'pending',
+    attempts        BIGINT       NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ  NOT NULL,
+    response_code   BIGINT,
+    response_body   VARCHAR(1000),
+    last_error      VARCHAR(500),
+    duration_ms     BIGINT,
+    delivered_at    TIMESTAMPTZ,
+    created_at      TIMESTAMPTZ,
+    updated_at      TIMESTAMPTZ
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_webhook_deliveries_subscription_message ON webhook_deliveries (subscription_id, message_id);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_event_name ON webhook_deliveries (event_name);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries (status, next_attempt_at);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_created_at ON webhook_deliveries (created_at);
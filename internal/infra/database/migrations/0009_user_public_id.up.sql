@@ -0,0 +1,3 @@
+-- Existing users get random (v4) IDs; the service assigns time-ordered v7 IDs to new ones
+ALTER TABLE users ADD COLUMN IF NOT EXISTS public_id UUID NOT NULL DEFAULT gen_random_uuid();
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_public_id ON users (public_id);
@@ -0,0 +1,7 @@
+-- Trigram indexes for typos and partial words in user search. Only applied when
+-- DB_SEARCH_TRIGRAM is enabled, since creating the extension takes privileges the
+-- application role may lack; it can also be created beforehand by a superuser.
+
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+CREATE INDEX IF NOT EXISTS idx_users_username_trgm ON users USING GIN (username gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_users_email_trgm ON users USING GIN (email gin_trgm_ops);
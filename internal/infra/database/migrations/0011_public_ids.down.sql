@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_webhook_deliveries_public_id;
+ALTER TABLE webhook_deliveries DROP COLUMN IF EXISTS public_id;
+
+DROP INDEX IF EXISTS idx_webhook_subscriptions_public_id;
+ALTER TABLE webhook_subscriptions DROP COLUMN IF EXISTS public_id;
+
+DROP INDEX IF EXISTS idx_saved_views_public_id;
+ALTER TABLE saved_views DROP COLUMN IF EXISTS public_id;
@@ -0,0 +1,9 @@
+-- Public IDs of the other entities exposed by the API, as for users in 0009_user_public_id
+ALTER TABLE saved_views ADD COLUMN IF NOT EXISTS public_id UUID NOT NULL DEFAULT gen_random_uuid();
+CREATE UNIQUE INDEX IF NOT EXISTS idx_saved_views_public_id ON saved_views (public_id);
+
+ALTER TABLE webhook_subscriptions ADD COLUMN IF NOT EXISTS public_id UUID NOT NULL DEFAULT gen_random_uuid();
+CREATE UNIQUE INDEX IF NOT EXISTS idx_webhook_subscriptions_public_id ON webhook_subscriptions (public_id);
+
+ALTER TABLE webhook_deliveries ADD COLUMN IF NOT EXISTS public_id UUID NOT NULL DEFAULT gen_random_uuid();
+CREATE UNIQUE INDEX IF NOT EXISTS idx_webhook_deliveries_public_id ON webhook_deliveries (public_id);
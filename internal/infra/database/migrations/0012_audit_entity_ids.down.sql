@@ -0,0 +1,13 @@
+UPDATE audit_logs a SET entity_id = u.id::text
+FROM users u WHERE a.entity_type = 'user' AND a.entity_id = u.public_id::text;
+
+UPDATE audit_logs a SET entity_id = v.id::text
+FROM saved_views v WHERE a.entity_type = 'saved_view' AND a.entity_id = v.public_id::text;
+
+UPDATE audit_logs a SET entity_id = s.id::text
+FROM webhook_subscriptions s WHERE a.entity_type = 'webhook_subscription' AND a.entity_id = s.public_id::text;
+
+-- Entries of entities that no longer exist have no ID to go back to
+DELETE FROM audit_logs WHERE entity_id !~ '^[0-9]+$';
+
+ALTER TABLE audit_logs ALTER COLUMN entity_id TYPE BIGINT USING entity_id::bigint;
@@ -0,0 +1,11 @@
+-- Audit entries identify entities by public ID where they have one, as the API does
+ALTER TABLE audit_logs ALTER COLUMN entity_id TYPE VARCHAR(64) USING entity_id::text;
+
+UPDATE audit_logs a SET entity_id = u.public_id::text
+FROM users u WHERE a.entity_type = 'user' AND a.entity_id = u.id::text;
+
+UPDATE audit_logs a SET entity_id = v.public_id::text
+FROM saved_views v WHERE a.entity_type = 'saved_view' AND a.entity_id = v.id::text;
+
+UPDATE audit_logs a SET entity_id = s.public_id::text
+FROM webhook_subscriptions s WHERE a.entity_type = 'webhook_subscription' AND a.entity_id = s.id::text;
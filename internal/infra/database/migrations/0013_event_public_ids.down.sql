@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_audit_logs_public_id;
+ALTER TABLE audit_logs DROP COLUMN IF EXISTS public_id;
+
+DROP INDEX IF EXISTS idx_auth_events_public_id;
+ALTER TABLE auth_events DROP COLUMN IF EXISTS public_id;
+
+DROP INDEX IF EXISTS idx_user_status_histories_public_id;
+ALTER TABLE user_status_histories DROP COLUMN IF EXISTS public_id;
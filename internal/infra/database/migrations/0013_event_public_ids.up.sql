@@ -0,0 +1,9 @@
+-- Public IDs of the log entries listed by the API, as for users in 0009_user_public_id
+ALTER TABLE user_status_histories ADD COLUMN IF NOT EXISTS public_id UUID NOT NULL DEFAULT gen_random_uuid();
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_status_histories_public_id ON user_status_histories (public_id);
+
+ALTER TABLE auth_events ADD COLUMN IF NOT EXISTS public_id UUID NOT NULL DEFAULT gen_random_uuid();
+CREATE UNIQUE INDEX IF NOT EXISTS idx_auth_events_public_id ON auth_events (public_id);
+
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS public_id UUID NOT NULL DEFAULT gen_random_uuid();
+CREATE UNIQUE INDEX IF NOT EXISTS idx_audit_logs_public_id ON audit_logs (public_id);
@@ -0,0 +1,34 @@
+package migrations
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/thienel/go-backend-template/internal/domain/entity"
+	"github.com/thienel/go-backend-template/pkg/migrate"
+)
+
+// goMigrations are the migrations written in Go
+var goMigrations = []migrate.Migration{
+	{
+		// The INACTIVE status was replaced by DEACTIVATED when user status became a state
+		// machine; rows written before that still carry the old value. Not reversible,
+		// since deactivated users cannot be told apart afterwards.
+		Version: 4,
+		Name:    "backfill_deactivated_status",
+		Up: func(tx *gorm.DB) error {
+			if err := tx.Table("users").
+				Where("status = ?", "INACTIVE").
+				Update("status", entity.UserStatusDeactivated).Error; err != nil {
+				return err
+			}
+			for _, column := range []string{"from_status", "to_status"} {
+				if err := tx.Table("user_status_histories").
+					Where(column+" = ?", "INACTIVE").
+					Update(column, entity.UserStatusDeactivated).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+}
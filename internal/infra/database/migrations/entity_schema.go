@@ -0,0 +1,34 @@
+package migrations
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/thienel/go-backend-template/internal/domain/entity"
+	"github.com/thienel/go-backend-template/pkg/migrate"
+)
+
+// entityModels are the tables of the service
+var entityModels = []any{
+	&entity.User{},
+	&entity.UserStatusHistory{},
+	&entity.AuthEvent{},
+	&entity.SavedView{},
+	&entity.AuditLog{},
+	&entity.OutboxMessage{},
+	&entity.WebhookSubscription{},
+	&entity.WebhookDelivery{},
+}
+
+// entitySchema creates and updates the tables of MySQL and SQLite databases from the
+// entity models, since the SQL migrations are written for Postgres. It takes the version
+// of the latest migration, so it runs again whenever one is added; AutoMigrate only adds
+// what is missing and cannot be reverted.
+func entitySchema(version int64) migrate.Migration {
+	return migrate.Migration{
+		Version: version,
+		Name:    "entity_schema",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(entityModels...)
+		},
+	}
+}
@@ -0,0 +1,49 @@
+// Package migrations holds the numbered schema and data migrations of the service.
+// SQL migrations are NNNN_name.up.sql / NNNN_name.down.sql files in this directory;
+// data migrations that need code are registered in goMigrations. Both are Postgres;
+// other databases get their schema from the entity models instead. Opt-in migrations,
+// such as trigramSearchVersion, are only included when enabled.
+package migrations
+
+import (
+	"embed"
+
+	"github.com/thienel/go-backend-template/pkg/migrate"
+	"github.com/thienel/go-backend-template/pkg/query"
+)
+
+//go:embed *.sql
+var sqlFiles embed.FS
+
+// trigramSearchVersion is the opt-in migration that creates the pg_trgm extension and
+// the trigram indexes of user search
+const trigramSearchVersion = 10
+
+// All returns every migration for the given dialect, in no particular order: the SQL
+// and Go migrations on Postgres, the entity schema on other databases. trigramSearch
+// includes the trigram search migration; it applies even after later migrations.
+func All(dialect string, trigramSearch bool) ([]migrate.Migration, error) {
+	migrations, err := migrate.LoadSQL(sqlFiles)
+	if err != nil {
+		return nil, err
+	}
+	migrations = append(migrations, goMigrations...)
+	if dialect == query.DialectPostgres {
+		if trigramSearch {
+			return migrations, nil
+		}
+		enabled := migrations[:0]
+		for _, migration := range migrations {
+			if migration.Version != trigramSearchVersion {
+				enabled = append(enabled, migration)
+			}
+		}
+		return enabled, nil
+	}
+
+	var latest int64
+	for _, migration := range migrations {
+		latest = max(latest, migration.Version)
+	}
+	return []migrate.Migration{entitySchema(latest)}, nil
+}
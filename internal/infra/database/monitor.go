@@ -0,0 +1,71 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// PrimaryPool is the name of the primary connection pool in statistics; replica pools
+// are named "replica-<n>" by their position in DB_REPLICA_HOSTS, so that public reports
+// do not reveal database addresses
+const PrimaryPool = "primary"
+
+// Monitor reports on the health and usage of the connection pools
+type Monitor struct{}
+
+// NewMonitor creates a monitor of the pools opened by Init
+func NewMonitor() *Monitor {
+	return &Monitor{}
+}
+
+// Ping checks that the primary answers
+func (m *Monitor) Ping(ctx context.Context) error {
+	if db == nil {
+		return fmt.Errorf("database not initialized")
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+// Stats returns the statistics of each connection pool by name
+func (m *Monitor) Stats() map[string]sql.DBStats {
+	stats := make(map[string]sql.DBStats)
+	if db != nil {
+		if sqlDB, err := db.DB(); err == nil {
+			stats[PrimaryPool] = sqlDB.Stats()
+		}
+	}
+	if router != nil {
+		for _, replica := range router.replicas {
+			stats[replica.name] = replica.pool.Stats()
+		}
+	}
+	return stats
+}
+
+// ReplicaHealth returns whether each replica pool passed its last health check
+func (m *Monitor) ReplicaHealth() map[string]bool {
+	health := make(map[string]bool)
+	if router != nil {
+		for _, replica := range router.replicas {
+			health[replica.name] = replica.healthy.Load()
+		}
+	}
+	return health
+}
+
+// PoolAddresses returns the address of each replica pool by name, for the metrics
+// listener and logs only
+func (m *Monitor) PoolAddresses() map[string]string {
+	addrs := make(map[string]string)
+	if router != nil {
+		for _, replica := range router.replicas {
+			addrs[replica.name] = replica.addr
+		}
+	}
+	return addrs
+}
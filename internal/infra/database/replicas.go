@@ -0,0 +1,152 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/thienel/tlog"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/thienel/go-backend-template/internal/domain/repository"
+)
+
+// pingTimeout bounds each replica health check
+const pingTimeout = 2 * time.Second
+
+// replica is a read replica connection pool and whether its last health check passed.
+// It is named by position, since its address is not for public reports.
+type replica struct {
+	name    string
+	addr    string
+	pool    *sql.DB
+	healthy atomic.Bool
+}
+
+// replicaRouter is a GORM plugin that sends reads made on the primary pool to a healthy
+// replica, in turn. Transactions, dedicated connections, locking reads, raw statements
+// other than SELECT and contexts asking to read their own writes stay on the primary,
+// as does everything when no replica is healthy.
+type replicaRouter struct {
+	primary  *sql.DB
+	replicas []*replica
+	next     atomic.Uint64
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+func (r *replicaRouter) Name() string {
+	return "database:replica_router"
+}
+
+func (r *replicaRouter) Initialize(db *gorm.DB) error {
+	if err := db.Callback().Query().Before("gorm:query").Register("database:route_query", r.route); err != nil {
+		return err
+	}
+	return db.Callback().Row().Before("gorm:row").Register("database:route_row", r.route)
+}
+
+func (r *replicaRouter) route(db *gorm.DB) {
+	stmt := db.Statement
+	if pool, ok := stmt.ConnPool.(*sql.DB); !ok || pool != r.primary {
+		return
+	}
+	if repository.ReadsYourWrites(stmt.Context) {
+		return
+	}
+	if _, locking := stmt.Clauses["FOR"]; locking {
+		return
+	}
+	// Raw statements are built before the callbacks run; others are built by them
+	if stmt.SQL.Len() > 0 && !isReadOnlySQL(stmt.SQL.String()) {
+		return
+	}
+
+	if replica := r.pick(); replica != nil {
+		stmt.ConnPool = replica.pool
+	}
+}
+
+// pick returns the next healthy replica, or nil if there is none
+func (r *replicaRouter) pick() *replica {
+	n := uint64(len(r.replicas))
+	start := r.next.Add(1)
+	for i := uint64(0); i < n; i++ {
+		if replica := r.replicas[(start+i)%n]; replica.healthy.Load() {
+			return replica
+		}
+	}
+	return nil
+}
+
+// start checks the health of the replicas now and then every interval until stop
+func (r *replicaRouter) start(interval time.Duration) {
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+	r.done = make(chan struct{})
+
+	r.checkHealth(ctx)
+	go func() {
+		defer close(r.done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				r.checkHealth(ctx)
+			}
+		}
+	}()
+}
+
+// stop stops the health checks and closes the replica pools
+func (r *replicaRouter) stop() error {
+	if r.cancel != nil {
+		r.cancel()
+		<-r.done
+	}
+
+	var firstErr error
+	for _, replica := range r.replicas {
+		if err := replica.pool.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (r *replicaRouter) checkHealth(ctx context.Context) {
+	for _, replica := range r.replicas {
+		pingCtx, cancel := context.WithTimeout(ctx, pingTimeout)
+		err := replica.pool.PingContext(pingCtx)
+		cancel()
+
+		healthy := err == nil
+		if replica.healthy.Swap(healthy) == healthy {
+			continue
+		}
+		if healthy {
+			tlog.Info("Database replica is healthy", zap.String("pool", replica.name), zap.String("replica", replica.addr))
+		} else {
+			tlog.Warn("Database replica is unhealthy, reading from the primary", zap.String("pool", replica.name), zap.String("replica", replica.addr), zap.Error(err))
+		}
+	}
+}
+
+// writeSQLPattern matches statements that lock rows or write, including SELECTs with
+// writable CTEs, which must run on the primary
+var writeSQLPattern = regexp.MustCompile(`(?i)\b(INSERT|UPDATE|DELETE|MERGE|FOR\s+(NO\s+KEY\s+)?UPDATE|FOR\s+(KEY\s+)?SHARE)\b`)
+
+// isReadOnlySQL checks if a raw statement is a plain query that a replica can serve
+func isReadOnlySQL(sql string) bool {
+	s := strings.ToUpper(strings.TrimSpace(sql))
+	return (strings.HasPrefix(s, "SELECT") || strings.HasPrefix(s, "WITH")) && !writeSQLPattern.MatchString(s)
+}
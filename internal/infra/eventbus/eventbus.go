@@ -0,0 +1,58 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/thienel/tlog"
+	"go.uber.org/zap"
+
+	"github.com/thienel/go-backend-template/internal/domain/event"
+)
+
+// wildcard subscribes a handler to every event
+const wildcard = "*"
+
+// Bus is a synchronous in-process event bus
+type Bus struct {
+	mu       sync.RWMutex
+	handlers map[string][]event.Handler
+}
+
+// New creates a new in-process event bus
+func New() *Bus {
+	return &Bus{handlers: make(map[string][]event.Handler)}
+}
+
+// Subscribe registers a handler for an event name, or "*" for all events
+func (b *Bus) Subscribe(eventName string, handler event.Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers[eventName] = append(b.handlers[eventName], handler)
+}
+
+// Publish delivers events to their handlers. Every handler runs even if another failed;
+// the failures are logged and returned together, so that the outbox retries the event.
+func (b *Bus) Publish(ctx context.Context, events ...event.Event) error {
+	var errs []error
+	for _, evt := range events {
+		for _, handler := range b.handlersFor(evt.EventName()) {
+			if err := handler(ctx, evt); err != nil {
+				tlog.Error("Event handler failed", zap.String("event", evt.EventName()), zap.Error(err))
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (b *Bus) handlersFor(eventName string) []event.Handler {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	handlers := make([]event.Handler, 0, len(b.handlers[eventName])+len(b.handlers[wildcard]))
+	handlers = append(handlers, b.handlers[eventName]...)
+	handlers = append(handlers, b.handlers[wildcard]...)
+	return handlers
+}
@@ -0,0 +1,170 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/thienel/tlog"
+	"go.uber.org/zap"
+
+	"github.com/thienel/go-backend-template/internal/domain/entity"
+	"github.com/thienel/go-backend-template/internal/domain/event"
+	"github.com/thienel/go-backend-template/internal/domain/repository"
+	"github.com/thienel/go-backend-template/pkg/backoff"
+	"github.com/thienel/go-backend-template/pkg/config"
+)
+
+// Delays between delivery attempts, doubling from minRetryDelay
+const (
+	minRetryDelay = time.Second
+	maxRetryDelay = time.Hour
+)
+
+// Dispatcher delivers outbox messages to its targets in the background. A message is
+// retried, with exponential backoff, until every target accepted it or it runs out of
+// attempts; targets that accepted it before another failed receive it again.
+type Dispatcher struct {
+	repo        repository.OutboxRepository
+	targets     []event.Publisher
+	interval    time.Duration
+	batchSize   int
+	maxAttempts int
+	lease       time.Duration
+	cancel      context.CancelFunc
+	done        chan struct{}
+}
+
+// NewDispatcher creates a dispatcher delivering to the given targets, such as the
+// in-process event bus and sinks
+func NewDispatcher(repo repository.OutboxRepository, cfg config.OutboxConfig, targets ...event.Publisher) *Dispatcher {
+	interval := time.Duration(cfg.PollIntervalMs) * time.Millisecond
+	if interval <= 0 {
+		interval = time.Second
+	}
+	batchSize := cfg.BatchSize
+	if batchSize <= 0 {
+		batchSize = 50
+	}
+	maxAttempts := cfg.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = 10
+	}
+	lease := time.Duration(cfg.LeaseSeconds) * time.Second
+	if lease <= 0 {
+		lease = 5 * time.Minute
+	}
+
+	return &Dispatcher{
+		repo:        repo,
+		targets:     targets,
+		interval:    interval,
+		batchSize:   batchSize,
+		maxAttempts: maxAttempts,
+		lease:       lease,
+	}
+}
+
+// Start runs the dispatcher in the background until Stop is called
+func (d *Dispatcher) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	d.cancel = cancel
+	d.done = make(chan struct{})
+
+	go func() {
+		defer close(d.done)
+
+		ticker := time.NewTicker(d.interval)
+		defer ticker.Stop()
+
+		d.run(ctx)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				d.run(ctx)
+			}
+		}
+	}()
+
+	tlog.Info("Outbox dispatcher started",
+		zap.Duration("interval", d.interval),
+		zap.Int("batch_size", d.batchSize),
+		zap.Int("targets", len(d.targets)),
+	)
+}
+
+// Stop stops the dispatcher and waits for the current batch to finish
+func (d *Dispatcher) Stop() {
+	if d.cancel == nil {
+		return
+	}
+	d.cancel()
+	<-d.done
+}
+
+// run delivers due messages until a batch comes back short
+func (d *Dispatcher) run(ctx context.Context) {
+	for ctx.Err() == nil {
+		messages, err := d.repo.ClaimDue(ctx, d.batchSize, d.lease)
+		if err != nil {
+			tlog.Error("Outbox dispatch failed", zap.Error(err))
+			return
+		}
+
+		for i := range messages {
+			d.deliver(ctx, &messages[i])
+		}
+		if len(messages) < d.batchSize {
+			return
+		}
+	}
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, msg *entity.OutboxMessage) {
+	evt := event.Message{ID: msg.ID, Name: msg.EventName, Payload: msg.Payload, Time: msg.OccurredAt}
+
+	var errs []error
+	for _, target := range d.targets {
+		if err := target.Publish(ctx, evt); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	err := errors.Join(errs...)
+	if err == nil {
+		if err := d.repo.MarkDelivered(ctx, msg.ID); err != nil {
+			// The lease expires and the message is delivered again
+			tlog.Error("Failed to mark outbox message delivered", zap.Uint("message_id", msg.ID), zap.Error(err))
+		}
+		return
+	}
+
+	if msg.Attempts >= d.maxAttempts {
+		tlog.Error("Outbox message failed permanently",
+			zap.Uint("message_id", msg.ID),
+			zap.String("event", msg.EventName),
+			zap.Int("attempts", msg.Attempts),
+			zap.Error(err),
+		)
+		d.markRetry(ctx, msg, nil, err)
+		return
+	}
+
+	next := time.Now().Add(backoff.Exponential(msg.Attempts, minRetryDelay, maxRetryDelay))
+	tlog.Warn("Outbox message delivery failed",
+		zap.Uint("message_id", msg.ID),
+		zap.String("event", msg.EventName),
+		zap.Int("attempts", msg.Attempts),
+		zap.Time("next_attempt_at", next),
+		zap.Error(err),
+	)
+	d.markRetry(ctx, msg, &next, err)
+}
+
+func (d *Dispatcher) markRetry(ctx context.Context, msg *entity.OutboxMessage, next *time.Time, cause error) {
+	if err := d.repo.MarkRetry(ctx, msg.ID, next, cause.Error()); err != nil {
+		tlog.Error("Failed to reschedule outbox message", zap.Uint("message_id", msg.ID), zap.Error(err))
+	}
+}
@@ -0,0 +1,48 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/thienel/go-backend-template/internal/domain/entity"
+	"github.com/thienel/go-backend-template/internal/domain/event"
+	"github.com/thienel/go-backend-template/internal/domain/repository"
+	apperror "github.com/thienel/go-backend-template/pkg/error"
+)
+
+type publisher struct {
+	repo repository.OutboxRepository
+}
+
+// NewPublisher creates a publisher that stores events in the outbox, in the transaction
+// of the publishing context, for the dispatcher to deliver once it commits
+func NewPublisher(repo repository.OutboxRepository) event.Publisher {
+	return &publisher{repo: repo}
+}
+
+func (p *publisher) Publish(ctx context.Context, events ...event.Event) error {
+	now := time.Now()
+	messages := make([]*entity.OutboxMessage, 0, len(events))
+	for _, evt := range events {
+		payload, err := json.Marshal(evt)
+		if err != nil {
+			return apperror.ErrInternalServerError.WithMessage("Không thể lưu sự kiện").
+				WithError(fmt.Errorf("encode %s: %w", evt.EventName(), err))
+		}
+
+		occurredAt := evt.OccurredAt()
+		if occurredAt.IsZero() {
+			occurredAt = now
+		}
+		messages = append(messages, &entity.OutboxMessage{
+			EventName:     evt.EventName(),
+			Payload:       payload,
+			OccurredAt:    occurredAt,
+			Status:        entity.OutboxStatusPending,
+			NextAttemptAt: now,
+		})
+	}
+	return p.repo.Add(ctx, messages...)
+}
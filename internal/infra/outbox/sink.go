@@ -0,0 +1,54 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/thienel/go-backend-template/internal/domain/event"
+)
+
+// WriterSink writes each event as a line of JSON, for local development and debugging
+type WriterSink struct {
+	mu     sync.Mutex
+	w      io.Writer
+	closer io.Closer
+}
+
+// NewStdoutSink creates a sink writing to standard output
+func NewStdoutSink() *WriterSink {
+	return &WriterSink{w: os.Stdout}
+}
+
+// NewFileSink creates a sink appending to the file at path
+func NewFileSink(path string) (*WriterSink, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &WriterSink{w: f, closer: f}, nil
+}
+
+// Publish writes the events, which the dispatcher passes as event.Message values
+func (s *WriterSink) Publish(_ context.Context, events ...event.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	enc := json.NewEncoder(s.w)
+	for _, evt := range events {
+		if err := enc.Encode(evt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close closes the file of a file sink
+func (s *WriterSink) Close() error {
+	if s.closer == nil {
+		return nil
+	}
+	return s.closer.Close()
+}
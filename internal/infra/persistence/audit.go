@@ -0,0 +1,135 @@
+package persistence
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+
+	"github.com/thienel/go-backend-template/internal/domain/entity"
+	"github.com/thienel/go-backend-template/internal/domain/valueobject"
+)
+
+// Audit actions, aliased for files where entity is a variable name
+const (
+	auditCreate  = entity.AuditActionCreate
+	auditUpdate  = entity.AuditActionUpdate
+	auditDelete  = entity.AuditActionDelete
+	auditRestore = entity.AuditActionRestore
+	auditPurge   = entity.AuditActionPurge
+)
+
+// redactedValue is the JSON form of entity.AuditRedacted
+var redactedValue, _ = json.Marshal(entity.AuditRedacted)
+
+// writeAudit records a change of an entity in the audit log, in the transaction of ctx
+// if there is one. before is nil for creations and after for deletions. Updates that
+// changed nothing are not recorded.
+func writeAudit(ctx context.Context, db *gorm.DB, entityType, action string, before, after any) error {
+	tx := conn(ctx, db)
+
+	subject := after
+	if subject == nil {
+		subject = before
+	}
+	stmt := &gorm.Statement{DB: tx}
+	if err := stmt.Parse(subject); err != nil {
+		return err
+	}
+
+	id, err := auditEntityID(ctx, stmt.Schema, subject)
+	if err != nil {
+		return err
+	}
+
+	changes := diffColumns(ctx, stmt.Schema, before, after)
+	if len(changes) == 0 && action == entity.AuditActionUpdate {
+		return nil
+	}
+
+	fields := make([]string, 0, len(changes))
+	for column := range changes {
+		fields = append(fields, column)
+	}
+	sort.Strings(fields)
+
+	meta := valueobject.RequestMetaFromContext(ctx)
+	log := &entity.AuditLog{
+		Action:        action,
+		EntityType:    entityType,
+		EntityID:      id,
+		Changes:       changes,
+		ChangedFields: fields,
+		RequestID:     meta.RequestID,
+		IP:            meta.IP,
+	}
+	if meta.ActorID != 0 {
+		log.ActorID = &meta.ActorID
+	}
+	return tx.Create(log).Error
+}
+
+// diffColumns compares the columns of two snapshots of an entity by their JSON values.
+// Relations and auto-updated timestamps are left out, and redacted columns only show
+// that they changed.
+func diffColumns(ctx context.Context, s *schema.Schema, before, after any) map[string]entity.AuditChange {
+	changes := make(map[string]entity.AuditChange)
+	for _, field := range s.Fields {
+		// The primary key is the entry's entity ID already
+		if field.DBName == "" || field.AutoUpdateTime > 0 || field.PrimaryKey {
+			continue
+		}
+
+		beforeJSON := columnJSON(ctx, field, before)
+		afterJSON := columnJSON(ctx, field, after)
+		if bytes.Equal(beforeJSON, afterJSON) {
+			continue
+		}
+
+		if field.Tag.Get("audit") == "redact" {
+			if beforeJSON != nil {
+				beforeJSON = redactedValue
+			}
+			if afterJSON != nil {
+				afterJSON = redactedValue
+			}
+		}
+		changes[field.DBName] = entity.AuditChange{Before: beforeJSON, After: afterJSON}
+	}
+	return changes
+}
+
+// columnJSON returns the JSON value of a column of v, or nil if there is no v
+func columnJSON(ctx context.Context, field *schema.Field, v any) json.RawMessage {
+	if v == nil {
+		return nil
+	}
+	value, _ := field.ValueOf(ctx, reflect.ValueOf(v).Elem())
+	data, err := json.Marshal(value)
+	if err != nil {
+		return json.RawMessage(fmt.Sprintf("%q", fmt.Sprint(value)))
+	}
+	return data
+}
+
+// auditEntityID identifies v in the audit log by the ID that clients know it by: its
+// public ID if it has one, else its primary key
+func auditEntityID(ctx context.Context, s *schema.Schema, v any) (string, error) {
+	field := s.LookUpField(publicIDColumn)
+	if field == nil {
+		field = s.PrioritizedPrimaryField
+	}
+	if field == nil {
+		return "", fmt.Errorf("%s has no primary key", s.Name)
+	}
+	value, zero := field.ValueOf(ctx, reflect.ValueOf(v).Elem())
+	if zero {
+		return "", fmt.Errorf("%s has no %s", s.Name, field.DBName)
+	}
+	return fmt.Sprint(value), nil
+}
@@ -0,0 +1,18 @@
+package persistence
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/thienel/go-backend-template/internal/domain/entity"
+	"github.com/thienel/go-backend-template/internal/domain/repository"
+)
+
+type auditLogRepositoryImpl struct {
+	*BaseRepositoryImpl[entity.AuditLog, uint]
+}
+
+// NewAuditLogRepository creates a new audit log repository
+func NewAuditLogRepository(db *gorm.DB) repository.AuditLogRepository {
+	base := NewBaseRepository[entity.AuditLog, uint](db, repository.AuditLogQuerySchema, repository.AuditLogProjection, "nhật ký thay đổi")
+	return &auditLogRepositoryImpl{BaseRepositoryImpl: base}
+}
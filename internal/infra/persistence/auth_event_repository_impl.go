@@ -0,0 +1,18 @@
+package persistence
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/thienel/go-backend-template/internal/domain/entity"
+	"github.com/thienel/go-backend-template/internal/domain/repository"
+)
+
+type authEventRepositoryImpl struct {
+	*BaseRepositoryImpl[entity.AuthEvent, uint]
+}
+
+// NewAuthEventRepository creates a new auth event repository
+func NewAuthEventRepository(db *gorm.DB) repository.AuthEventRepository {
+	base := NewBaseRepository[entity.AuthEvent, uint](db, repository.AuthEventQuerySchema, repository.AuthEventProjection, "sự kiện xác thực")
+	return &authEventRepositoryImpl{BaseRepositoryImpl: base}
+}
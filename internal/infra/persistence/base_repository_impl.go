@@ -2,93 +2,337 @@ package persistence
 
 import (
 	"context"
+	"fmt"
+	"reflect"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
 
+	apperror "github.com/thienel/go-backend-template/pkg/error"
 	"github.com/thienel/go-backend-template/pkg/query"
 )
 
-// BaseRepositoryImpl provides generic CRUD operations
-type BaseRepositoryImpl[T any] struct {
-	DB            *gorm.DB
-	AllowedFields map[string]bool
-	EntityName    string
+// defaultListSort orders list results newest first when the caller does not sort
+var defaultListSort = query.SortField{Field: "created_at", Desc: true}
+
+// versionColumn is the optimistic locking column; entities that have it are updated
+// conditionally (see Update)
+const versionColumn = "version"
+
+// unversionedTag marks fields written without bumping the version, such as login
+// tracking, with `version:"skip"`. Versioned updates leave them alone, since the entity
+// being saved may carry values older than the stored ones.
+const unversionedTag = "skip"
+
+// BaseRepositoryImpl provides generic CRUD operations on entities of type T with
+// primary keys of type ID
+type BaseRepositoryImpl[T any, ID comparable] struct {
+	DB         *gorm.DB
+	Schema     query.Schema
+	Projection query.Projection
+	EntityName string
+
+	// Search enables the search filter; nil for entities that are not searchable
+	Search *query.SearchConfig
+
+	// AuditEntity names the entity in the audit log; empty for entities that are not audited
+	AuditEntity string
 }
 
 // NewBaseRepository creates a new base repository
-func NewBaseRepository[T any](db *gorm.DB, allowedFields map[string]bool, entityName string) *BaseRepositoryImpl[T] {
-	return &BaseRepositoryImpl[T]{
-		DB:            db,
-		AllowedFields: allowedFields,
-		EntityName:    entityName,
+func NewBaseRepository[T any, ID comparable](db *gorm.DB, schema query.Schema, projection query.Projection, entityName string) *BaseRepositoryImpl[T, ID] {
+	return &BaseRepositoryImpl[T, ID]{
+		DB:         db,
+		Schema:     schema,
+		Projection: projection,
+		EntityName: entityName,
 	}
 }
 
+// conn returns the connection for ctx, joining a transaction started by a TxManager
+func (r *BaseRepositoryImpl[T, ID]) conn(ctx context.Context) *gorm.DB {
+	return conn(ctx, r.DB)
+}
+
 // Create creates a new entity
-func (r *BaseRepositoryImpl[T]) Create(ctx context.Context, entity *T) error {
-	if err := r.DB.WithContext(ctx).Create(entity).Error; err != nil {
-		return wrapCreateError(err, r.EntityName)
-	}
-	return nil
+func (r *BaseRepositoryImpl[T, ID]) Create(ctx context.Context, entity *T) error {
+	return r.audited(ctx, func(ctx context.Context) error {
+		if err := r.conn(ctx).Create(entity).Error; err != nil {
+			return wrapCreateError(err, r.EntityName)
+		}
+		return r.audit(ctx, auditCreate, nil, entity)
+	})
 }
 
 // FindByID finds an entity by ID
-func (r *BaseRepositoryImpl[T]) FindByID(ctx context.Context, id uint) (*T, error) {
+func (r *BaseRepositoryImpl[T, ID]) FindByID(ctx context.Context, id ID) (*T, error) {
 	var entity T
-	if err := r.DB.WithContext(ctx).First(&entity, id).Error; err != nil {
+	if err := r.conn(ctx).Where(byPrimaryKey(id)).First(&entity).Error; err != nil {
 		return nil, wrapFindError(err, r.EntityName)
 	}
 	return &entity, nil
 }
 
-// Update updates an entity
-func (r *BaseRepositoryImpl[T]) Update(ctx context.Context, entity *T) error {
-	if err := r.DB.WithContext(ctx).Save(entity).Error; err != nil {
+// FindByIDWithOptions finds an entity by ID, applying the sparse fieldset, includes and
+// soft-delete visibility of opts
+func (r *BaseRepositoryImpl[T, ID]) FindByIDWithOptions(ctx context.Context, id ID, opts query.QueryOptions) (*T, error) {
+	var entity T
+	if err := r.conn(ctx).Scopes(
+		query.ApplyDeletedScope(opts),
+		query.ApplyProjection(opts, r.Projection),
+	).Where(byPrimaryKey(id)).First(&entity).Error; err != nil {
+		return nil, wrapFindError(err, r.EntityName)
+	}
+	return &entity, nil
+}
+
+// Update updates an entity. Entities with a version column are updated only if the
+// stored version still matches theirs, and get the next version; otherwise the update
+// fails with ErrVersionConflict and the entity is left unchanged. Fields tagged
+// `version:"skip"` are not written by versioned updates.
+func (r *BaseRepositoryImpl[T, ID]) Update(ctx context.Context, entity *T) error {
+	return r.audited(ctx, func(ctx context.Context) error {
+		var before *T
+		if r.AuditEntity != "" {
+			id, err := r.primaryKey(ctx, entity)
+			if err != nil {
+				return wrapUpdateError(err, r.EntityName)
+			}
+			if before, err = r.FindByID(ctx, id); err != nil {
+				return err
+			}
+		}
+
+		if err := r.update(ctx, entity, before); err != nil {
+			return err
+		}
+		return r.audit(ctx, auditUpdate, before, entity)
+	})
+}
+
+// update writes entity; before, if known, is the stored entity, whose unversioned fields
+// entity takes over since they are not written
+func (r *BaseRepositoryImpl[T, ID]) update(ctx context.Context, entity, before *T) error {
+	db := r.conn(ctx)
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(entity); err != nil {
 		return wrapUpdateError(err, r.EntityName)
 	}
-	return nil
+
+	version := stmt.Schema.LookUpField(versionColumn)
+	if version == nil {
+		if err := db.Save(entity).Error; err != nil {
+			return wrapUpdateError(err, r.EntityName)
+		}
+		return nil
+	}
+
+	rv := reflect.ValueOf(entity).Elem()
+	value, _ := version.ValueOf(ctx, rv)
+	current, ok := value.(uint)
+	if !ok {
+		return wrapUpdateError(fmt.Errorf("%s.%s must be uint", stmt.Schema.Name, version.Name), r.EntityName)
+	}
+	if err := version.Set(ctx, rv, current+1); err != nil {
+		return wrapUpdateError(err, r.EntityName)
+	}
+
+	omit := []string{clause.Associations}
+	var unversioned []*schema.Field
+	for _, field := range stmt.Schema.Fields {
+		if field.DBName != "" && field.Tag.Get("version") == unversionedTag {
+			omit = append(omit, field.DBName)
+			unversioned = append(unversioned, field)
+		}
+	}
+
+	result := db.Model(entity).Select("*").Omit(omit...).
+		Where(versionColumn+" = ?", current).
+		Updates(entity)
+	if result.Error == nil && result.RowsAffected == 1 {
+		if before != nil {
+			stored := reflect.ValueOf(before).Elem()
+			for _, field := range unversioned {
+				value, _ := field.ValueOf(ctx, stored)
+				if err := field.Set(ctx, rv, value); err != nil {
+					return wrapUpdateError(err, r.EntityName)
+				}
+			}
+		}
+		return nil
+	}
+
+	_ = version.Set(ctx, rv, current)
+	if result.Error != nil {
+		return wrapUpdateError(result.Error, r.EntityName)
+	}
+
+	// Nothing matched: either the row is gone or someone else updated it first
+	id, _ := r.primaryKey(ctx, entity)
+	if exists, err := r.Exists(ctx, id); err != nil {
+		return err
+	} else if !exists {
+		return wrapFindError(gorm.ErrRecordNotFound, r.EntityName)
+	}
+	return apperror.ErrVersionConflict
 }
 
 // Delete soft-deletes an entity
-func (r *BaseRepositoryImpl[T]) Delete(ctx context.Context, id uint) error {
+func (r *BaseRepositoryImpl[T, ID]) Delete(ctx context.Context, id ID) error {
+	return r.audited(ctx, func(ctx context.Context) error {
+		before, err := r.auditSnapshot(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		var entity T
+		if err := r.conn(ctx).Where(byPrimaryKey(id)).Delete(&entity).Error; err != nil {
+			return wrapDeleteError(err, r.EntityName)
+		}
+		return r.audit(ctx, auditDelete, before, nil)
+	})
+}
+
+// DeleteVersion soft-deletes an entity only if its stored version matches, failing with
+// ErrVersionConflict otherwise
+func (r *BaseRepositoryImpl[T, ID]) DeleteVersion(ctx context.Context, id ID, version uint) error {
+	return r.audited(ctx, func(ctx context.Context) error {
+		before, err := r.auditSnapshot(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := r.deleteVersion(ctx, id, version); err != nil {
+			return err
+		}
+		return r.audit(ctx, auditDelete, before, nil)
+	})
+}
+
+func (r *BaseRepositoryImpl[T, ID]) deleteVersion(ctx context.Context, id ID, version uint) error {
 	var entity T
-	if err := r.DB.WithContext(ctx).Delete(&entity, id).Error; err != nil {
-		return wrapDeleteError(err, r.EntityName)
+	result := r.conn(ctx).Where(byPrimaryKey(id)).Where(versionColumn+" = ?", version).Delete(&entity)
+	if result.Error != nil {
+		return wrapDeleteError(result.Error, r.EntityName)
 	}
-	return nil
+	if result.RowsAffected == 1 {
+		return nil
+	}
+
+	if exists, err := r.Exists(ctx, id); err != nil {
+		return err
+	} else if !exists {
+		return wrapFindError(gorm.ErrRecordNotFound, r.EntityName)
+	}
+	return apperror.ErrVersionConflict
 }
 
 // List lists entities with query options
-func (r *BaseRepositoryImpl[T]) List(ctx context.Context, offset, limit int, opts query.QueryOptions) ([]T, int64, error) {
-	var entities []T
-	var total int64
-
-	q := r.DB.WithContext(ctx).Model(new(T))
+func (r *BaseRepositoryImpl[T, ID]) List(ctx context.Context, page query.Pagination, opts query.QueryOptions) (query.Page[T], error) {
+	q := r.conn(ctx).Model(new(T)).Scopes(
+		query.ApplyDeletedScope(opts),
+		query.ApplyFilters(opts, r.Schema),
+	)
+	scopes := []func(*gorm.DB) *gorm.DB{query.ApplyProjection(opts, r.Projection)}
 
-	// Apply filters
-	q = q.Scopes(query.ApplyFilters(opts, r.AllowedFields))
+	// The search term is a virtual filter, so ApplyFilters skips it
+	term, searching := query.SearchTerm(opts)
+	searching = searching && r.Search != nil
+	if searching {
+		q = q.Scopes(query.ApplySearch(term, *r.Search))
+		// Keyset pages cannot be ordered by relevance, so cursor mode keeps the plain sort
+		if len(opts.Sort) == 0 && !page.IsCursor() {
+			scopes = append(scopes, query.ApplySearchRank(term, *r.Search))
+		}
+	}
 
-	// Count total
-	if err := q.Count(&total).Error; err != nil {
-		return nil, 0, wrapListError(err, r.EntityName)
+	result, err := query.Paginate[T](q, page, opts, r.Schema, defaultListSort, scopes...)
+	if err != nil {
+		return query.Page[T]{}, wrapListError(err, r.EntityName)
 	}
 
-	// Apply sort and pagination
-	if err := q.Scopes(
-		query.ApplySort(opts, r.AllowedFields),
-		query.ApplyDefaultSort(opts, "created_at", true),
-	).Offset(offset).Limit(limit).Find(&entities).Error; err != nil {
-		return nil, 0, wrapListError(err, r.EntityName)
+	// Facets and aggregates cover every row matching the filters, not just this page
+	if result.Facets, err = query.ComputeFacets(q, opts, r.Schema); err != nil {
+		return query.Page[T]{}, wrapListError(err, r.EntityName)
+	}
+	if result.Aggregates, err = query.ComputeAggregates(q, opts, r.Schema); err != nil {
+		return query.Page[T]{}, wrapListError(err, r.EntityName)
 	}
 
-	return entities, total, nil
+	if searching {
+		if result.Highlights, err = query.SearchHighlights(r.conn(ctx), *r.Search, term, result.Items); err != nil {
+			return query.Page[T]{}, wrapListError(err, r.EntityName)
+		}
+	}
+	return result, nil
 }
 
 // Exists checks if an entity exists
-func (r *BaseRepositoryImpl[T]) Exists(ctx context.Context, id uint) (bool, error) {
+func (r *BaseRepositoryImpl[T, ID]) Exists(ctx context.Context, id ID) (bool, error) {
 	var count int64
-	if err := r.DB.WithContext(ctx).Model(new(T)).Where("id = ?", id).Count(&count).Error; err != nil {
+	if err := r.conn(ctx).Model(new(T)).Where(byPrimaryKey(id)).Count(&count).Error; err != nil {
 		return false, wrapFindError(err, r.EntityName)
 	}
 	return count > 0, nil
 }
+
+// audited runs fn in a transaction when the entity is audited, so that a change and its
+// audit row are written together
+func (r *BaseRepositoryImpl[T, ID]) audited(ctx context.Context, fn func(ctx context.Context) error) error {
+	if r.AuditEntity == "" {
+		return fn(ctx)
+	}
+	return runInTransaction(ctx, r.DB, fn)
+}
+
+// audit records a change in the audit log if the entity is audited
+func (r *BaseRepositoryImpl[T, ID]) audit(ctx context.Context, action string, before, after *T) error {
+	if r.AuditEntity == "" {
+		return nil
+	}
+
+	// Pass untyped nils, so that writeAudit can tell a missing snapshot apart
+	var b, a any
+	if before != nil {
+		b = before
+	}
+	if after != nil {
+		a = after
+	}
+	if err := writeAudit(ctx, r.DB, r.AuditEntity, action, b, a); err != nil {
+		return apperror.ErrInternalServerError.WithMessage("Không thể ghi nhật ký thay đổi").WithError(err)
+	}
+	return nil
+}
+
+// auditSnapshot loads the entity as it is before a change, if the entity is audited
+func (r *BaseRepositoryImpl[T, ID]) auditSnapshot(ctx context.Context, id ID) (*T, error) {
+	if r.AuditEntity == "" {
+		return nil, nil
+	}
+	return r.FindByID(ctx, id)
+}
+
+func (r *BaseRepositoryImpl[T, ID]) primaryKey(ctx context.Context, entity *T) (ID, error) {
+	var id ID
+	stmt := &gorm.Statement{DB: r.DB}
+	if err := stmt.Parse(entity); err != nil {
+		return id, err
+	}
+	if stmt.Schema.PrioritizedPrimaryField == nil {
+		return id, fmt.Errorf("%s has no primary key", stmt.Schema.Name)
+	}
+	value, _ := stmt.Schema.PrioritizedPrimaryField.ValueOf(ctx, reflect.ValueOf(entity).Elem())
+	id, ok := value.(ID)
+	if !ok {
+		return id, fmt.Errorf("%s primary key is %T, not %T", stmt.Schema.Name, value, id)
+	}
+	return id, nil
+}
+
+// byPrimaryKey matches the row with primary key id. Unlike passing id to First or Delete,
+// it also works for keys that GORM would take for SQL or a list of keys, such as strings
+// and UUIDs.
+func byPrimaryKey(id any) clause.Expression {
+	return clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: clause.PrimaryKey}, Value: id}
+}
@@ -0,0 +1,215 @@
+package persistence
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/thienel/tlog"
+	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
+
+	"github.com/thienel/go-backend-template/internal/domain/repository"
+	"github.com/thienel/go-backend-template/pkg/cache"
+)
+
+// cachedRepository serves FindByID of a repository from a cache store and evicts an
+// entity when it is changed through the repository.
+//
+// Reads that must see the latest writes (repository.ReadsYourWrites, set for every
+// mutating request) bypass the cache, so a change is never based on a cached copy, and
+// so do reads in a transaction, which may see rows that are not committed yet. An entity
+// is evicted when it is written and again when the transaction commits, since a
+// concurrent read may have cached the old row in between.
+// Cache failures are logged and counted, and the query falls through to the database.
+type cachedRepository[T any, ID comparable] struct {
+	repository.BaseRepository[T, ID]
+
+	name    string
+	store   cache.Store
+	ttl     time.Duration
+	metrics *cache.Metrics
+	// loads lets concurrent misses of a key share one query
+	loads singleflight.Group
+}
+
+// NewCachedRepository wraps repo with a cache of its entities by ID, under keys and
+// metrics named name
+func NewCachedRepository[T any, ID comparable](repo repository.BaseRepository[T, ID], name string, store cache.Store, ttl time.Duration, metrics *cache.Metrics) repository.BaseRepository[T, ID] {
+	return newCachedRepository(repo, name, store, ttl, metrics)
+}
+
+func newCachedRepository[T any, ID comparable](repo repository.BaseRepository[T, ID], name string, store cache.Store, ttl time.Duration, metrics *cache.Metrics) *cachedRepository[T, ID] {
+	return &cachedRepository[T, ID]{BaseRepository: repo, name: name, store: store, ttl: ttl, metrics: metrics}
+}
+
+func (r *cachedRepository[T, ID]) FindByID(ctx context.Context, id ID) (*T, error) {
+	if repository.ReadsYourWrites(ctx) || inTransaction(ctx) {
+		return r.BaseRepository.FindByID(ctx, id)
+	}
+
+	key := r.idKey(id)
+	if entity, ok := r.get(ctx, key); ok {
+		r.metrics.Hit(r.name)
+		return entity, nil
+	}
+	r.metrics.Miss(r.name)
+
+	return r.load(ctx, key, func(ctx context.Context) (*T, error) {
+		entity, err := r.BaseRepository.FindByID(ctx, id)
+		if err == nil {
+			r.put(ctx, key, entity)
+		}
+		return entity, err
+	})
+}
+
+func (r *cachedRepository[T, ID]) Update(ctx context.Context, entity *T) error {
+	if err := r.BaseRepository.Update(ctx, entity); err != nil {
+		return err
+	}
+	id, err := r.entityID(entity)
+	if err != nil {
+		return err
+	}
+	r.evict(ctx, id)
+	return nil
+}
+
+func (r *cachedRepository[T, ID]) Delete(ctx context.Context, id ID) error {
+	if err := r.BaseRepository.Delete(ctx, id); err != nil {
+		return err
+	}
+	r.evict(ctx, id)
+	return nil
+}
+
+func (r *cachedRepository[T, ID]) DeleteVersion(ctx context.Context, id ID, version uint) error {
+	if err := r.BaseRepository.DeleteVersion(ctx, id, version); err != nil {
+		return err
+	}
+	r.evict(ctx, id)
+	return nil
+}
+
+// key returns the cache key of an entity looked up by field
+func (r *cachedRepository[T, ID]) key(field, value string) string {
+	return r.name + ":" + field + ":" + value
+}
+
+func (r *cachedRepository[T, ID]) idKey(id ID) string {
+	return r.key("id", fmt.Sprint(id))
+}
+
+// load runs fn once for all concurrent callers missing key. The query outlives the
+// caller that started it, as the others wait for it too. Each caller gets its own copy
+// of the entity, since callers modify what they find.
+func (r *cachedRepository[T, ID]) load(ctx context.Context, key string, fn func(ctx context.Context) (*T, error)) (*T, error) {
+	v, err, _ := r.loads.Do(key, func() (any, error) {
+		return fn(context.WithoutCancel(ctx))
+	})
+	if err != nil {
+		return nil, err
+	}
+	entity := *v.(*T)
+	return &entity, nil
+}
+
+// get returns the entity cached at key. Entities are gob-encoded, which unlike JSON
+// keeps fields hidden from API responses, such as password hashes.
+func (r *cachedRepository[T, ID]) get(ctx context.Context, key string) (*T, bool) {
+	data, ok, err := r.store.Get(ctx, key)
+	if err != nil {
+		r.failed("read", key, err)
+		return nil, false
+	}
+	if !ok {
+		return nil, false
+	}
+
+	entity := new(T)
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(entity); err != nil {
+		// Written by a version with another layout; it is replaced on load
+		r.failed("decode", key, err)
+		return nil, false
+	}
+	return entity, true
+}
+
+func (r *cachedRepository[T, ID]) put(ctx context.Context, key string, entity *T) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(entity); err != nil {
+		r.failed("encode", key, err)
+		return
+	}
+	if err := r.store.Set(ctx, key, buf.Bytes(), r.ttl); err != nil {
+		r.failed("write", key, err)
+	}
+}
+
+// getID returns the entity ID cached at the secondary key key
+func (r *cachedRepository[T, ID]) getID(ctx context.Context, key string) (ID, bool) {
+	var id ID
+	data, ok, err := r.store.Get(ctx, key)
+	if err != nil {
+		r.failed("read", key, err)
+		return id, false
+	}
+	if !ok {
+		return id, false
+	}
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&id); err != nil {
+		r.failed("decode", key, err)
+		return id, false
+	}
+	return id, true
+}
+
+func (r *cachedRepository[T, ID]) putID(ctx context.Context, key string, id ID) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(id); err != nil {
+		r.failed("encode", key, err)
+		return
+	}
+	if err := r.store.Set(ctx, key, buf.Bytes(), r.ttl); err != nil {
+		r.failed("write", key, err)
+	}
+}
+
+// evict removes the cached entity with the given ID, now and once the transaction in
+// ctx commits; entries pointing at it by other fields are checked against the entity
+// when read, so they can stay
+func (r *cachedRepository[T, ID]) evict(ctx context.Context, id ID) {
+	key := r.idKey(id)
+	r.delete(ctx, key)
+	if inTransaction(ctx) {
+		ctx = context.WithoutCancel(ctx)
+		afterCommit(ctx, func() { r.delete(ctx, key) })
+	}
+}
+
+func (r *cachedRepository[T, ID]) delete(ctx context.Context, key string) {
+	if err := r.store.Delete(ctx, key); err != nil {
+		r.failed("delete", key, err)
+	}
+}
+
+func (r *cachedRepository[T, ID]) failed(op, key string, err error) {
+	r.metrics.Error(r.name)
+	tlog.Warn("Cache "+op+" failed", zap.String("key", key), zap.Error(err))
+}
+
+// entityID reads the ID field that every entity has
+func (r *cachedRepository[T, ID]) entityID(entity *T) (ID, error) {
+	field := reflect.ValueOf(entity).Elem().FieldByName("ID")
+	if field.IsValid() {
+		if id, ok := field.Interface().(ID); ok {
+			return id, nil
+		}
+	}
+	var id ID
+	return id, fmt.Errorf("cache: %T has no ID field of type %T", entity, id)
+}
@@ -0,0 +1,83 @@
+package persistence
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/thienel/go-backend-template/internal/domain/repository"
+	"github.com/thienel/go-backend-template/pkg/cache"
+)
+
+type cachedItem struct {
+	ID   uint
+	Name string
+}
+
+// fakeItemRepository keeps items in a map; only the methods used are implemented
+type fakeItemRepository struct {
+	repository.BaseRepository[cachedItem, uint]
+	items map[uint]cachedItem
+}
+
+func (r *fakeItemRepository) FindByID(_ context.Context, id uint) (*cachedItem, error) {
+	item := r.items[id]
+	return &item, nil
+}
+
+func (r *fakeItemRepository) Update(_ context.Context, item *cachedItem) error {
+	r.items[item.ID] = *item
+	return nil
+}
+
+func newTestCachedRepository() (*cachedRepository[cachedItem, uint], *fakeItemRepository, cache.Store) {
+	repo := &fakeItemRepository{items: map[uint]cachedItem{1: {ID: 1, Name: "old"}}}
+	store := cache.NewMemory(10)
+	return newCachedRepository[cachedItem, uint](repo, "item", store, time.Minute, cache.NewMetrics()), repo, store
+}
+
+func TestCachedRepositoryInTransaction(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+
+	t.Run("reads do not populate the cache", func(t *testing.T) {
+		cached, _, store := newTestCachedRepository()
+		err := runInTransaction(ctx, db, func(ctx context.Context) error {
+			_, err := cached.FindByID(ctx, 1)
+			return err
+		})
+		if err != nil {
+			t.Fatalf("run: %v", err)
+		}
+		if _, ok, _ := store.Get(ctx, cached.idKey(1)); ok {
+			t.Error("read in a transaction was cached")
+		}
+	})
+
+	t.Run("write is evicted after commit", func(t *testing.T) {
+		cached, repo, _ := newTestCachedRepository()
+		err := runInTransaction(ctx, db, func(txCtx context.Context) error {
+			if err := cached.Update(txCtx, &cachedItem{ID: 1, Name: "new"}); err != nil {
+				return err
+			}
+			// A concurrent read caches the row as it was before the commit
+			repo.items[1] = cachedItem{ID: 1, Name: "old"}
+			if _, err := cached.FindByID(ctx, 1); err != nil {
+				return err
+			}
+			repo.items[1] = cachedItem{ID: 1, Name: "new"}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("run: %v", err)
+		}
+
+		item, err := cached.FindByID(ctx, 1)
+		if err != nil {
+			t.Fatalf("FindByID: %v", err)
+		}
+		if item.Name != "new" {
+			t.Errorf("Name = %q, want %q", item.Name, "new")
+		}
+	})
+}
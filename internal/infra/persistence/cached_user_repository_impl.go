@@ -0,0 +1,128 @@
+package persistence
+
+import (
+	"context"
+	"time"
+
+	"github.com/thienel/go-backend-template/internal/domain/entity"
+	"github.com/thienel/go-backend-template/internal/domain/repository"
+	"github.com/thienel/go-backend-template/pkg/cache"
+)
+
+// userCacheName names the keys and metrics of the user cache
+const userCacheName = "user"
+
+// cachedUserRepository caches users by ID, username and email. Username and email keys
+// hold the user ID, so a rename only needs the entity evicted: a key whose user no
+// longer has that username or email is treated as a miss. Public IDs never change, so
+// the IDs they resolve to are cached as they are.
+type cachedUserRepository struct {
+	repository.UserRepository
+	cache *cachedRepository[entity.User, uint]
+}
+
+// NewCachedUserRepository wraps repo with a cache of users; see NewCachedRepository
+func NewCachedUserRepository(repo repository.UserRepository, store cache.Store, ttl time.Duration, metrics *cache.Metrics) repository.UserRepository {
+	return &cachedUserRepository{
+		UserRepository: repo,
+		cache:          newCachedRepository[entity.User, uint](repo, userCacheName, store, ttl, metrics),
+	}
+}
+
+func (r *cachedUserRepository) FindByID(ctx context.Context, id uint) (*entity.User, error) {
+	return r.cache.FindByID(ctx, id)
+}
+
+func (r *cachedUserRepository) FindByUsername(ctx context.Context, username string) (*entity.User, error) {
+	return r.findBy(ctx, "username", username, r.UserRepository.FindByUsername, func(user *entity.User) bool {
+		return user.Username == username
+	})
+}
+
+func (r *cachedUserRepository) FindByEmail(ctx context.Context, email string) (*entity.User, error) {
+	return r.findBy(ctx, "email", email, r.UserRepository.FindByEmail, func(user *entity.User) bool {
+		return user.Email == email
+	})
+}
+
+func (r *cachedUserRepository) FindIDByPublicID(ctx context.Context, publicID entity.PublicID) (uint, error) {
+	// The user may be created by a transaction that rolls back
+	if inTransaction(ctx) {
+		return r.UserRepository.FindIDByPublicID(ctx, publicID)
+	}
+
+	key := r.cache.key("public_id", publicID.String())
+	if id, ok := r.cache.getID(ctx, key); ok {
+		r.cache.metrics.Hit(userCacheName)
+		return id, nil
+	}
+	r.cache.metrics.Miss(userCacheName)
+
+	id, err := r.UserRepository.FindIDByPublicID(ctx, publicID)
+	if err == nil {
+		r.cache.putID(ctx, key, id)
+	}
+	return id, err
+}
+
+func (r *cachedUserRepository) Update(ctx context.Context, user *entity.User) error {
+	return r.cache.Update(ctx, user)
+}
+
+func (r *cachedUserRepository) Delete(ctx context.Context, id uint) error {
+	return r.cache.Delete(ctx, id)
+}
+
+func (r *cachedUserRepository) DeleteVersion(ctx context.Context, id uint, version uint) error {
+	return r.cache.DeleteVersion(ctx, id, version)
+}
+
+func (r *cachedUserRepository) Restore(ctx context.Context, id uint) error {
+	if err := r.UserRepository.Restore(ctx, id); err != nil {
+		return err
+	}
+	r.cache.evict(ctx, id)
+	return nil
+}
+
+func (r *cachedUserRepository) UpdateLastLogin(ctx context.Context, id uint, at time.Time, ip string) error {
+	if err := r.UserRepository.UpdateLastLogin(ctx, id, at, ip); err != nil {
+		return err
+	}
+	r.cache.evict(ctx, id)
+	return nil
+}
+
+func (r *cachedUserRepository) Purge(ctx context.Context, id uint, version uint) error {
+	if err := r.UserRepository.Purge(ctx, id, version); err != nil {
+		return err
+	}
+	r.cache.evict(ctx, id)
+	return nil
+}
+
+// findBy looks a user up by a unique field through the cached ID, loading it with load
+// on a miss
+func (r *cachedUserRepository) findBy(ctx context.Context, field, value string, load func(context.Context, string) (*entity.User, error), matches func(*entity.User) bool) (*entity.User, error) {
+	if repository.ReadsYourWrites(ctx) || inTransaction(ctx) {
+		return load(ctx, value)
+	}
+
+	key := r.cache.key(field, value)
+	if id, ok := r.cache.getID(ctx, key); ok {
+		if user, ok := r.cache.get(ctx, r.cache.idKey(id)); ok && matches(user) {
+			r.cache.metrics.Hit(userCacheName)
+			return user, nil
+		}
+	}
+	r.cache.metrics.Miss(userCacheName)
+
+	return r.cache.load(ctx, key, func(ctx context.Context) (*entity.User, error) {
+		user, err := load(ctx, value)
+		if err == nil {
+			r.cache.put(ctx, r.cache.idKey(user.ID), user)
+			r.cache.putID(ctx, key, user.ID)
+		}
+		return user, err
+	})
+}
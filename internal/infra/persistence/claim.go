@@ -0,0 +1,44 @@
+package persistence
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// claimDue claims up to limit rows of T in pendingStatus whose next_attempt_at has
+// passed: it counts an attempt and pushes next_attempt_at past the lease, so that the
+// rows are not picked again until the lease runs out. SKIP LOCKED lets concurrent
+// workers claim disjoint batches; SQLite has no row locks and ignores it, which is fine
+// as it allows a single writer anyway.
+func claimDue[T any](ctx context.Context, db *gorm.DB, pendingStatus string, limit int, lease time.Duration) ([]T, error) {
+	var claimed []T
+	err := runInTransaction(ctx, db, func(ctx context.Context) error {
+		tx := conn(ctx, db)
+		now := time.Now()
+
+		var ids []uint
+		if err := tx.Model(new(T)).
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", pendingStatus, now).
+			Order("id").
+			Limit(limit).
+			Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+
+		if err := tx.Model(new(T)).Where("id IN ?", ids).Updates(map[string]any{
+			"attempts":        gorm.Expr("attempts + 1"),
+			"next_attempt_at": now.Add(lease),
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id IN ?", ids).Order("id").Find(&claimed).Error
+	})
+	return claimed, err
+}
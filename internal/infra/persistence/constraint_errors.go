@@ -0,0 +1,211 @@
+package persistence
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/mattn/go-sqlite3"
+)
+
+// violationKind is the kind of constraint a write violated
+type violationKind int
+
+const (
+	violationUnique violationKind = iota + 1
+	violationForeignKey
+	violationNotNull
+	violationCheck
+	violationTooLong
+)
+
+// constraintViolation is a constraint failure decoded from a driver error, with the
+// constraint and columns involved as far as the driver reports them
+type constraintViolation struct {
+	kind       violationKind
+	constraint string
+	columns    []string
+}
+
+// SQLSTATE codes of Postgres constraint failures
+const (
+	sqlStateUniqueViolation     = "23505"
+	sqlStateForeignKeyViolation = "23503"
+	sqlStateNotNullViolation    = "23502"
+	sqlStateCheckViolation      = "23514"
+	sqlStateStringTooLong       = "22001"
+)
+
+// SQLSTATE codes of Postgres failures that succeed when the transaction is run again
+const (
+	sqlStateSerializationFailure = "40001"
+	sqlStateDeadlockDetected     = "40P01"
+)
+
+// MySQL error numbers of failures that succeed when the transaction is run again
+const (
+	mysqlDeadlock        = 1213
+	mysqlLockWaitTimeout = 1205
+)
+
+// MySQL error numbers of constraint failures
+const (
+	mysqlDuplicateEntry  = 1062
+	mysqlRowIsReferenced = 1451
+	mysqlNoReferencedRow = 1452
+	mysqlColumnNotNull   = 1048
+	mysqlDataTooLong     = 1406
+	mysqlCheckViolated   = 3819
+)
+
+var (
+	// pgKeyDetail matches the columns in the detail of a Postgres key violation,
+	// e.g. `Key (username)=(bob) already exists.`
+	pgKeyDetail = regexp.MustCompile(`^Key \(([^)]*)\)=`)
+
+	mysqlDuplicateKey  = regexp.MustCompile(`for key '([^']+)'`)
+	mysqlForeignKey    = regexp.MustCompile("CONSTRAINT `([^`]+)` FOREIGN KEY \\(([^)]*)\\)")
+	mysqlColumn        = regexp.MustCompile(`[Cc]olumn '([^']+)'`)
+	mysqlConstraint    = regexp.MustCompile(`[Cc]onstraint '([^']+)'`)
+	sqliteFailedTarget = regexp.MustCompile(`constraint failed: (.+)$`)
+)
+
+// asConstraintViolation decodes err if it is a constraint failure of Postgres, MySQL or SQLite
+func asConstraintViolation(err error) (constraintViolation, bool) {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgViolation(pgErr)
+	}
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		return mysqlViolation(mysqlErr)
+	}
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteViolation(sqliteErr)
+	}
+	return constraintViolation{}, false
+}
+
+// isRetryableTxError reports whether err is a conflict with a concurrent transaction
+// that running the transaction again can get past: a Postgres serialization failure or
+// deadlock, a MySQL deadlock or lock wait timeout, or a busy or locked SQLite database
+func isRetryableTxError(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == sqlStateSerializationFailure || pgErr.Code == sqlStateDeadlockDetected
+	}
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		return mysqlErr.Number == mysqlDeadlock || mysqlErr.Number == mysqlLockWaitTimeout
+	}
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.Code == sqlite3.ErrBusy || sqliteErr.Code == sqlite3.ErrLocked
+	}
+	return false
+}
+
+func pgViolation(err *pgconn.PgError) (constraintViolation, bool) {
+	v := constraintViolation{constraint: err.ConstraintName}
+	switch err.Code {
+	case sqlStateUniqueViolation:
+		v.kind = violationUnique
+	case sqlStateForeignKeyViolation:
+		v.kind = violationForeignKey
+	case sqlStateNotNullViolation:
+		v.kind = violationNotNull
+	case sqlStateCheckViolation:
+		v.kind = violationCheck
+	case sqlStateStringTooLong:
+		v.kind = violationTooLong
+	default:
+		return constraintViolation{}, false
+	}
+
+	if err.ColumnName != "" {
+		v.columns = []string{err.ColumnName}
+	} else if match := pgKeyDetail.FindStringSubmatch(err.Detail); match != nil {
+		v.columns = splitColumns(match[1], "")
+	}
+	return v, true
+}
+
+func mysqlViolation(err *mysql.MySQLError) (constraintViolation, bool) {
+	var v constraintViolation
+	switch err.Number {
+	case mysqlDuplicateEntry:
+		v.kind = violationUnique
+		if match := mysqlDuplicateKey.FindStringSubmatch(err.Message); match != nil {
+			// MySQL 8 qualifies the key with its table
+			v.constraint = match[1][strings.LastIndex(match[1], ".")+1:]
+		}
+	case mysqlRowIsReferenced, mysqlNoReferencedRow:
+		v.kind = violationForeignKey
+		if match := mysqlForeignKey.FindStringSubmatch(err.Message); match != nil {
+			v.constraint = match[1]
+			v.columns = splitColumns(strings.ReplaceAll(match[2], "`", ""), "")
+		}
+	case mysqlColumnNotNull, mysqlDataTooLong:
+		v.kind = violationNotNull
+		if err.Number == mysqlDataTooLong {
+			v.kind = violationTooLong
+		}
+		if match := mysqlColumn.FindStringSubmatch(err.Message); match != nil {
+			v.columns = []string{match[1]}
+		}
+	case mysqlCheckViolated:
+		v.kind = violationCheck
+		if match := mysqlConstraint.FindStringSubmatch(err.Message); match != nil {
+			v.constraint = match[1]
+		}
+	default:
+		return constraintViolation{}, false
+	}
+	return v, true
+}
+
+// sqliteViolation decodes messages such as "UNIQUE constraint failed: users.username";
+// SQLite names the columns of unique and not-null failures, and the constraint of checks
+func sqliteViolation(err sqlite3.Error) (constraintViolation, bool) {
+	var v constraintViolation
+	switch err.ExtendedCode {
+	case sqlite3.ErrConstraintUnique, sqlite3.ErrConstraintPrimaryKey:
+		v.kind = violationUnique
+	case sqlite3.ErrConstraintForeignKey:
+		v.kind = violationForeignKey
+	case sqlite3.ErrConstraintNotNull:
+		v.kind = violationNotNull
+	case sqlite3.ErrConstraintCheck:
+		v.kind = violationCheck
+	default:
+		return constraintViolation{}, false
+	}
+
+	if match := sqliteFailedTarget.FindStringSubmatch(err.Error()); match != nil {
+		if v.kind == violationCheck {
+			v.constraint = match[1]
+		} else {
+			v.columns = splitColumns(match[1], ".")
+		}
+	}
+	return v, true
+}
+
+// splitColumns splits a comma-separated column list, dropping whatever precedes the
+// last qualifier in each, e.g. the table of "users.email" for qualifier "."
+func splitColumns(list, qualifier string) []string {
+	var columns []string
+	for _, column := range strings.Split(list, ",") {
+		column = strings.TrimSpace(column)
+		if qualifier != "" {
+			column = column[strings.LastIndex(column, qualifier)+1:]
+		}
+		if column != "" {
+			columns = append(columns, column)
+		}
+	}
+	return columns
+}
@@ -0,0 +1,232 @@
+package persistence
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	apperror "github.com/thienel/go-backend-template/pkg/error"
+)
+
+// wantConstraintError is what constraintError should make of a driver error
+type wantConstraintError struct {
+	code       string
+	constraint string
+	columns    []string
+}
+
+func checkConstraintError(t *testing.T, err error, deleting bool, want wantConstraintError) {
+	t.Helper()
+	appErr := constraintError(err, "người dùng", deleting)
+	if appErr == nil {
+		t.Fatalf("constraintError(%v) = nil, want %s", err, want.code)
+	}
+	if appErr.Code != want.code {
+		t.Errorf("code = %s, want %s", appErr.Code, want.code)
+	}
+	if want.constraint != "" && !strings.Contains(appErr.Message, "ràng buộc "+want.constraint+")") {
+		t.Errorf("message %q does not name constraint %q", appErr.Message, want.constraint)
+	}
+	var columns []string
+	for _, field := range appErr.Fields {
+		columns = append(columns, field.Field)
+	}
+	if !reflect.DeepEqual(columns, want.columns) {
+		t.Errorf("fields = %v, want %v", columns, want.columns)
+	}
+	if !errors.Is(appErr.Err, err) {
+		t.Errorf("driver error is not kept")
+	}
+}
+
+func TestConstraintErrorPostgres(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      *pgconn.PgError
+		deleting bool
+		want     wantConstraintError
+	}{
+		{
+			name: "unique",
+			err:  &pgconn.PgError{Code: "23505", ConstraintName: "idx_users_username", Detail: "Key (username)=(bob) already exists."},
+			want: wantConstraintError{code: apperror.ErrConflict.Code, constraint: "idx_users_username", columns: []string{"username"}},
+		},
+		{
+			name: "unique on several columns",
+			err:  &pgconn.PgError{Code: "23505", ConstraintName: "idx_saved_views_owner_resource_name", Detail: "Key (owner_id, resource, name)=(1, users, mine) already exists."},
+			want: wantConstraintError{code: apperror.ErrConflict.Code, constraint: "idx_saved_views_owner_resource_name", columns: []string{"owner_id", "resource", "name"}},
+		},
+		{
+			name: "missing reference",
+			err:  &pgconn.PgError{Code: "23503", ConstraintName: "fk_owner", Detail: "Key (owner_id)=(9) is not present in table \"users\"."},
+			want: wantConstraintError{code: apperror.ErrReferenceNotFound.Code, constraint: "fk_owner", columns: []string{"owner_id"}},
+		},
+		{
+			name:     "still referenced",
+			err:      &pgconn.PgError{Code: "23503", ConstraintName: "fk_owner", Detail: "Key (id)=(1) is still referenced from table \"saved_views\"."},
+			deleting: true,
+			want:     wantConstraintError{code: apperror.ErrResourceInUse.Code, constraint: "fk_owner", columns: []string{"id"}},
+		},
+		{
+			name: "not null",
+			err:  &pgconn.PgError{Code: "23502", ColumnName: "email"},
+			want: wantConstraintError{code: apperror.ErrRequiredField.Code, columns: []string{"email"}},
+		},
+		{
+			name: "check",
+			err:  &pgconn.PgError{Code: "23514", ConstraintName: "chk_users_status"},
+			want: wantConstraintError{code: apperror.ErrConstraintViolation.Code, constraint: "chk_users_status"},
+		},
+		{
+			name: "too long",
+			err:  &pgconn.PgError{Code: "22001"},
+			want: wantConstraintError{code: apperror.ErrConstraintViolation.Code},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkConstraintError(t, tt.err, tt.deleting, tt.want)
+		})
+	}
+}
+
+func TestConstraintErrorMySQL(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      *mysql.MySQLError
+		deleting bool
+		want     wantConstraintError
+	}{
+		{
+			name: "duplicate entry",
+			err:  &mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'bob' for key 'users.idx_users_username'"},
+			want: wantConstraintError{code: apperror.ErrConflict.Code, constraint: "idx_users_username"},
+		},
+		{
+			name:     "row is referenced",
+			err:      &mysql.MySQLError{Number: 1451, Message: "Cannot delete or update a parent row: a foreign key constraint fails (`app`.`saved_views`, CONSTRAINT `fk_owner` FOREIGN KEY (`owner_id`) REFERENCES `users` (`id`))"},
+			deleting: true,
+			want:     wantConstraintError{code: apperror.ErrResourceInUse.Code, constraint: "fk_owner", columns: []string{"owner_id"}},
+		},
+		{
+			name: "no referenced row",
+			err:  &mysql.MySQLError{Number: 1452, Message: "Cannot add or update a child row: a foreign key constraint fails (`app`.`saved_views`, CONSTRAINT `fk_owner` FOREIGN KEY (`owner_id`) REFERENCES `users` (`id`))"},
+			want: wantConstraintError{code: apperror.ErrReferenceNotFound.Code, constraint: "fk_owner", columns: []string{"owner_id"}},
+		},
+		{
+			name: "column cannot be null",
+			err:  &mysql.MySQLError{Number: 1048, Message: "Column 'email' cannot be null"},
+			want: wantConstraintError{code: apperror.ErrRequiredField.Code, columns: []string{"email"}},
+		},
+		{
+			name: "data too long",
+			err:  &mysql.MySQLError{Number: 1406, Message: "Data too long for column 'username' at row 1"},
+			want: wantConstraintError{code: apperror.ErrConstraintViolation.Code, columns: []string{"username"}},
+		},
+		{
+			name: "check violated",
+			err:  &mysql.MySQLError{Number: 3819, Message: "Check constraint 'chk_users_status' is violated."},
+			want: wantConstraintError{code: apperror.ErrConstraintViolation.Code, constraint: "chk_users_status"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkConstraintError(t, tt.err, tt.deleting, tt.want)
+		})
+	}
+}
+
+func TestConstraintErrorSQLite(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:?_foreign_keys=1"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = sqlDB.Close() })
+
+	for _, stmt := range []string{
+		"CREATE TABLE owners (id INTEGER PRIMARY KEY, name TEXT NOT NULL UNIQUE, age INTEGER CONSTRAINT chk_age CHECK (age >= 0))",
+		"CREATE TABLE pets (id INTEGER PRIMARY KEY, owner_id INTEGER REFERENCES owners(id))",
+		"INSERT INTO owners (id, name, age) VALUES (1, 'bob', 1)",
+		"INSERT INTO pets (owner_id) VALUES (1)",
+	} {
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		stmt     string
+		deleting bool
+		want     wantConstraintError
+	}{
+		{
+			name: "unique",
+			stmt: "INSERT INTO owners (name) VALUES ('bob')",
+			want: wantConstraintError{code: apperror.ErrConflict.Code, columns: []string{"name"}},
+		},
+		{
+			name: "primary key",
+			stmt: "INSERT INTO owners (id, name) VALUES (1, 'alice')",
+			want: wantConstraintError{code: apperror.ErrConflict.Code, columns: []string{"id"}},
+		},
+		{
+			name: "missing reference",
+			stmt: "INSERT INTO pets (owner_id) VALUES (9)",
+			want: wantConstraintError{code: apperror.ErrReferenceNotFound.Code},
+		},
+		{
+			name:     "still referenced",
+			stmt:     "DELETE FROM owners WHERE id = 1",
+			deleting: true,
+			want:     wantConstraintError{code: apperror.ErrResourceInUse.Code},
+		},
+		{
+			name: "not null",
+			stmt: "INSERT INTO owners (name) VALUES (NULL)",
+			want: wantConstraintError{code: apperror.ErrRequiredField.Code, columns: []string{"name"}},
+		},
+		{
+			name: "check",
+			stmt: "INSERT INTO owners (name, age) VALUES ('carol', -1)",
+			want: wantConstraintError{code: apperror.ErrConstraintViolation.Code, constraint: "chk_age"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := db.Exec(tt.stmt).Error
+			if err == nil {
+				t.Fatalf("%s succeeded", tt.stmt)
+			}
+			checkConstraintError(t, err, tt.deleting, tt.want)
+		})
+	}
+}
+
+func TestConstraintErrorIgnoresOtherErrors(t *testing.T) {
+	for _, err := range []error{
+		errors.New("connection refused"),
+		gorm.ErrRecordNotFound,
+		&pgconn.PgError{Code: "40001"},
+		&mysql.MySQLError{Number: 1213, Message: "Deadlock found when trying to get lock"},
+	} {
+		if appErr := constraintError(err, "người dùng", false); appErr != nil {
+			t.Errorf("constraintError(%v) = %v, want nil", err, appErr)
+		}
+	}
+}
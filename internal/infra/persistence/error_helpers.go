@@ -20,47 +20,73 @@ func wrapFindError(err error, entityName string) error {
 }
 
 func wrapCreateError(err error, entityName string) error {
-	if isDuplicateKeyError(err) {
-		return apperror.ErrConflict.WithMessage(entityName + " đã tồn tại").WithError(err)
+	if appErr := constraintError(err, entityName, false); appErr != nil {
+		return appErr
 	}
 	return apperror.ErrInternalServerError.WithMessage("Không thể tạo " + entityName).WithError(err)
 }
 
 func wrapUpdateError(err error, entityName string) error {
-	if isDuplicateKeyError(err) {
-		return apperror.ErrConflict.WithMessage(entityName + " đã tồn tại").WithError(err)
+	if appErr := constraintError(err, entityName, false); appErr != nil {
+		return appErr
 	}
 	return apperror.ErrInternalServerError.WithMessage("Không thể cập nhật " + entityName).WithError(err)
 }
 
 func wrapDeleteError(err error, entityName string) error {
+	if appErr := constraintError(err, entityName, true); appErr != nil {
+		return appErr
+	}
 	return apperror.ErrInternalServerError.WithMessage("Không thể xóa " + entityName).WithError(err)
 }
 
 func wrapListError(err error, entityName string) error {
+	// Invalid cursors are reported by the query package as client errors
+	if appErr, ok := err.(*apperror.AppError); ok {
+		return appErr
+	}
 	return apperror.ErrInternalServerError.WithMessage("Không thể lấy danh sách " + entityName).WithError(err)
 }
 
-func isDuplicateKeyError(err error) bool {
-	if err == nil {
-		return false
+// constraintError maps a constraint failure of a write to the client error it stands
+// for, naming the constraint and the offending fields where the driver reports them.
+// A foreign key failure means a missing referenced row, unless the write is a delete,
+// where it means the row is still referenced. Other errors give nil.
+func constraintError(err error, entityName string, deleting bool) *apperror.AppError {
+	v, ok := asConstraintViolation(err)
+	if !ok {
+		return nil
 	}
-	errStr := err.Error()
-	return contains(errStr, "duplicate key") ||
-		contains(errStr, "Duplicate entry") ||
-		contains(errStr, "UNIQUE constraint failed") ||
-		contains(errStr, "violates unique constraint")
-}
 
-func contains(s, substr string) bool {
-	return len(s) >= len(substr) && (s == substr || len(s) > 0 && containsRune(s, substr))
-}
+	var base *apperror.AppError
+	var message, fieldMessage string
+	switch v.kind {
+	case violationUnique:
+		base, message, fieldMessage = apperror.ErrConflict, entityName+" đã tồn tại", "Giá trị đã tồn tại"
+	case violationForeignKey:
+		if deleting {
+			base, message, fieldMessage = apperror.ErrResourceInUse, entityName+" đang được tham chiếu ở nơi khác", "Đang được tham chiếu"
+		} else {
+			base, message, fieldMessage = apperror.ErrReferenceNotFound, "Dữ liệu được "+entityName+" tham chiếu không tồn tại", "Không tồn tại"
+		}
+	case violationNotNull:
+		base, message, fieldMessage = apperror.ErrRequiredField, "Thiếu dữ liệu bắt buộc của "+entityName, "Không được để trống"
+	case violationTooLong:
+		base, message, fieldMessage = apperror.ErrConstraintViolation, "Dữ liệu của "+entityName+" quá dài", "Giá trị quá dài"
+	default:
+		base, message, fieldMessage = apperror.ErrConstraintViolation, entityName+" vi phạm ràng buộc dữ liệu", "Giá trị không hợp lệ"
+	}
+	if v.constraint != "" {
+		message += " (ràng buộc " + v.constraint + ")"
+	}
 
-func containsRune(s, substr string) bool {
-	for i := 0; i <= len(s)-len(substr); i++ {
-		if s[i:i+len(substr)] == substr {
-			return true
+	appErr := base.WithMessage(message).WithError(err)
+	if len(v.columns) > 0 {
+		fields := make([]apperror.FieldError, len(v.columns))
+		for i, column := range v.columns {
+			fields[i] = apperror.FieldError{Field: column, Message: fieldMessage}
 		}
+		appErr = appErr.WithFields(fields)
 	}
-	return false
+	return appErr
 }
@@ -0,0 +1,70 @@
+package persistence
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/thienel/go-backend-template/internal/domain/entity"
+	"github.com/thienel/go-backend-template/internal/domain/repository"
+	apperror "github.com/thienel/go-backend-template/pkg/error"
+)
+
+// maxOutboxErrorLength matches the size of outbox_messages.last_error
+const maxOutboxErrorLength = 500
+
+type outboxRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewOutboxRepository creates a new outbox repository
+func NewOutboxRepository(db *gorm.DB) repository.OutboxRepository {
+	return &outboxRepositoryImpl{db: db}
+}
+
+func (r *outboxRepositoryImpl) Add(ctx context.Context, messages ...*entity.OutboxMessage) error {
+	if len(messages) == 0 {
+		return nil
+	}
+	if err := conn(ctx, r.db).Create(messages).Error; err != nil {
+		return wrapCreateError(err, "sự kiện")
+	}
+	return nil
+}
+
+func (r *outboxRepositoryImpl) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]entity.OutboxMessage, error) {
+	messages, err := claimDue[entity.OutboxMessage](ctx, r.db, entity.OutboxStatusPending, limit, lease)
+	if err != nil {
+		return nil, apperror.ErrInternalServerError.WithMessage("Không thể lấy sự kiện cần gửi").WithError(err)
+	}
+	return messages, nil
+}
+
+func (r *outboxRepositoryImpl) MarkDelivered(ctx context.Context, id uint) error {
+	if err := conn(ctx, r.db).Model(&entity.OutboxMessage{}).Where("id = ?", id).Updates(map[string]any{
+		"status":       entity.OutboxStatusDelivered,
+		"delivered_at": time.Now(),
+		"last_error":   "",
+	}).Error; err != nil {
+		return wrapUpdateError(err, "sự kiện")
+	}
+	return nil
+}
+
+func (r *outboxRepositoryImpl) MarkRetry(ctx context.Context, id uint, next *time.Time, lastError string) error {
+	if len(lastError) > maxOutboxErrorLength {
+		lastError = lastError[:maxOutboxErrorLength]
+	}
+
+	updates := map[string]any{"last_error": lastError}
+	if next != nil {
+		updates["next_attempt_at"] = *next
+	} else {
+		updates["status"] = entity.OutboxStatusFailed
+	}
+	if err := conn(ctx, r.db).Model(&entity.OutboxMessage{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+		return wrapUpdateError(err, "sự kiện")
+	}
+	return nil
+}
@@ -0,0 +1,22 @@
+package persistence
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/thienel/go-backend-template/internal/domain/entity"
+)
+
+// publicIDColumn holds the IDs that clients know entities by, for entities that have one
+const publicIDColumn = "public_id"
+
+// findIDByPublicID returns the ID of the entity of type T that clients know by publicID
+func findIDByPublicID[T any](db *gorm.DB, publicID entity.PublicID, entityName string) (uint, error) {
+	var ids []uint
+	if err := db.Model(new(T)).Where(publicIDColumn+" = ?", publicID).Limit(1).Pluck("id", &ids).Error; err != nil {
+		return 0, wrapFindError(err, entityName)
+	}
+	if len(ids) == 0 {
+		return 0, wrapFindError(gorm.ErrRecordNotFound, entityName)
+	}
+	return ids[0], nil
+}
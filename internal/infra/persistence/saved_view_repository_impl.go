@@ -0,0 +1,26 @@
+package persistence
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/thienel/go-backend-template/internal/domain/entity"
+	"github.com/thienel/go-backend-template/internal/domain/repository"
+	"github.com/thienel/go-backend-template/pkg/query"
+)
+
+type savedViewRepositoryImpl struct {
+	*BaseRepositoryImpl[entity.SavedView, uint]
+}
+
+// NewSavedViewRepository creates a new saved view repository
+func NewSavedViewRepository(db *gorm.DB) repository.SavedViewRepository {
+	base := NewBaseRepository[entity.SavedView, uint](db, repository.SavedViewQuerySchema, query.Projection{}, "bộ lọc đã lưu")
+	base.AuditEntity = "saved_view"
+	return &savedViewRepositoryImpl{BaseRepositoryImpl: base}
+}
+
+func (r *savedViewRepositoryImpl) FindIDByPublicID(ctx context.Context, publicID entity.PublicID) (uint, error) {
+	return findIDByPublicID[entity.SavedView](r.conn(ctx), publicID, r.EntityName)
+}
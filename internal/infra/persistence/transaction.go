@@ -0,0 +1,105 @@
+package persistence
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"github.com/thienel/tlog"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/thienel/go-backend-template/internal/domain/repository"
+)
+
+// Retries of transactions that lost a conflict with a concurrent one (see isRetryableTxError)
+const (
+	maxTxAttempts  = 3
+	txRetryBackoff = 20 * time.Millisecond
+)
+
+type txContextKey struct{}
+
+type commitHooksKey struct{}
+
+// commitHooks are the functions to run once the outermost transaction commits
+type commitHooks []func()
+
+// conn returns the transaction carried by ctx, or the repository's connection otherwise
+func conn(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txContextKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
+
+// inTransaction reports whether ctx carries a transaction
+func inTransaction(ctx context.Context) bool {
+	_, ok := ctx.Value(txContextKey{}).(*gorm.DB)
+	return ok
+}
+
+// afterCommit runs fn once the transaction carried by ctx commits, or right away if ctx
+// carries none. fn is dropped if the transaction rolls back, but runs even if only the
+// savepoint it was registered in rolled back, so it must be safe to run needlessly.
+func afterCommit(ctx context.Context, fn func()) {
+	hooks, ok := ctx.Value(commitHooksKey{}).(*commitHooks)
+	if !ok {
+		fn()
+		return
+	}
+	*hooks = append(*hooks, fn)
+}
+
+type txManagerImpl struct {
+	db *gorm.DB
+}
+
+// NewTxManager creates a transaction manager for repositories sharing db
+func NewTxManager(db *gorm.DB) repository.TxManager {
+	return &txManagerImpl{db: db}
+}
+
+func (m *txManagerImpl) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return runInTransaction(ctx, m.db, fn)
+}
+
+// runInTransaction runs fn in a transaction carried by the context passed to it.
+// If ctx already carries a transaction, fn runs in a savepoint of it.
+func runInTransaction(ctx context.Context, db *gorm.DB, fn func(ctx context.Context) error) error {
+	if tx, ok := ctx.Value(txContextKey{}).(*gorm.DB); ok {
+		// GORM turns a transaction started inside another into a savepoint
+		return tx.WithContext(ctx).Transaction(func(nested *gorm.DB) error {
+			return fn(context.WithValue(ctx, txContextKey{}, nested))
+		})
+	}
+
+	var err error
+	for attempt := 1; attempt <= maxTxAttempts; attempt++ {
+		// Each attempt starts afresh, so only the hooks of the one that commits run
+		hooks := &commitHooks{}
+		err = db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			txCtx := context.WithValue(ctx, txContextKey{}, tx)
+			return fn(context.WithValue(txCtx, commitHooksKey{}, hooks))
+		})
+		if err == nil {
+			for _, hook := range *hooks {
+				hook()
+			}
+			return nil
+		}
+		if !isRetryableTxError(err) || attempt == maxTxAttempts {
+			return err
+		}
+
+		backoff := time.Duration(attempt)*txRetryBackoff + time.Duration(rand.Int63n(int64(txRetryBackoff)))
+		tlog.Debug("Retrying transaction", zap.Int("attempt", attempt), zap.Duration("backoff", backoff), zap.Error(err))
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+	}
+	return err
+}
@@ -0,0 +1,188 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/mattn/go-sqlite3"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	// Every connection to :memory: is a database of its own
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	return db
+}
+
+func TestAfterCommit(t *testing.T) {
+	errRollback := errors.New("rollback")
+
+	tests := []struct {
+		name string
+		run  func(ctx context.Context, db *gorm.DB, hook func()) error
+		want int
+	}{
+		{
+			name: "without transaction",
+			run: func(ctx context.Context, _ *gorm.DB, hook func()) error {
+				afterCommit(ctx, hook)
+				return nil
+			},
+			want: 1,
+		},
+		{
+			name: "commit",
+			run: func(ctx context.Context, db *gorm.DB, hook func()) error {
+				return runInTransaction(ctx, db, func(ctx context.Context) error {
+					afterCommit(ctx, hook)
+					return nil
+				})
+			},
+			want: 1,
+		},
+		{
+			name: "rollback",
+			run: func(ctx context.Context, db *gorm.DB, hook func()) error {
+				return runInTransaction(ctx, db, func(ctx context.Context) error {
+					afterCommit(ctx, hook)
+					return errRollback
+				})
+			},
+			want: 0,
+		},
+		{
+			name: "savepoint of committed transaction",
+			run: func(ctx context.Context, db *gorm.DB, hook func()) error {
+				return runInTransaction(ctx, db, func(ctx context.Context) error {
+					return runInTransaction(ctx, db, func(ctx context.Context) error {
+						afterCommit(ctx, hook)
+						return nil
+					})
+				})
+			},
+			want: 1,
+		},
+		{
+			name: "savepoint of rolled back transaction",
+			run: func(ctx context.Context, db *gorm.DB, hook func()) error {
+				return runInTransaction(ctx, db, func(ctx context.Context) error {
+					if err := runInTransaction(ctx, db, func(ctx context.Context) error {
+						afterCommit(ctx, hook)
+						return nil
+					}); err != nil {
+						return err
+					}
+					return errRollback
+				})
+			},
+			want: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := openTestDB(t)
+			var runs int
+			err := tt.run(context.Background(), db, func() {
+				runs++
+			})
+			if err != nil && !errors.Is(err, errRollback) {
+				t.Fatalf("run: %v", err)
+			}
+			if runs != tt.want {
+				t.Errorf("hook ran %d times, want %d", runs, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsRetryableTxError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "serialization failure", err: &pgconn.PgError{Code: "40001"}, want: true},
+		{name: "deadlock", err: &pgconn.PgError{Code: "40P01"}, want: true},
+		{name: "wrapped", err: fmt.Errorf("update user: %w", &pgconn.PgError{Code: "40001"}), want: true},
+		{name: "unique violation", err: &pgconn.PgError{Code: "23505"}},
+		{name: "lock timeout", err: &pgconn.PgError{Code: "55P03"}},
+		{name: "MySQL deadlock", err: &mysql.MySQLError{Number: 1213}, want: true},
+		{name: "MySQL lock wait timeout", err: &mysql.MySQLError{Number: 1205}, want: true},
+		{name: "MySQL duplicate entry", err: &mysql.MySQLError{Number: 1062}},
+		{name: "SQLite busy", err: sqlite3.Error{Code: sqlite3.ErrBusy, ExtendedCode: sqlite3.ErrBusySnapshot}, want: true},
+		{name: "SQLite locked", err: sqlite3.Error{Code: sqlite3.ErrLocked}, want: true},
+		{name: "SQLite constraint", err: sqlite3.Error{Code: sqlite3.ErrConstraint, ExtendedCode: sqlite3.ErrConstraintUnique}},
+		{name: "not a database error", err: errors.New("40001")},
+		{name: "nil", err: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isRetryableTxError(tt.err); got != tt.want {
+				t.Errorf("isRetryableTxError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunInTransactionRetries(t *testing.T) {
+	conflict := &pgconn.PgError{Code: sqlStateSerializationFailure}
+
+	tests := []struct {
+		name         string
+		failures     int
+		err          error
+		wantAttempts int
+		wantErr      bool
+	}{
+		{name: "succeeds after conflicts", failures: maxTxAttempts - 1, err: conflict, wantAttempts: maxTxAttempts},
+		{name: "gives up after the last attempt", failures: maxTxAttempts, err: conflict, wantAttempts: maxTxAttempts, wantErr: true},
+		{name: "other errors are not retried", failures: 1, err: errors.New("boom"), wantAttempts: 1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := openTestDB(t)
+			var attempts, hooks int
+			err := runInTransaction(context.Background(), db, func(ctx context.Context) error {
+				attempts++
+				afterCommit(ctx, func() { hooks++ })
+				if attempts <= tt.failures {
+					return tt.err
+				}
+				return nil
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+			if attempts != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d", attempts, tt.wantAttempts)
+			}
+			// Only the attempt that commits runs its hooks
+			wantHooks := 1
+			if tt.wantErr {
+				wantHooks = 0
+			}
+			if hooks != wantHooks {
+				t.Errorf("hooks ran %d times, want %d", hooks, wantHooks)
+			}
+		})
+	}
+}
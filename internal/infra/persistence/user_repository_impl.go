@@ -2,8 +2,10 @@ package persistence
 
 import (
 	"context"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"github.com/thienel/go-backend-template/internal/domain/entity"
 	"github.com/thienel/go-backend-template/internal/domain/repository"
@@ -11,30 +13,45 @@ import (
 	"github.com/thienel/go-backend-template/pkg/query"
 )
 
-// userAllowedFields defines filterable/sortable fields for security
-var userAllowedFields = map[string]bool{
-	"id":         true,
-	"username":   true,
-	"email":      true,
-	"role":       true,
-	"status":     true,
-	"created_at": true,
-	"updated_at": true,
+// userSearch matches the search filter against username and email. The search column
+// and indexes are created by migration 0002_user_search, the trigram indexes by the
+// opt-in migration 0010_user_search_trigram.
+var userSearch = query.SearchConfig{
+	Table:        "users",
+	Language:     "simple",
+	VectorColumn: "search_vector",
+	Fields: []query.SearchField{
+		{Column: "username", Weight: "A"},
+		{Column: "email", Weight: "B"},
+	},
 }
 
+// userTrigramColumns are matched by trigram similarity when trigram search is enabled
+var userTrigramColumns = []string{"username", "email"}
+
+// purgeBatchSize is the number of users PurgeDeletedBefore purges per transaction
+const purgeBatchSize = 500
+
 type userRepositoryImpl struct {
-	*BaseRepositoryImpl[entity.User]
+	*BaseRepositoryImpl[entity.User, uint]
 }
 
-// NewUserRepository creates a new user repository
-func NewUserRepository(db *gorm.DB) repository.UserRepository {
-	base := NewBaseRepository[entity.User](db, userAllowedFields, "người dùng")
+// NewUserRepository creates a new user repository. trigramSearch also matches search
+// terms by trigram similarity, which needs the pg_trgm extension.
+func NewUserRepository(db *gorm.DB, trigramSearch bool) repository.UserRepository {
+	base := NewBaseRepository[entity.User, uint](db, repository.UserQuerySchema, repository.UserProjection, "người dùng")
+	search := userSearch
+	if trigramSearch {
+		search.TrigramColumns = userTrigramColumns
+	}
+	base.Search = &search
+	base.AuditEntity = entity.AuditEntityUser
 	return &userRepositoryImpl{BaseRepositoryImpl: base}
 }
 
 func (r *userRepositoryImpl) FindByUsername(ctx context.Context, username string) (*entity.User, error) {
 	var user entity.User
-	if err := r.DB.WithContext(ctx).Where("username = ?", username).First(&user).Error; err != nil {
+	if err := r.conn(ctx).Where("username = ?", username).First(&user).Error; err != nil {
 		return nil, wrapFindError(err, "người dùng")
 	}
 	return &user, nil
@@ -42,7 +59,7 @@ func (r *userRepositoryImpl) FindByUsername(ctx context.Context, username string
 
 func (r *userRepositoryImpl) FindByEmail(ctx context.Context, email string) (*entity.User, error) {
 	var user entity.User
-	if err := r.DB.WithContext(ctx).Where("email = ?", email).First(&user).Error; err != nil {
+	if err := r.conn(ctx).Where("email = ?", email).First(&user).Error; err != nil {
 		return nil, wrapFindError(err, "người dùng")
 	}
 	return &user, nil
@@ -50,7 +67,7 @@ func (r *userRepositoryImpl) FindByEmail(ctx context.Context, email string) (*en
 
 func (r *userRepositoryImpl) FindByUsernameIncludingDeleted(ctx context.Context, username string) (*entity.User, error) {
 	var user entity.User
-	if err := r.DB.WithContext(ctx).Unscoped().Where("username = ?", username).First(&user).Error; err != nil {
+	if err := r.conn(ctx).Unscoped().Where("username = ?", username).First(&user).Error; err != nil {
 		return nil, wrapFindError(err, "người dùng")
 	}
 	return &user, nil
@@ -58,47 +75,149 @@ func (r *userRepositoryImpl) FindByUsernameIncludingDeleted(ctx context.Context,
 
 func (r *userRepositoryImpl) FindByEmailIncludingDeleted(ctx context.Context, email string) (*entity.User, error) {
 	var user entity.User
-	if err := r.DB.WithContext(ctx).Unscoped().Where("email = ?", email).First(&user).Error; err != nil {
+	if err := r.conn(ctx).Unscoped().Where("email = ?", email).First(&user).Error; err != nil {
 		return nil, wrapFindError(err, "người dùng")
 	}
 	return &user, nil
 }
 
-func (r *userRepositoryImpl) Restore(ctx context.Context, id uint) error {
-	if err := r.DB.WithContext(ctx).Unscoped().Model(&entity.User{}).Where("id = ?", id).Update("deleted_at", nil).Error; err != nil {
-		return apperror.ErrInternalServerError.WithMessage("Không thể khôi phục người dùng").WithError(err)
+func (r *userRepositoryImpl) FindByIDIncludingDeleted(ctx context.Context, id uint) (*entity.User, error) {
+	var user entity.User
+	if err := r.conn(ctx).Unscoped().First(&user, id).Error; err != nil {
+		return nil, wrapFindError(err, "người dùng")
 	}
-	return nil
+	return &user, nil
+}
+
+func (r *userRepositoryImpl) FindIDByPublicID(ctx context.Context, publicID entity.PublicID) (uint, error) {
+	return findIDByPublicID[entity.User](r.conn(ctx).Unscoped(), publicID, "người dùng")
 }
 
-func (r *userRepositoryImpl) ListWithQuery(ctx context.Context, offset, limit int, opts query.QueryOptions) ([]entity.User, int64, error) {
-	var users []entity.User
-	var total int64
+func (r *userRepositoryImpl) FindPublicIDs(ctx context.Context, ids []uint) (map[uint]entity.PublicID, error) {
+	publicIDs := make(map[uint]entity.PublicID, len(ids))
+	if len(ids) == 0 {
+		return publicIDs, nil
+	}
+
+	var rows []struct {
+		ID       uint
+		PublicID entity.PublicID
+	}
+	if err := r.conn(ctx).Unscoped().Model(&entity.User{}).
+		Select("id", "public_id").Where("id IN ?", ids).Find(&rows).Error; err != nil {
+		return nil, wrapListError(err, "người dùng")
+	}
+	for _, row := range rows {
+		publicIDs[row.ID] = row.PublicID
+	}
+	return publicIDs, nil
+}
 
-	q := r.DB.WithContext(ctx).Model(&entity.User{})
+func (r *userRepositoryImpl) Restore(ctx context.Context, id uint) error {
+	return r.audited(ctx, func(ctx context.Context) error {
+		before, err := r.findUnscoped(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if err := r.conn(ctx).Unscoped().Model(&entity.User{}).Where("id = ?", id).Updates(map[string]any{
+			"deleted_at": nil,
+			"version":    gorm.Expr("version + 1"),
+		}).Error; err != nil {
+			return apperror.ErrInternalServerError.WithMessage("Không thể khôi phục người dùng").WithError(err)
+		}
+
+		after, err := r.findUnscoped(ctx, id)
+		if err != nil {
+			return err
+		}
+		return r.audit(ctx, auditRestore, before, after)
+	})
+}
 
-	// Handle special 'search' filter
-	if searchFilter, ok := opts.Filters["search"]; ok {
-		searchValue := searchFilter.Value.(string)
-		q = q.Where("username ILIKE ? OR email ILIKE ?", "%"+searchValue+"%", "%"+searchValue+"%")
-		delete(opts.Filters, "search")
+func (r *userRepositoryImpl) UpdateLastLogin(ctx context.Context, id uint, at time.Time, ip string) error {
+	if err := r.conn(ctx).Model(&entity.User{}).Where("id = ?", id).Updates(map[string]any{
+		"last_login_at": at,
+		"last_login_ip": ip,
+	}).Error; err != nil {
+		return wrapUpdateError(err, "người dùng")
 	}
+	return nil
+}
 
-	// Apply filters
-	q = q.Scopes(query.ApplyFilters(opts, r.AllowedFields))
+func (r *userRepositoryImpl) Purge(ctx context.Context, id uint, version uint) error {
+	return r.audited(ctx, func(ctx context.Context) error {
+		before, err := r.findUnscoped(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		result := r.conn(ctx).Unscoped().Where(versionColumn+" = ?", version).Delete(&entity.User{}, id)
+		if result.Error != nil {
+			return apperror.ErrInternalServerError.WithMessage("Không thể xóa vĩnh viễn người dùng").WithError(result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return apperror.ErrVersionConflict
+		}
+		return r.audit(ctx, auditPurge, before, nil)
+	})
+}
 
-	// Count total
-	if err := q.Count(&total).Error; err != nil {
-		return nil, 0, wrapListError(err, "người dùng")
+// findUnscoped finds a user whether or not it is soft-deleted
+func (r *userRepositoryImpl) findUnscoped(ctx context.Context, id uint) (*entity.User, error) {
+	var user entity.User
+	if err := r.conn(ctx).Unscoped().First(&user, id).Error; err != nil {
+		return nil, wrapFindError(err, "người dùng")
 	}
+	return &user, nil
+}
 
-	// Apply sort and pagination
-	if err := q.Scopes(
-		query.ApplySort(opts, r.AllowedFields),
-		query.ApplyDefaultSort(opts, "created_at", true),
-	).Offset(offset).Limit(limit).Find(&users).Error; err != nil {
-		return nil, 0, wrapListError(err, "người dùng")
+// PurgeDeletedBefore purges the users in batches, each in its own transaction, so that
+// every purged user is recorded in the audit log like a single Purge
+func (r *userRepositoryImpl) PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error) {
+	var purged int64
+	for {
+		var batch int
+		err := r.audited(ctx, func(ctx context.Context) error {
+			var users []entity.User
+			if err := r.conn(ctx).Unscoped().
+				Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+				Order("id").Limit(purgeBatchSize).
+				// A user restored meanwhile must not be purged
+				Clauses(clause.Locking{Strength: "UPDATE"}).
+				Find(&users).Error; err != nil {
+				return err
+			}
+			if len(users) == 0 {
+				return nil
+			}
+
+			ids := make([]uint, len(users))
+			for i := range users {
+				ids[i] = users[i].ID
+			}
+			if err := r.conn(ctx).Unscoped().Where("id IN ?", ids).Delete(&entity.User{}).Error; err != nil {
+				return err
+			}
+			for i := range users {
+				if err := r.audit(ctx, auditPurge, &users[i], nil); err != nil {
+					return err
+				}
+			}
+			batch = len(users)
+			return nil
+		})
+		if err != nil {
+			return purged, apperror.ErrInternalServerError.WithMessage("Không thể xóa vĩnh viễn người dùng").WithError(err)
+		}
+
+		purged += int64(batch)
+		if batch < purgeBatchSize {
+			return purged, nil
+		}
 	}
+}
 
-	return users, total, nil
+func (r *userRepositoryImpl) ListWithQuery(ctx context.Context, page query.Pagination, opts query.QueryOptions) (query.Page[entity.User], error) {
+	return r.List(ctx, page, opts)
 }
@@ -0,0 +1,69 @@
+package persistence
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/thienel/go-backend-template/internal/domain/entity"
+)
+
+func TestUpdateKeepsLastLogin(t *testing.T) {
+	db := openTestDB(t)
+	if err := db.AutoMigrate(&entity.User{}, &entity.AuditLog{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	repo := NewUserRepository(db, false)
+	ctx := context.Background()
+
+	user := &entity.User{Username: "alice", Email: "alice@example.com", Password: "x"}
+	if err := repo.Create(ctx, user); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	// Read before the login, saved after it
+	stale, err := repo.FindByID(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	loginAt := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	if err := repo.UpdateLastLogin(ctx, user.ID, loginAt, "203.0.113.7"); err != nil {
+		t.Fatalf("update last login: %v", err)
+	}
+
+	stale.Email = "alice@example.org"
+	if err := repo.Update(ctx, stale); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if stale.LastLoginIP != "203.0.113.7" {
+		t.Errorf("updated entity has last login IP %q, want the stored one", stale.LastLoginIP)
+	}
+
+	got, err := repo.FindByID(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if got.Email != "alice@example.org" {
+		t.Errorf("email = %q, want the updated one", got.Email)
+	}
+	if got.LastLoginAt == nil || !got.LastLoginAt.Equal(loginAt) || got.LastLoginIP != "203.0.113.7" {
+		t.Errorf("last login = %v from %q, want %v from 203.0.113.7", got.LastLoginAt, got.LastLoginIP, loginAt)
+	}
+	if got.Version != user.Version+1 {
+		t.Errorf("version = %d, want %d", got.Version, user.Version+1)
+	}
+
+	var logs []entity.AuditLog
+	if err := db.Where("action = ?", entity.AuditActionUpdate).Find(&logs).Error; err != nil {
+		t.Fatalf("list audit logs: %v", err)
+	}
+	if len(logs) != 1 {
+		t.Fatalf("%d update audit logs, want 1", len(logs))
+	}
+	if logs[0].EntityID != user.PublicID.String() {
+		t.Errorf("audit log entity ID = %q, want the public ID %s", logs[0].EntityID, user.PublicID)
+	}
+	if _, ok := logs[0].Changes["last_login_at"]; ok {
+		t.Errorf("audit log records a last login change that was not written: %v", logs[0].ChangedFields)
+	}
+}
@@ -0,0 +1,19 @@
+package persistence
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/thienel/go-backend-template/internal/domain/entity"
+	"github.com/thienel/go-backend-template/internal/domain/repository"
+	"github.com/thienel/go-backend-template/pkg/query"
+)
+
+type userStatusHistoryRepositoryImpl struct {
+	*BaseRepositoryImpl[entity.UserStatusHistory, uint]
+}
+
+// NewUserStatusHistoryRepository creates a new user status history repository
+func NewUserStatusHistoryRepository(db *gorm.DB) repository.UserStatusHistoryRepository {
+	base := NewBaseRepository[entity.UserStatusHistory, uint](db, repository.UserStatusHistoryQuerySchema, query.Projection{}, "lịch sử trạng thái")
+	return &userStatusHistoryRepositoryImpl{BaseRepositoryImpl: base}
+}
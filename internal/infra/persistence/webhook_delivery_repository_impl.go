@@ -0,0 +1,46 @@
+package persistence
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/thienel/go-backend-template/internal/domain/entity"
+	"github.com/thienel/go-backend-template/internal/domain/repository"
+	apperror "github.com/thienel/go-backend-template/pkg/error"
+	"github.com/thienel/go-backend-template/pkg/query"
+)
+
+type webhookDeliveryRepositoryImpl struct {
+	*BaseRepositoryImpl[entity.WebhookDelivery, uint]
+}
+
+// NewWebhookDeliveryRepository creates a new webhook delivery repository
+func NewWebhookDeliveryRepository(db *gorm.DB) repository.WebhookDeliveryRepository {
+	base := NewBaseRepository[entity.WebhookDelivery, uint](db, repository.WebhookDeliveryQuerySchema, query.Projection{}, "lượt gửi webhook")
+	return &webhookDeliveryRepositoryImpl{BaseRepositoryImpl: base}
+}
+
+func (r *webhookDeliveryRepositoryImpl) FindIDByPublicID(ctx context.Context, publicID entity.PublicID) (uint, error) {
+	return findIDByPublicID[entity.WebhookDelivery](r.conn(ctx), publicID, r.EntityName)
+}
+
+func (r *webhookDeliveryRepositoryImpl) Enqueue(ctx context.Context, deliveries ...*entity.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	if err := r.conn(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(deliveries).Error; err != nil {
+		return wrapCreateError(err, r.EntityName)
+	}
+	return nil
+}
+
+func (r *webhookDeliveryRepositoryImpl) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]entity.WebhookDelivery, error) {
+	deliveries, err := claimDue[entity.WebhookDelivery](ctx, r.DB, entity.WebhookDeliveryPending, limit, lease)
+	if err != nil {
+		return nil, apperror.ErrInternalServerError.WithMessage("Không thể lấy webhook cần gửi").WithError(err)
+	}
+	return deliveries, nil
+}
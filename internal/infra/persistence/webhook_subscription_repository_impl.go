@@ -0,0 +1,43 @@
+package persistence
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/thienel/go-backend-template/internal/domain/entity"
+	"github.com/thienel/go-backend-template/internal/domain/repository"
+	"github.com/thienel/go-backend-template/pkg/query"
+)
+
+type webhookSubscriptionRepositoryImpl struct {
+	*BaseRepositoryImpl[entity.WebhookSubscription, uint]
+}
+
+// NewWebhookSubscriptionRepository creates a new webhook subscription repository
+func NewWebhookSubscriptionRepository(db *gorm.DB) repository.WebhookSubscriptionRepository {
+	base := NewBaseRepository[entity.WebhookSubscription, uint](db, repository.WebhookSubscriptionQuerySchema, query.Projection{}, "webhook")
+	base.AuditEntity = "webhook_subscription"
+	return &webhookSubscriptionRepositoryImpl{BaseRepositoryImpl: base}
+}
+
+func (r *webhookSubscriptionRepositoryImpl) FindIDByPublicID(ctx context.Context, publicID entity.PublicID) (uint, error) {
+	return findIDByPublicID[entity.WebhookSubscription](r.conn(ctx), publicID, r.EntityName)
+}
+
+// FindActiveByEvent matches the event types in Go rather than with a JSON containment
+// query, which differs between databases; there are few subscriptions.
+func (r *webhookSubscriptionRepositoryImpl) FindActiveByEvent(ctx context.Context, eventName string) ([]entity.WebhookSubscription, error) {
+	var active []entity.WebhookSubscription
+	if err := r.conn(ctx).Where("active = ?", true).Order("id").Find(&active).Error; err != nil {
+		return nil, wrapFindError(err, "webhook")
+	}
+
+	subscriptions := active[:0]
+	for _, subscription := range active {
+		if subscription.Subscribes(eventName) {
+			subscriptions = append(subscriptions, subscription)
+		}
+	}
+	return subscriptions, nil
+}
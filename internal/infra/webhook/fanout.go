@@ -0,0 +1,72 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/thienel/go-backend-template/internal/domain/entity"
+	"github.com/thienel/go-backend-template/internal/domain/event"
+	"github.com/thienel/go-backend-template/internal/domain/repository"
+)
+
+// body is the JSON request body of a webhook; ID is the outbox message ID, the same for
+// every subscription, so receivers can recognize an event sent twice
+type body struct {
+	ID         uint            `json:"id"`
+	Event      string          `json:"event"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Data       json.RawMessage `json:"data"`
+}
+
+type fanout struct {
+	subscriptionRepo repository.WebhookSubscriptionRepository
+	deliveryRepo     repository.WebhookDeliveryRepository
+}
+
+// NewFanout creates an outbox target that queues a delivery of each event for every
+// active subscription to it. The worker sends the deliveries.
+func NewFanout(subscriptionRepo repository.WebhookSubscriptionRepository, deliveryRepo repository.WebhookDeliveryRepository) event.Publisher {
+	return &fanout{subscriptionRepo: subscriptionRepo, deliveryRepo: deliveryRepo}
+}
+
+func (f *fanout) Publish(ctx context.Context, events ...event.Event) error {
+	now := time.Now()
+	for _, evt := range events {
+		// Deliveries are keyed by outbox message, so only outbox events can be sent
+		msg, ok := evt.(event.Message)
+		if !ok {
+			return fmt.Errorf("webhook: %s is not an outbox message", evt.EventName())
+		}
+
+		subscriptions, err := f.subscriptionRepo.FindActiveByEvent(ctx, msg.Name)
+		if err != nil {
+			return err
+		}
+		if len(subscriptions) == 0 {
+			continue
+		}
+
+		payload, err := json.Marshal(body{ID: msg.ID, Event: msg.Name, OccurredAt: msg.Time, Data: msg.Payload})
+		if err != nil {
+			return err
+		}
+
+		deliveries := make([]*entity.WebhookDelivery, len(subscriptions))
+		for i, subscription := range subscriptions {
+			deliveries[i] = &entity.WebhookDelivery{
+				SubscriptionID: subscription.ID,
+				MessageID:      msg.ID,
+				EventName:      msg.Name,
+				Payload:        payload,
+				Status:         entity.WebhookDeliveryPending,
+				NextAttemptAt:  now,
+			}
+		}
+		if err := f.deliveryRepo.Enqueue(ctx, deliveries...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
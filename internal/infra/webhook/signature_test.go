@@ -0,0 +1,47 @@
+package webhook
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignVerify(t *testing.T) {
+	const secret = "whsec_test"
+	body := []byte(`{"event":"user.created"}`)
+	signedAt := time.Unix(1_700_000_000, 0)
+	header := Sign(secret, signedAt, body)
+
+	if !strings.HasPrefix(header, "t=1700000000,v1=") {
+		t.Fatalf("Sign() = %q, want t=1700000000,v1=<hex>", header)
+	}
+
+	tests := []struct {
+		name   string
+		secret string
+		header string
+		body   []byte
+		now    time.Time
+		want   bool
+	}{
+		{"valid", secret, header, body, signedAt, true},
+		{"within tolerance after", secret, header, body, signedAt.Add(5 * time.Minute), true},
+		{"within tolerance before", secret, header, body, signedAt.Add(-5 * time.Minute), true},
+		{"too old", secret, header, body, signedAt.Add(5*time.Minute + time.Second), false},
+		{"too far in the future", secret, header, body, signedAt.Add(-5*time.Minute - time.Second), false},
+		{"other secret", "whsec_other", header, body, signedAt, false},
+		{"tampered body", secret, header, []byte(`{"event":"user.deleted"}`), signedAt, false},
+		{"tampered timestamp", secret, strings.Replace(header, "t=1700000000", "t=1700000001", 1), body, signedAt, false},
+		{"missing timestamp", secret, header[strings.Index(header, "v1="):], body, signedAt, false},
+		{"missing signature", secret, "t=1700000000", body, signedAt, false},
+		{"signature not hex", secret, "t=1700000000,v1=zz", body, signedAt, false},
+		{"empty", secret, "", body, signedAt, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Verify(tt.secret, tt.header, tt.body, 5*time.Minute, tt.now); got != tt.want {
+				t.Errorf("Verify() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,255 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/thienel/tlog"
+	"go.uber.org/zap"
+
+	"github.com/thienel/go-backend-template/internal/domain/entity"
+	"github.com/thienel/go-backend-template/internal/domain/repository"
+	"github.com/thienel/go-backend-template/pkg/backoff"
+	"github.com/thienel/go-backend-template/pkg/config"
+	apperror "github.com/thienel/go-backend-template/pkg/error"
+)
+
+// Delays between delivery attempts, doubling from minRetryDelay
+const (
+	minRetryDelay = 10 * time.Second
+	maxRetryDelay = 6 * time.Hour
+)
+
+// Sizes of the delivery columns that keep the outcome of an attempt
+const (
+	maxResponseBodyLength = 1000
+	maxErrorLength        = 500
+)
+
+// Worker sends queued webhook deliveries in the background. A delivery succeeds on a 2xx
+// response and is otherwise retried with exponential backoff until it runs out of
+// attempts and is dead.
+type Worker struct {
+	subscriptionRepo repository.WebhookSubscriptionRepository
+	deliveryRepo     repository.WebhookDeliveryRepository
+	client           *http.Client
+	interval         time.Duration
+	batchSize        int
+	maxAttempts      int
+	lease            time.Duration
+	cancel           context.CancelFunc
+	done             chan struct{}
+}
+
+// NewWorker creates a webhook delivery worker sending requests with client
+func NewWorker(
+	subscriptionRepo repository.WebhookSubscriptionRepository,
+	deliveryRepo repository.WebhookDeliveryRepository,
+	client *http.Client,
+	cfg config.WebhookConfig,
+) *Worker {
+	interval := time.Duration(cfg.PollIntervalMs) * time.Millisecond
+	if interval <= 0 {
+		interval = time.Second
+	}
+	batchSize := cfg.BatchSize
+	if batchSize <= 0 {
+		batchSize = 20
+	}
+	maxAttempts := cfg.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = 8
+	}
+	lease := time.Duration(cfg.LeaseSeconds) * time.Second
+	if lease <= 0 {
+		lease = 5 * time.Minute
+	}
+
+	return &Worker{
+		subscriptionRepo: subscriptionRepo,
+		deliveryRepo:     deliveryRepo,
+		client:           client,
+		interval:         interval,
+		batchSize:        batchSize,
+		maxAttempts:      maxAttempts,
+		lease:            lease,
+	}
+}
+
+// Start runs the worker in the background until Stop is called
+func (w *Worker) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	w.cancel = cancel
+	w.done = make(chan struct{})
+
+	go func() {
+		defer close(w.done)
+
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+
+		w.run(ctx)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				w.run(ctx)
+			}
+		}
+	}()
+
+	tlog.Info("Webhook worker started",
+		zap.Duration("interval", w.interval),
+		zap.Int("batch_size", w.batchSize),
+		zap.Int("max_attempts", w.maxAttempts),
+	)
+}
+
+// Stop stops the worker and waits for the current batch to finish
+func (w *Worker) Stop() {
+	if w.cancel == nil {
+		return
+	}
+	w.cancel()
+	<-w.done
+}
+
+// run sends due deliveries until a batch comes back short
+func (w *Worker) run(ctx context.Context) {
+	for ctx.Err() == nil {
+		deliveries, err := w.deliveryRepo.ClaimDue(ctx, w.batchSize, w.lease)
+		if err != nil {
+			tlog.Error("Webhook delivery failed", zap.Error(err))
+			return
+		}
+
+		subscriptions := make(map[uint]*entity.WebhookSubscription)
+		for i := range deliveries {
+			w.deliver(ctx, &deliveries[i], subscriptions)
+		}
+		if len(deliveries) < w.batchSize {
+			return
+		}
+	}
+}
+
+// deliver sends a delivery and records the outcome. subscriptions caches the
+// subscriptions loaded for the batch.
+func (w *Worker) deliver(ctx context.Context, delivery *entity.WebhookDelivery, subscriptions map[uint]*entity.WebhookSubscription) {
+	subscription, ok := subscriptions[delivery.SubscriptionID]
+	if !ok {
+		var err error
+		subscription, err = w.subscriptionRepo.FindByID(ctx, delivery.SubscriptionID)
+		var appErr *apperror.AppError
+		if err != nil && !(errors.As(err, &appErr) && appErr.Code == apperror.ErrNotFound.Code) {
+			// The lease expires and the delivery is claimed again
+			tlog.Error("Failed to load webhook subscription", zap.Uint("subscription_id", delivery.SubscriptionID), zap.Error(err))
+			return
+		}
+		subscriptions[delivery.SubscriptionID] = subscription
+	}
+
+	switch {
+	case subscription == nil:
+		w.fail(ctx, delivery, "subscription deleted", true)
+		return
+	case !subscription.Active:
+		w.fail(ctx, delivery, "subscription inactive", true)
+		return
+	}
+
+	code, respBody, duration, err := w.send(ctx, subscription, delivery)
+	delivery.DurationMs = duration.Milliseconds()
+	delivery.ResponseCode = code
+	delivery.ResponseBody = respBody
+
+	switch {
+	case err != nil:
+		w.fail(ctx, delivery, err.Error(), false)
+	case *code < 200 || *code > 299:
+		w.fail(ctx, delivery, fmt.Sprintf("unexpected status %d", *code), false)
+	default:
+		now := time.Now()
+		delivery.Status = entity.WebhookDeliveryDelivered
+		delivery.DeliveredAt = &now
+		delivery.LastError = ""
+		w.save(ctx, delivery)
+	}
+}
+
+// send posts a delivery to its subscription; code is nil if no response was received
+func (w *Worker) send(ctx context.Context, subscription *entity.WebhookSubscription, delivery *entity.WebhookDelivery) (code *int, respBody string, duration time.Duration, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return nil, "", 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, Sign(subscription.Secret, time.Now(), delivery.Payload))
+	req.Header.Set(EventHeader, delivery.EventName)
+	req.Header.Set(DeliveryHeader, delivery.PublicID.String())
+
+	started := time.Now()
+	resp, err := w.client.Do(req)
+	duration = time.Since(started)
+	if err != nil {
+		return nil, "", duration, err
+	}
+	defer resp.Body.Close()
+
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBodyLength))
+	return &resp.StatusCode, truncate(string(data), maxResponseBodyLength), duration, nil
+}
+
+// fail schedules another attempt of a delivery, or marks it dead if it ran out of
+// attempts or cannot succeed
+func (w *Worker) fail(ctx context.Context, delivery *entity.WebhookDelivery, reason string, permanent bool) {
+	reason = truncate(reason, maxErrorLength)
+	delivery.LastError = reason
+
+	if permanent || delivery.Attempts >= w.maxAttempts {
+		delivery.Status = entity.WebhookDeliveryDead
+		tlog.Warn("Webhook delivery dead",
+			zap.Uint("delivery_id", delivery.ID),
+			zap.Uint("subscription_id", delivery.SubscriptionID),
+			zap.Int("attempts", delivery.Attempts),
+			zap.String("error", reason),
+		)
+	} else {
+		delivery.NextAttemptAt = time.Now().Add(backoff.Exponential(delivery.Attempts, minRetryDelay, maxRetryDelay))
+		tlog.Debug("Webhook delivery failed",
+			zap.Uint("delivery_id", delivery.ID),
+			zap.Uint("subscription_id", delivery.SubscriptionID),
+			zap.Int("attempts", delivery.Attempts),
+			zap.Time("next_attempt_at", delivery.NextAttemptAt),
+			zap.String("error", reason),
+		)
+	}
+	w.save(ctx, delivery)
+}
+
+func (w *Worker) save(ctx context.Context, delivery *entity.WebhookDelivery) {
+	if err := w.deliveryRepo.Update(ctx, delivery); err != nil {
+		tlog.Error("Failed to save webhook delivery", zap.Uint("delivery_id", delivery.ID), zap.Error(err))
+	}
+}
+
+// truncate cuts s to at most n bytes that a text column accepts: invalid UTF-8 and NUL
+// bytes, which a receiver may well send back, are dropped, and no rune is split
+func truncate(s string, n int) string {
+	s = strings.ReplaceAll(strings.ToValidUTF8(s, ""), "\x00", "")
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
@@ -0,0 +1,276 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/thienel/go-backend-template/internal/domain/entity"
+	"github.com/thienel/go-backend-template/internal/domain/repository"
+	"github.com/thienel/go-backend-template/pkg/config"
+	apperror "github.com/thienel/go-backend-template/pkg/error"
+)
+
+// fakeSubscriptionRepository serves subscriptions from a map; other methods are not used
+type fakeSubscriptionRepository struct {
+	repository.WebhookSubscriptionRepository
+	subscriptions map[uint]*entity.WebhookSubscription
+}
+
+func (r *fakeSubscriptionRepository) FindByID(_ context.Context, id uint) (*entity.WebhookSubscription, error) {
+	subscription, ok := r.subscriptions[id]
+	if !ok {
+		return nil, apperror.ErrNotFound
+	}
+	return subscription, nil
+}
+
+// fakeDeliveryRepository records the deliveries saved by the worker
+type fakeDeliveryRepository struct {
+	repository.WebhookDeliveryRepository
+	mu    sync.Mutex
+	saved []entity.WebhookDelivery
+}
+
+func (r *fakeDeliveryRepository) Update(_ context.Context, delivery *entity.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.saved = append(r.saved, *delivery)
+	return nil
+}
+
+// request is what the receiver got
+type request struct {
+	signature, event, delivery string
+	body                       []byte
+}
+
+// newReceiver starts a server answering every request with status and body
+func newReceiver(t *testing.T, status int, body string) (*httptest.Server, <-chan request) {
+	t.Helper()
+	requests := make(chan request, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		requests <- request{
+			signature: r.Header.Get(SignatureHeader),
+			event:     r.Header.Get(EventHeader),
+			delivery:  r.Header.Get(DeliveryHeader),
+			body:      data,
+		}
+		w.WriteHeader(status)
+		_, _ = io.WriteString(w, body)
+	}))
+	t.Cleanup(server.Close)
+	return server, requests
+}
+
+func newTestWorker(subscriptions map[uint]*entity.WebhookSubscription) (*Worker, *fakeDeliveryRepository) {
+	deliveries := &fakeDeliveryRepository{}
+	worker := NewWorker(
+		&fakeSubscriptionRepository{subscriptions: subscriptions},
+		deliveries,
+		NewClient(5*time.Second, true),
+		config.WebhookConfig{MaxAttempts: 3},
+	)
+	return worker, deliveries
+}
+
+// testDeliveryID is the public ID of the deliveries from newDelivery
+var testDeliveryID = entity.NewPublicID()
+
+func newDelivery(attempts int) *entity.WebhookDelivery {
+	return &entity.WebhookDelivery{
+		ID:             7,
+		PublicID:       testDeliveryID,
+		SubscriptionID: 1,
+		EventName:      "user.created",
+		Payload:        []byte(`{"event":"user.created"}`),
+		Status:         entity.WebhookDeliveryPending,
+		Attempts:       attempts,
+	}
+}
+
+// deliverOnce runs a single delivery and returns its saved state
+func deliverOnce(t *testing.T, worker *Worker, repo *fakeDeliveryRepository, delivery *entity.WebhookDelivery) entity.WebhookDelivery {
+	t.Helper()
+	worker.deliver(context.Background(), delivery, make(map[uint]*entity.WebhookSubscription))
+	if len(repo.saved) != 1 {
+		t.Fatalf("saved %d times, want once", len(repo.saved))
+	}
+	return repo.saved[0]
+}
+
+func TestWorkerDeliverSuccess(t *testing.T) {
+	server, requests := newReceiver(t, http.StatusNoContent, "")
+	worker, repo := newTestWorker(map[uint]*entity.WebhookSubscription{
+		1: {ID: 1, URL: server.URL, Secret: "whsec_test", Active: true},
+	})
+
+	saved := deliverOnce(t, worker, repo, newDelivery(1))
+
+	if saved.Status != entity.WebhookDeliveryDelivered || saved.DeliveredAt == nil {
+		t.Errorf("status = %q, delivered at %v; want delivered", saved.Status, saved.DeliveredAt)
+	}
+	if saved.ResponseCode == nil || *saved.ResponseCode != http.StatusNoContent {
+		t.Errorf("response code = %v, want 204", saved.ResponseCode)
+	}
+
+	got := <-requests
+	if got.event != "user.created" || got.delivery != testDeliveryID.String() {
+		t.Errorf("headers event=%q delivery=%q, want user.created and %s", got.event, got.delivery, testDeliveryID)
+	}
+	if !Verify("whsec_test", got.signature, got.body, time.Minute, time.Now()) {
+		t.Errorf("signature %q does not verify", got.signature)
+	}
+}
+
+func TestWorkerDeliverServerErrorRetries(t *testing.T) {
+	server, _ := newReceiver(t, http.StatusInternalServerError, "down")
+	worker, repo := newTestWorker(map[uint]*entity.WebhookSubscription{
+		1: {ID: 1, URL: server.URL, Secret: "whsec_test", Active: true},
+	})
+
+	before := time.Now()
+	saved := deliverOnce(t, worker, repo, newDelivery(2))
+
+	if saved.Status != entity.WebhookDeliveryPending {
+		t.Errorf("status = %q, want pending", saved.Status)
+	}
+	if saved.LastError != "unexpected status 500" || saved.ResponseBody != "down" {
+		t.Errorf("last error %q, response body %q", saved.LastError, saved.ResponseBody)
+	}
+	// The second failed attempt waits twice the first delay
+	if wait := saved.NextAttemptAt.Sub(before); wait < 2*minRetryDelay || wait > 2*minRetryDelay+time.Minute {
+		t.Errorf("next attempt in %v, want about %v", wait, 2*minRetryDelay)
+	}
+}
+
+func TestWorkerDeliverDeadAfterMaxAttempts(t *testing.T) {
+	server, _ := newReceiver(t, http.StatusBadGateway, "")
+	worker, repo := newTestWorker(map[uint]*entity.WebhookSubscription{
+		1: {ID: 1, URL: server.URL, Secret: "whsec_test", Active: true},
+	})
+
+	saved := deliverOnce(t, worker, repo, newDelivery(3))
+
+	if saved.Status != entity.WebhookDeliveryDead {
+		t.Errorf("status = %q, want dead", saved.Status)
+	}
+}
+
+func TestWorkerDeliverUnusableSubscription(t *testing.T) {
+	server, requests := newReceiver(t, http.StatusOK, "")
+
+	tests := []struct {
+		name          string
+		subscriptions map[uint]*entity.WebhookSubscription
+		wantError     string
+	}{
+		{"deleted", map[uint]*entity.WebhookSubscription{}, "subscription deleted"},
+		{"inactive", map[uint]*entity.WebhookSubscription{
+			1: {ID: 1, URL: server.URL, Secret: "whsec_test", Active: false},
+		}, "subscription inactive"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			worker, repo := newTestWorker(tt.subscriptions)
+
+			saved := deliverOnce(t, worker, repo, newDelivery(1))
+
+			if saved.Status != entity.WebhookDeliveryDead || saved.LastError != tt.wantError {
+				t.Errorf("status %q, last error %q; want dead, %q", saved.Status, saved.LastError, tt.wantError)
+			}
+		})
+	}
+	if len(requests) != 0 {
+		t.Errorf("receiver got %d requests, want none", len(requests))
+	}
+}
+
+func TestWorkerDeliverRefusesPrivateAddress(t *testing.T) {
+	server, requests := newReceiver(t, http.StatusOK, "")
+	worker, repo := newTestWorker(map[uint]*entity.WebhookSubscription{
+		1: {ID: 1, URL: server.URL, Secret: "whsec_test", Active: true},
+	})
+	worker.client = NewClient(5*time.Second, false)
+
+	saved := deliverOnce(t, worker, repo, newDelivery(1))
+
+	if saved.Status != entity.WebhookDeliveryPending || !strings.Contains(saved.LastError, ErrNonPublicAddress.Error()) {
+		t.Errorf("status %q, last error %q; want a refused attempt", saved.Status, saved.LastError)
+	}
+	if len(requests) != 0 {
+		t.Errorf("receiver got %d requests, want none", len(requests))
+	}
+}
+
+func TestIsPublic(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1:248:1893:25c8:1946", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"100.64.0.1", false},
+		{"0.0.0.0", false},
+		{"::", false},
+		{"fc00::1", false},
+		{"fe80::1", false},
+		{"::ffff:127.0.0.1", false},
+		{"::ffff:10.0.0.1", false},
+		{"255.255.255.255", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			if got := isPublic(mustParseAddr(t, tt.addr)); got != tt.want {
+				t.Errorf("isPublic(%s) = %v, want %v", tt.addr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		n    int
+		want string
+	}{
+		{"short", "ok", 10, "ok"},
+		{"ascii", "abcdef", 3, "abc"},
+		{"rune boundary", "aé", 2, "a"}, // é is two bytes
+		{"three-byte rune", "xyz€", 5, "xyz"},
+		{"invalid utf-8", "a\xffb", 10, "ab"},
+		{"nul bytes", "a\x00b", 10, "ab"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := truncate(tt.s, tt.n)
+			if got != tt.want || !utf8.ValidString(got) {
+				t.Errorf("truncate(%q, %d) = %q, want %q", tt.s, tt.n, got, tt.want)
+			}
+		})
+	}
+}
+
+func mustParseAddr(t *testing.T, s string) netip.Addr {
+	t.Helper()
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return addr
+}
@@ -0,0 +1,27 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AuditChangeResponse represents the values of a column before and after a change
+type AuditChangeResponse struct {
+	Before json.RawMessage `json:"before,omitempty"`
+	After  json.RawMessage `json:"after,omitempty"`
+}
+
+// AuditLogResponse represents an entry of the audit log. EntityID is the public ID of
+// entities that have one, and the ID of others.
+type AuditLogResponse struct {
+	ID            string                         `json:"id"`
+	ActorID       *string                        `json:"actor_id,omitempty"`
+	Action        string                         `json:"action"`
+	EntityType    string                         `json:"entity_type"`
+	EntityID      string                         `json:"entity_id"`
+	Changes       map[string]AuditChangeResponse `json:"changes"`
+	ChangedFields []string                       `json:"changed_fields"`
+	RequestID     string                         `json:"request_id,omitempty"`
+	IP            string                         `json:"ip,omitempty"`
+	CreatedAt     time.Time                      `json:"created_at"`
+}
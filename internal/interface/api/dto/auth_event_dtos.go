@@ -0,0 +1,17 @@
+package dto
+
+import "time"
+
+// AuthEventResponse represents an authentication event
+type AuthEventResponse struct {
+	ID        string    `json:"id"`
+	UserID    *string   `json:"user_id,omitempty"`
+	Username  string    `json:"username,omitempty"`
+	Type      string    `json:"type"`
+	Success   bool      `json:"success"`
+	Reason    string    `json:"reason,omitempty"`
+	IP        string    `json:"ip"`
+	UserAgent string    `json:"user_agent"`
+	RequestID string    `json:"request_id,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
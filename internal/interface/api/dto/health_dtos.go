@@ -0,0 +1,28 @@
+package dto
+
+// HealthResponse represents the health of the service: "ok", "degraded" when a read
+// replica is unhealthy, or "unavailable" when the primary database is
+type HealthResponse struct {
+	Status   string                 `json:"status"`
+	Database DatabaseHealthResponse `json:"database"`
+}
+
+// DatabaseHealthResponse represents the health and usage of the database connection pools
+type DatabaseHealthResponse struct {
+	Status string                       `json:"status"`
+	Pools  map[string]PoolStatsResponse `json:"pools"`
+}
+
+// PoolStatsResponse represents the statistics of a connection pool
+type PoolStatsResponse struct {
+	Healthy            bool  `json:"healthy"`
+	MaxOpenConnections int   `json:"max_open_connections"`
+	OpenConnections    int   `json:"open_connections"`
+	InUse              int   `json:"in_use"`
+	Idle               int   `json:"idle"`
+	WaitCount          int64 `json:"wait_count"`
+	WaitDurationMs     int64 `json:"wait_duration_ms"`
+	MaxIdleClosed      int64 `json:"max_idle_closed"`
+	MaxIdleTimeClosed  int64 `json:"max_idle_time_closed"`
+	MaxLifetimeClosed  int64 `json:"max_lifetime_closed"`
+}
@@ -0,0 +1,35 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/thienel/go-backend-template/pkg/query"
+)
+
+// CreateSavedViewRequest represents saved view creation request. Query holds the list
+// parameters to save, in the same format as the list endpoint, e.g. "status=ACTIVE&sort=-created_at".
+type CreateSavedViewRequest struct {
+	Name     string `json:"name" binding:"required,max=100"`
+	Resource string `json:"resource" binding:"required"`
+	Shared   bool   `json:"shared"`
+	Query    string `json:"query"`
+}
+
+// UpdateSavedViewRequest represents saved view update request
+type UpdateSavedViewRequest struct {
+	Name   string  `json:"name,omitempty" binding:"omitempty,max=100"`
+	Shared *bool   `json:"shared,omitempty"`
+	Query  *string `json:"query,omitempty"`
+}
+
+// SavedViewResponse represents a saved view
+type SavedViewResponse struct {
+	ID        string             `json:"id"`
+	OwnerID   string             `json:"owner_id"`
+	Resource  string             `json:"resource"`
+	Name      string             `json:"name"`
+	Shared    bool               `json:"shared"`
+	Options   query.QueryOptions `json:"options"`
+	CreatedAt time.Time          `json:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at"`
+}
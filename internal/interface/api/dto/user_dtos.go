@@ -8,6 +8,7 @@ type CreateUserRequest struct {
 	Email    string `json:"email" binding:"required,email"`
 	Password string `json:"password" binding:"required,min=6"`
 	Role     string `json:"role,omitempty"`
+	Status   string `json:"status,omitempty"`
 }
 
 // UpdateUserRequest represents user update request
@@ -15,28 +16,72 @@ type UpdateUserRequest struct {
 	Username string `json:"username,omitempty" binding:"omitempty,min=3,max=50"`
 	Email    string `json:"email,omitempty" binding:"omitempty,email"`
 	Role     string `json:"role,omitempty"`
-	Status   string `json:"status,omitempty"`
+}
+
+// ChangeUserStatusRequest represents a status transition request
+type ChangeUserStatusRequest struct {
+	Reason string `json:"reason,omitempty" binding:"omitempty,max=255"`
+}
+
+// SuspendUserRequest represents user suspension request
+type SuspendUserRequest struct {
+	Reason string     `json:"reason" binding:"required,max=255"`
+	Until  *time.Time `json:"until,omitempty"`
 }
 
 // UserResponse represents user response
 type UserResponse struct {
-	ID        uint       `json:"id"`
-	Username  string     `json:"username"`
-	Email     string     `json:"email"`
-	Role      string     `json:"role"`
-	Status    string     `json:"status"`
-	CreatedAt time.Time  `json:"created_at"`
-	UpdatedAt time.Time  `json:"updated_at"`
-	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	ID             string     `json:"id"`
+	Username       string     `json:"username"`
+	Email          string     `json:"email"`
+	Role           string     `json:"role"`
+	Status         string     `json:"status"`
+	StatusReason   string     `json:"status_reason,omitempty"`
+	SuspendedUntil *time.Time `json:"suspended_until,omitempty"`
+	LastLoginAt    *time.Time `json:"last_login_at,omitempty"`
+	LastLoginIP    string     `json:"last_login_ip,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+	DeletedAt      *time.Time `json:"deleted_at,omitempty"`
+	Version        uint       `json:"version"`
+
+	StatusHistory []UserStatusHistoryResponse `json:"status_history,omitempty"`
+
+	// Highlights holds search snippets by field, with matches wrapped in <mark>
+	Highlights map[string]string `json:"highlights,omitempty"`
+}
+
+// UserStatusHistoryResponse represents a user status transition
+type UserStatusHistoryResponse struct {
+	ID             string     `json:"id"`
+	Transition     string     `json:"transition"`
+	FromStatus     string     `json:"from_status"`
+	ToStatus       string     `json:"to_status"`
+	Reason         string     `json:"reason,omitempty"`
+	SuspendedUntil *time.Time `json:"suspended_until,omitempty"`
+	ActorID        *string    `json:"actor_id,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
 }
 
-// ListResponse represents paginated list response
+// ListResponse represents paginated list response. Page is only set in offset mode,
+// the cursors only in cursor mode, and the totals only when they were counted.
+// Facets and Aggregates are computed over all matching rows, not just the page.
 type ListResponse[T any] struct {
-	Items      []T   `json:"items"`
-	Total      int64 `json:"total"`
-	Page       int   `json:"page"`
-	Limit      int   `json:"limit"`
-	TotalPages int   `json:"total_pages"`
+	Items      []T                             `json:"items"`
+	Total      *int64                          `json:"total,omitempty"`
+	Page       int                             `json:"page,omitempty"`
+	Limit      int                             `json:"limit"`
+	TotalPages *int                            `json:"total_pages,omitempty"`
+	NextCursor string                          `json:"next_cursor,omitempty"`
+	PrevCursor string                          `json:"prev_cursor,omitempty"`
+	Facets     map[string][]FacetCountResponse `json:"facets,omitempty"`
+	Aggregates map[string]any                  `json:"aggregates,omitempty"`
+}
+
+// FacetCountResponse represents the number of matching rows with a value
+type FacetCountResponse struct {
+	Value any   `json:"value"`
+	Count int64 `json:"count"`
 }
 
 // LoginRequest represents login request
@@ -45,9 +90,69 @@ type LoginRequest struct {
 	Password string `json:"password" binding:"required"`
 }
 
+// RefreshTokenRequest represents token refresh request
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// ChangePasswordRequest represents password change request
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required,min=6"`
+}
+
 // LoginResponse represents login response
 type LoginResponse struct {
 	User         UserResponse `json:"user"`
 	AccessToken  string       `json:"access_token"`
 	RefreshToken string       `json:"refresh_token,omitempty"`
 }
+
+// ImportUsersResponse represents the per-row report of a bulk user import
+type ImportUsersResponse struct {
+	DryRun    bool                    `json:"dry_run"`
+	Total     int                     `json:"total"`
+	Succeeded int                     `json:"succeeded"`
+	Failed    int                     `json:"failed"`
+	Rows      []ImportUserRowResponse `json:"rows"`
+}
+
+// ImportUserRowResponse represents the outcome of a single imported row
+type ImportUserRowResponse struct {
+	Line         int    `json:"line"`
+	Username     string `json:"username,omitempty"`
+	Success      bool   `json:"success"`
+	UserID       string `json:"user_id,omitempty"`
+	ErrorCode    string `json:"error_code,omitempty"`
+	ErrorMessage string `json:"error_message,omitempty"`
+}
+
+// BulkUserRequest represents a bulk action on users, targeted by IDs or by a filter
+// using the same field[op]=value syntax as the list endpoint
+type BulkUserRequest struct {
+	IDs    []string          `json:"ids,omitempty"`
+	Filter map[string]string `json:"filter,omitempty"`
+	Action string            `json:"action" binding:"required,oneof=deactivate change_role delete"`
+	Role   string            `json:"role,omitempty"`
+	Reason string            `json:"reason,omitempty" binding:"omitempty,max=255"`
+	Mode   string            `json:"mode,omitempty" binding:"omitempty,oneof=atomic best_effort"`
+}
+
+// BulkUserResponse represents the per-item report of a bulk action
+type BulkUserResponse struct {
+	Action     string                 `json:"action"`
+	Mode       string                 `json:"mode"`
+	Total      int                    `json:"total"`
+	Succeeded  int                    `json:"succeeded"`
+	Failed     int                    `json:"failed"`
+	RolledBack bool                   `json:"rolled_back"`
+	Items      []BulkUserItemResponse `json:"items"`
+}
+
+// BulkUserItemResponse represents the outcome of a bulk action for one user
+type BulkUserItemResponse struct {
+	ID           string `json:"id"`
+	Status       string `json:"status"`
+	ErrorCode    string `json:"error_code,omitempty"`
+	ErrorMessage string `json:"error_message,omitempty"`
+}
@@ -0,0 +1,55 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// CreateWebhookRequest represents webhook subscription request. EventTypes holds event
+// names such as "user.created", or "*" for every event; a secret is generated if none is given.
+type CreateWebhookRequest struct {
+	URL         string   `json:"url" binding:"required,url,max=500"`
+	EventTypes  []string `json:"event_types" binding:"required,min=1"`
+	Secret      string   `json:"secret,omitempty" binding:"omitempty,max=100"`
+	Description string   `json:"description,omitempty" binding:"omitempty,max=255"`
+	Active      *bool    `json:"active,omitempty"`
+}
+
+// UpdateWebhookRequest represents webhook subscription update request
+type UpdateWebhookRequest struct {
+	URL         string   `json:"url,omitempty" binding:"omitempty,url,max=500"`
+	EventTypes  []string `json:"event_types,omitempty" binding:"omitempty,min=1"`
+	Secret      string   `json:"secret,omitempty" binding:"omitempty,max=100"`
+	Description *string  `json:"description,omitempty" binding:"omitempty,max=255"`
+	Active      *bool    `json:"active,omitempty"`
+}
+
+// WebhookResponse represents a webhook subscription. Secret is only returned when the
+// subscription is created.
+type WebhookResponse struct {
+	ID          string    `json:"id"`
+	URL         string    `json:"url"`
+	EventTypes  []string  `json:"event_types"`
+	Secret      string    `json:"secret,omitempty"`
+	Description string    `json:"description,omitempty"`
+	Active      bool      `json:"active"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// WebhookDeliveryResponse represents a webhook delivery and the outcome of its last attempt
+type WebhookDeliveryResponse struct {
+	ID             string          `json:"id"`
+	SubscriptionID string          `json:"subscription_id"`
+	EventName      string          `json:"event_name"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at"`
+	ResponseCode   *int            `json:"response_code,omitempty"`
+	ResponseBody   string          `json:"response_body,omitempty"`
+	LastError      string          `json:"last_error,omitempty"`
+	DurationMs     int64           `json:"duration_ms"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/thienel/go-backend-template/internal/domain/entity"
+	"github.com/thienel/go-backend-template/internal/domain/repository"
+	"github.com/thienel/go-backend-template/internal/interface/api/dto"
+	"github.com/thienel/go-backend-template/internal/usecase/service"
+	"github.com/thienel/go-backend-template/pkg/query"
+	"github.com/thienel/go-backend-template/pkg/response"
+)
+
+// AuditLogHandler interface
+type AuditLogHandler interface {
+	List(c *gin.Context)
+}
+
+type auditLogHandlerImpl struct {
+	auditLogService service.AuditLogService
+}
+
+// NewAuditLogHandler creates a new audit log handler
+func NewAuditLogHandler(auditLogService service.AuditLogService) AuditLogHandler {
+	return &auditLogHandlerImpl{auditLogService: auditLogService}
+}
+
+func (h *auditLogHandlerImpl) List(c *gin.Context) {
+	params := make(map[string]string)
+	for key, values := range c.Request.URL.Query() {
+		if len(values) > 0 {
+			params[key] = values[0]
+		}
+	}
+
+	pagination, err := query.ParsePagination(params, 20)
+	if err != nil {
+		response.WriteErrorResponse(c, err)
+		return
+	}
+	opts, err := query.ParseQueryParams(params, repository.AuditLogQuerySchema)
+	if err != nil {
+		response.WriteErrorResponse(c, err)
+		return
+	}
+	if err := query.ParseProjectionParams(params, repository.AuditLogProjection, &opts); err != nil {
+		response.WriteErrorResponse(c, err)
+		return
+	}
+
+	page, err := h.auditLogService.List(c.Request.Context(), pagination, opts)
+	if err != nil {
+		response.WriteErrorResponse(c, err)
+		return
+	}
+
+	writeListResponse(c, toListResponse(page, pagination, toAuditLogResponse), opts)
+}
+
+func toAuditLogResponse(l *entity.AuditLog) dto.AuditLogResponse {
+	changes := make(map[string]dto.AuditChangeResponse, len(l.Changes))
+	for column, change := range l.Changes {
+		changes[column] = dto.AuditChangeResponse{Before: change.Before, After: change.After}
+	}
+	return dto.AuditLogResponse{
+		ID:            l.PublicID.String(),
+		ActorID:       publicIDString(l.ActorPublicID),
+		Action:        l.Action,
+		EntityType:    l.EntityType,
+		EntityID:      l.EntityID,
+		Changes:       changes,
+		ChangedFields: l.ChangedFields,
+		RequestID:     l.RequestID,
+		IP:            l.IP,
+		CreatedAt:     l.CreatedAt,
+	}
+}
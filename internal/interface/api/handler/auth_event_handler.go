@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/thienel/go-backend-template/internal/domain/entity"
+	"github.com/thienel/go-backend-template/internal/domain/repository"
+	"github.com/thienel/go-backend-template/internal/interface/api/dto"
+	"github.com/thienel/go-backend-template/internal/interface/api/middleware"
+	"github.com/thienel/go-backend-template/internal/usecase/service"
+	"github.com/thienel/go-backend-template/pkg/query"
+	"github.com/thienel/go-backend-template/pkg/response"
+)
+
+// AuthEventHandler interface
+type AuthEventHandler interface {
+	List(c *gin.Context)
+	ListMine(c *gin.Context)
+}
+
+type authEventHandlerImpl struct {
+	authEventService service.AuthEventService
+	savedViewService service.SavedViewService
+}
+
+// NewAuthEventHandler creates a new auth event handler
+func NewAuthEventHandler(authEventService service.AuthEventService, savedViewService service.SavedViewService) AuthEventHandler {
+	return &authEventHandlerImpl{authEventService: authEventService, savedViewService: savedViewService}
+}
+
+func (h *authEventHandlerImpl) List(c *gin.Context) {
+	params := make(map[string]string)
+	for key, values := range c.Request.URL.Query() {
+		if len(values) > 0 {
+			params[key] = values[0]
+		}
+	}
+
+	pagination, err := query.ParsePagination(params, 20)
+	if err != nil {
+		response.WriteErrorResponse(c, err)
+		return
+	}
+	opts, err := query.ParseQueryParams(params, repository.AuthEventQuerySchema)
+	if err != nil {
+		response.WriteErrorResponse(c, err)
+		return
+	}
+	if err := query.ParseProjectionParams(params, repository.AuthEventProjection, &opts); err != nil {
+		response.WriteErrorResponse(c, err)
+		return
+	}
+	if opts, err = applySavedView(c, h.savedViewService, entity.SavedViewResourceAuthEvents, params, opts); err != nil {
+		response.WriteErrorResponse(c, err)
+		return
+	}
+
+	page, err := h.authEventService.List(c.Request.Context(), pagination, opts)
+	if err != nil {
+		response.WriteErrorResponse(c, err)
+		return
+	}
+
+	writeListResponse(c, toListResponse(page, pagination, toAuthEventResponse), opts)
+}
+
+func (h *authEventHandlerImpl) ListMine(c *gin.Context) {
+	params := make(map[string]string)
+	for key, values := range c.Request.URL.Query() {
+		if len(values) > 0 {
+			params[key] = values[0]
+		}
+	}
+
+	pagination, err := query.ParsePagination(params, 20)
+	if err != nil {
+		response.WriteErrorResponse(c, err)
+		return
+	}
+	opts, err := query.ParseQueryParams(params, repository.AuthEventQuerySchema)
+	if err != nil {
+		response.WriteErrorResponse(c, err)
+		return
+	}
+	if err := query.ParseProjectionParams(params, repository.AuthEventProjection, &opts); err != nil {
+		response.WriteErrorResponse(c, err)
+		return
+	}
+	if opts, err = applySavedView(c, h.savedViewService, entity.SavedViewResourceAuthEvents, params, opts); err != nil {
+		response.WriteErrorResponse(c, err)
+		return
+	}
+
+	page, err := h.authEventService.ListByUser(c.Request.Context(), middleware.GetUserID(c), pagination, opts)
+	if err != nil {
+		response.WriteErrorResponse(c, err)
+		return
+	}
+
+	writeListResponse(c, toListResponse(page, pagination, toAuthEventResponse), opts)
+}
+
+func toAuthEventResponse(e *entity.AuthEvent) dto.AuthEventResponse {
+	return dto.AuthEventResponse{
+		ID:        e.PublicID.String(),
+		UserID:    publicIDString(e.UserPublicID),
+		Username:  e.Username,
+		Type:      e.Type,
+		Success:   e.Success,
+		Reason:    e.Reason,
+		IP:        e.IP,
+		UserAgent: e.UserAgent,
+		RequestID: e.RequestID,
+		CreatedAt: e.CreatedAt,
+	}
+}
@@ -14,8 +14,10 @@ import (
 // AuthHandler interface
 type AuthHandler interface {
 	Login(c *gin.Context)
+	Refresh(c *gin.Context)
 	Logout(c *gin.Context)
 	GetMe(c *gin.Context)
+	ChangePassword(c *gin.Context)
 }
 
 type authHandlerImpl struct {
@@ -47,6 +49,22 @@ func (h *authHandlerImpl) Login(c *gin.Context) {
 	response.OK(c, loginResp, "Đăng nhập thành công")
 }
 
+func (h *authHandlerImpl) Refresh(c *gin.Context) {
+	var req dto.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.WriteErrorResponse(c, apperror.ErrBadRequest.WithMessage("Dữ liệu không hợp lệ"))
+		return
+	}
+
+	loginResp, err := h.authService.Refresh(c.Request.Context(), req.RefreshToken)
+	if err != nil {
+		response.WriteErrorResponse(c, err)
+		return
+	}
+
+	response.OK(c, loginResp, "Làm mới token thành công")
+}
+
 func (h *authHandlerImpl) Logout(c *gin.Context) {
 	if err := h.authService.Logout(c.Request.Context()); err != nil {
 		response.WriteErrorResponse(c, err)
@@ -65,15 +83,38 @@ func (h *authHandlerImpl) GetMe(c *gin.Context) {
 	response.OK(c, toAuthUserResponse(user), "")
 }
 
+func (h *authHandlerImpl) ChangePassword(c *gin.Context) {
+	var req dto.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.WriteErrorResponse(c, apperror.ErrValidation.WithMessage("Dữ liệu không hợp lệ"))
+		return
+	}
+
+	if err := h.authService.ChangePassword(c.Request.Context(), service.ChangePasswordCommand{
+		UserID:          middleware.GetUserID(c),
+		CurrentPassword: req.CurrentPassword,
+		NewPassword:     req.NewPassword,
+	}); err != nil {
+		response.WriteErrorResponse(c, err)
+		return
+	}
+
+	response.OK[any](c, nil, "Đổi mật khẩu thành công")
+}
+
 func toAuthUserResponse(user *entity.User) dto.UserResponse {
 	resp := dto.UserResponse{
-		ID:        user.ID,
-		Username:  user.Username,
-		Email:     user.Email,
-		Role:      user.Role,
-		Status:    user.Status,
-		CreatedAt: user.CreatedAt,
-		UpdatedAt: user.UpdatedAt,
+		ID:             user.PublicID.String(),
+		Username:       user.Username,
+		Email:          user.Email,
+		Role:           user.Role,
+		Status:         user.Status,
+		StatusReason:   user.StatusReason,
+		SuspendedUntil: user.SuspendedUntil,
+		LastLoginAt:    user.LastLoginAt,
+		LastLoginIP:    user.LastLoginIP,
+		CreatedAt:      user.CreatedAt,
+		UpdatedAt:      user.UpdatedAt,
 	}
 	if user.DeletedAt.Valid {
 		resp.DeletedAt = &user.DeletedAt.Time
@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	apperror "github.com/thienel/go-backend-template/pkg/error"
+)
+
+// setETag exposes the version of the returned entity as its ETag
+func setETag(c *gin.Context, version uint) {
+	c.Header("ETag", strconv.Quote(strconv.FormatUint(uint64(version), 10)))
+}
+
+// requireIfMatch returns the version the client expects from the If-Match header.
+// The header is mandatory; "*" matches any version and yields 0.
+func requireIfMatch(c *gin.Context) (uint, error) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" {
+		return 0, apperror.ErrPreconditionRequired
+	}
+	if header == "*" {
+		return 0, nil
+	}
+
+	tag := strings.TrimPrefix(header, "W/")
+	unquoted, err := strconv.Unquote(tag)
+	if err != nil {
+		unquoted = tag
+	}
+	version, err := strconv.ParseUint(unquoted, 10, 32)
+	if err != nil || version == 0 {
+		return 0, apperror.ErrBadRequest.WithMessage("Header If-Match không hợp lệ")
+	}
+	return uint(version), nil
+}
@@ -0,0 +1,173 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/thienel/tlog"
+	"go.uber.org/zap"
+
+	"github.com/thienel/go-backend-template/internal/interface/api/dto"
+	"github.com/thienel/go-backend-template/pkg/cache"
+)
+
+// healthPingTimeout bounds the database check of a health request
+const healthPingTimeout = 2 * time.Second
+
+// DatabaseMonitor reports on the database connection pools, keyed by pool name.
+// ReplicaHealth only has the read replica pools; PoolAddresses names their hosts, which
+// only metrics may show.
+type DatabaseMonitor interface {
+	Ping(ctx context.Context) error
+	Stats() map[string]sql.DBStats
+	ReplicaHealth() map[string]bool
+	PoolAddresses() map[string]string
+}
+
+// CacheMonitor reports the lookups of each cache by name
+type CacheMonitor interface {
+	Stats() map[string]cache.Stats
+}
+
+// HealthHandler interface
+type HealthHandler interface {
+	Health(c *gin.Context)
+	Metrics(c *gin.Context)
+}
+
+type healthHandlerImpl struct {
+	monitor DatabaseMonitor
+	caches  CacheMonitor
+}
+
+// NewHealthHandler creates a new health handler
+func NewHealthHandler(monitor DatabaseMonitor, caches CacheMonitor) HealthHandler {
+	return &healthHandlerImpl{monitor: monitor, caches: caches}
+}
+
+// Health reports the service as unavailable when the primary database does not answer,
+// and as degraded when a read replica is unhealthy. The endpoint is public, so the
+// cause of a failure is only logged: driver errors name hosts, users and databases.
+func (h *healthHandlerImpl) Health(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), healthPingTimeout)
+	defer cancel()
+	pingErr := h.monitor.Ping(ctx)
+
+	resp := dto.HealthResponse{
+		Status:   "ok",
+		Database: dto.DatabaseHealthResponse{Status: "ok", Pools: make(map[string]dto.PoolStatsResponse)},
+	}
+	replicas := h.monitor.ReplicaHealth()
+	for name, stats := range h.monitor.Stats() {
+		// Pools that are not replicas are the primary, checked by the ping
+		healthy, isReplica := replicas[name]
+		if !isReplica {
+			healthy = pingErr == nil
+		} else if !healthy {
+			resp.Status, resp.Database.Status = "degraded", "degraded"
+		}
+
+		resp.Database.Pools[name] = dto.PoolStatsResponse{
+			Healthy:            healthy,
+			MaxOpenConnections: stats.MaxOpenConnections,
+			OpenConnections:    stats.OpenConnections,
+			InUse:              stats.InUse,
+			Idle:               stats.Idle,
+			WaitCount:          stats.WaitCount,
+			WaitDurationMs:     stats.WaitDuration.Milliseconds(),
+			MaxIdleClosed:      stats.MaxIdleClosed,
+			MaxIdleTimeClosed:  stats.MaxIdleTimeClosed,
+			MaxLifetimeClosed:  stats.MaxLifetimeClosed,
+		}
+	}
+
+	status := http.StatusOK
+	if pingErr != nil {
+		status = http.StatusServiceUnavailable
+		resp.Status, resp.Database.Status = "unavailable", "unavailable"
+		tlog.Error("Health check failed: database unavailable", zap.Error(pingErr))
+	}
+	c.JSON(status, resp)
+}
+
+// Metrics writes the pool and cache statistics in the Prometheus text format. It is
+// served on the separate metrics listener only (see router.SetupMetricsRouter).
+func (h *healthHandlerImpl) Metrics(c *gin.Context) {
+	stats := h.monitor.Stats()
+	health := h.monitor.ReplicaHealth()
+
+	names := make([]string, 0, len(stats))
+	for name := range stats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	metric := func(name, kind, help string, value func(name string, s sql.DBStats) float64) {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+		for _, pool := range names {
+			fmt.Fprintf(&b, "%s{pool=%q} %g\n", name, pool, value(pool, stats[pool]))
+		}
+	}
+
+	addrs := h.monitor.PoolAddresses()
+	fmt.Fprintf(&b, "# HELP db_pool_info The address of each replica pool.\n# TYPE db_pool_info gauge\n")
+	for _, pool := range names {
+		if addr, ok := addrs[pool]; ok {
+			fmt.Fprintf(&b, "db_pool_info{pool=%q,address=%q} 1\n", pool, addr)
+		}
+	}
+	metric("db_pool_healthy", "gauge", "Whether the replica pool passed its last health check; always 1 for the primary.",
+		func(pool string, _ sql.DBStats) float64 {
+			if healthy, ok := health[pool]; ok && !healthy {
+				return 0
+			}
+			return 1
+		})
+	metric("db_pool_max_open_connections", "gauge", "Maximum number of open connections.",
+		func(_ string, s sql.DBStats) float64 { return float64(s.MaxOpenConnections) })
+	metric("db_pool_open_connections", "gauge", "Number of open connections, in use or idle.",
+		func(_ string, s sql.DBStats) float64 { return float64(s.OpenConnections) })
+	metric("db_pool_in_use_connections", "gauge", "Number of connections in use.",
+		func(_ string, s sql.DBStats) float64 { return float64(s.InUse) })
+	metric("db_pool_idle_connections", "gauge", "Number of idle connections.",
+		func(_ string, s sql.DBStats) float64 { return float64(s.Idle) })
+	metric("db_pool_wait_count_total", "counter", "Number of times a connection was waited for.",
+		func(_ string, s sql.DBStats) float64 { return float64(s.WaitCount) })
+	metric("db_pool_wait_duration_seconds_total", "counter", "Time spent waiting for connections.",
+		func(_ string, s sql.DBStats) float64 { return s.WaitDuration.Seconds() })
+	metric("db_pool_max_idle_closed_total", "counter", "Connections closed because of the idle connection limit.",
+		func(_ string, s sql.DBStats) float64 { return float64(s.MaxIdleClosed) })
+	metric("db_pool_max_idle_time_closed_total", "counter", "Connections closed because they were idle too long.",
+		func(_ string, s sql.DBStats) float64 { return float64(s.MaxIdleTimeClosed) })
+	metric("db_pool_max_lifetime_closed_total", "counter", "Connections closed because they reached their maximum lifetime.",
+		func(_ string, s sql.DBStats) float64 { return float64(s.MaxLifetimeClosed) })
+
+	cacheStats := h.caches.Stats()
+	caches := make([]string, 0, len(cacheStats))
+	for name := range cacheStats {
+		caches = append(caches, name)
+	}
+	sort.Strings(caches)
+
+	cacheMetric := func(name, help string, value func(s cache.Stats) int64) {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s counter\n", name, help, name)
+		for _, cacheName := range caches {
+			fmt.Fprintf(&b, "%s{cache=%q} %d\n", name, cacheName, value(cacheStats[cacheName]))
+		}
+	}
+	cacheMetric("cache_hits_total", "Lookups served from the cache.",
+		func(s cache.Stats) int64 { return s.Hits })
+	cacheMetric("cache_misses_total", "Lookups loaded from the database.",
+		func(s cache.Stats) int64 { return s.Misses })
+	cacheMetric("cache_errors_total", "Failed cache backend calls.",
+		func(s cache.Stats) int64 { return s.Errors })
+
+	c.Data(http.StatusOK, "text/plain; version=0.0.4; charset=utf-8", []byte(b.String()))
+}
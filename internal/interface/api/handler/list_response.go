@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/thienel/go-backend-template/internal/interface/api/dto"
+	apperror "github.com/thienel/go-backend-template/pkg/error"
+	"github.com/thienel/go-backend-template/pkg/query"
+	"github.com/thienel/go-backend-template/pkg/response"
+)
+
+// toListResponse converts a page of entities to a list response
+func toListResponse[E any, T any](page query.Page[E], pagination query.Pagination, convert func(*E) T) dto.ListResponse[T] {
+	items := make([]T, len(page.Items))
+	for i := range page.Items {
+		items[i] = convert(&page.Items[i])
+	}
+
+	resp := dto.ListResponse[T]{
+		Items:      items,
+		Limit:      pagination.Limit,
+		NextCursor: page.NextCursor,
+		PrevCursor: page.PrevCursor,
+		Aggregates: page.Aggregates,
+	}
+	if len(page.Facets) > 0 {
+		resp.Facets = make(map[string][]dto.FacetCountResponse, len(page.Facets))
+		for name, counts := range page.Facets {
+			buckets := make([]dto.FacetCountResponse, len(counts))
+			for i, c := range counts {
+				buckets[i] = dto.FacetCountResponse{Value: c.Value, Count: c.Count}
+			}
+			resp.Facets[name] = buckets
+		}
+	}
+	if !pagination.IsCursor() {
+		resp.Page = (pagination.Offset / pagination.Limit) + 1
+	}
+	if page.HasTotal {
+		total := page.Total
+		totalPages := int((total + int64(pagination.Limit) - 1) / int64(pagination.Limit))
+		resp.Total = &total
+		resp.TotalPages = &totalPages
+	}
+	return resp
+}
+
+// writeListResponse sends a list response, narrowing each item to the sparse fieldset of opts
+func writeListResponse[T any](c *gin.Context, resp dto.ListResponse[T], opts query.QueryOptions) {
+	if len(opts.Fields) == 0 {
+		response.OK(c, resp, "")
+		return
+	}
+
+	items := make([]any, len(resp.Items))
+	for i, item := range resp.Items {
+		picked, err := query.PickFields(item, opts)
+		if err != nil {
+			response.WriteErrorResponse(c, apperror.ErrInternalServerError.WithError(err))
+			return
+		}
+		items[i] = picked
+	}
+
+	response.OK(c, dto.ListResponse[any]{
+		Items:      items,
+		Total:      resp.Total,
+		Page:       resp.Page,
+		Limit:      resp.Limit,
+		TotalPages: resp.TotalPages,
+		NextCursor: resp.NextCursor,
+		PrevCursor: resp.PrevCursor,
+		Facets:     resp.Facets,
+		Aggregates: resp.Aggregates,
+	}, "")
+}
+
+// writeItemResponse sends a single item, narrowed to the sparse fieldset of opts
+func writeItemResponse(c *gin.Context, item any, opts query.QueryOptions) {
+	picked, err := query.PickFields(item, opts)
+	if err != nil {
+		response.WriteErrorResponse(c, apperror.ErrInternalServerError.WithError(err))
+		return
+	}
+	response.OK(c, picked, "")
+}
@@ -0,0 +1,251 @@
+package handler
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/thienel/go-backend-template/internal/domain/entity"
+	"github.com/thienel/go-backend-template/internal/domain/repository"
+	"github.com/thienel/go-backend-template/internal/interface/api/dto"
+	"github.com/thienel/go-backend-template/internal/usecase/service"
+	apperror "github.com/thienel/go-backend-template/pkg/error"
+	"github.com/thienel/go-backend-template/pkg/query"
+	"github.com/thienel/go-backend-template/pkg/response"
+)
+
+// savedViewResource is how the list endpoint of a resource parses its query
+type savedViewResource struct {
+	schema     query.Schema
+	projection query.Projection
+}
+
+var savedViewResources = map[string]savedViewResource{
+	entity.SavedViewResourceUsers:      {schema: repository.UserQuerySchema, projection: repository.UserProjection},
+	entity.SavedViewResourceAuthEvents: {schema: repository.AuthEventQuerySchema, projection: repository.AuthEventProjection},
+}
+
+// SavedViewHandler interface
+type SavedViewHandler interface {
+	List(c *gin.Context)
+	GetByID(c *gin.Context)
+	Create(c *gin.Context)
+	Update(c *gin.Context)
+	Delete(c *gin.Context)
+}
+
+type savedViewHandlerImpl struct {
+	savedViewService service.SavedViewService
+}
+
+// NewSavedViewHandler creates a new saved view handler
+func NewSavedViewHandler(savedViewService service.SavedViewService) SavedViewHandler {
+	return &savedViewHandlerImpl{savedViewService: savedViewService}
+}
+
+func (h *savedViewHandlerImpl) List(c *gin.Context) {
+	params := make(map[string]string)
+	for key, values := range c.Request.URL.Query() {
+		if len(values) > 0 {
+			params[key] = values[0]
+		}
+	}
+
+	pagination, err := query.ParsePagination(params, 20)
+	if err != nil {
+		response.WriteErrorResponse(c, err)
+		return
+	}
+	opts, err := query.ParseQueryParams(params, repository.SavedViewQuerySchema)
+	if err != nil {
+		response.WriteErrorResponse(c, err)
+		return
+	}
+
+	page, err := h.savedViewService.List(c.Request.Context(), pagination, opts)
+	if err != nil {
+		response.WriteErrorResponse(c, err)
+		return
+	}
+
+	response.OK(c, toListResponse(page, pagination, toSavedViewResponse), "")
+}
+
+func (h *savedViewHandlerImpl) GetByID(c *gin.Context) {
+	id, err := h.viewID(c)
+	if err != nil {
+		response.WriteErrorResponse(c, err)
+		return
+	}
+
+	view, err := h.savedViewService.GetByID(c.Request.Context(), id)
+	if err != nil {
+		response.WriteErrorResponse(c, err)
+		return
+	}
+
+	response.OK(c, toSavedViewResponse(view), "")
+}
+
+func (h *savedViewHandlerImpl) Create(c *gin.Context) {
+	var req dto.CreateSavedViewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.WriteErrorResponse(c, apperror.ErrValidation.WithMessage("Dữ liệu không hợp lệ"))
+		return
+	}
+
+	opts, err := parseSavedViewQuery(req.Resource, req.Query)
+	if err != nil {
+		response.WriteErrorResponse(c, err)
+		return
+	}
+
+	view, err := h.savedViewService.Create(c.Request.Context(), service.CreateSavedViewCommand{
+		Name:     req.Name,
+		Resource: req.Resource,
+		Shared:   req.Shared,
+		Options:  opts,
+	})
+	if err != nil {
+		response.WriteErrorResponse(c, err)
+		return
+	}
+
+	response.Created(c, toSavedViewResponse(view), "Lưu bộ lọc thành công")
+}
+
+func (h *savedViewHandlerImpl) Update(c *gin.Context) {
+	id, err := h.viewID(c)
+	if err != nil {
+		response.WriteErrorResponse(c, err)
+		return
+	}
+
+	var req dto.UpdateSavedViewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.WriteErrorResponse(c, apperror.ErrValidation.WithMessage("Dữ liệu không hợp lệ"))
+		return
+	}
+
+	cmd := service.UpdateSavedViewCommand{ID: id, Name: req.Name, Shared: req.Shared}
+	if req.Query != nil {
+		view, err := h.savedViewService.GetByID(c.Request.Context(), id)
+		if err != nil {
+			response.WriteErrorResponse(c, err)
+			return
+		}
+		opts, err := parseSavedViewQuery(view.Resource, *req.Query)
+		if err != nil {
+			response.WriteErrorResponse(c, err)
+			return
+		}
+		cmd.Options = &opts
+	}
+
+	view, err := h.savedViewService.Update(c.Request.Context(), cmd)
+	if err != nil {
+		response.WriteErrorResponse(c, err)
+		return
+	}
+
+	response.OK(c, toSavedViewResponse(view), "Cập nhật thành công")
+}
+
+func (h *savedViewHandlerImpl) Delete(c *gin.Context) {
+	id, err := h.viewID(c)
+	if err != nil {
+		response.WriteErrorResponse(c, err)
+		return
+	}
+
+	if err := h.savedViewService.Delete(c.Request.Context(), id); err != nil {
+		response.WriteErrorResponse(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// viewID resolves the public ID in the path to the ID of the view
+func (h *savedViewHandlerImpl) viewID(c *gin.Context) (uint, error) {
+	publicID, err := entity.ParsePublicID(c.Param("id"))
+	if err != nil {
+		return 0, apperror.ErrBadRequest.WithMessage("ID không hợp lệ")
+	}
+	return h.savedViewService.ResolveID(c.Request.Context(), publicID)
+}
+
+// parseSavedViewQuery parses the list parameters of a view the same way the list endpoint
+// of its resource does
+func parseSavedViewQuery(resource, rawQuery string) (query.QueryOptions, error) {
+	res, ok := savedViewResources[resource]
+	if !ok {
+		return query.QueryOptions{}, apperror.ErrValidation.WithMessage("Tài nguyên không hỗ trợ bộ lọc đã lưu")
+	}
+
+	values, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return query.QueryOptions{}, apperror.ErrValidation.WithMessage("Chuỗi truy vấn không hợp lệ")
+	}
+	params := make(map[string]string)
+	for key, v := range values {
+		if len(v) > 0 {
+			params[key] = v[0]
+		}
+	}
+
+	opts, err := query.ParseQueryParams(params, res.schema)
+	if err != nil {
+		return query.QueryOptions{}, err
+	}
+	if err := query.ParseProjectionParams(params, res.projection, &opts); err != nil {
+		return query.QueryOptions{}, err
+	}
+	return opts, nil
+}
+
+// applySavedView merges the view given by view=<id>, if any, into opts parsed from the
+// request; explicit parameters take precedence over the stored ones
+func applySavedView(c *gin.Context, views service.SavedViewService, resource string, params map[string]string, opts query.QueryOptions) (query.QueryOptions, error) {
+	raw, ok := params["view"]
+	if !ok || raw == "" {
+		return opts, nil
+	}
+
+	publicID, err := entity.ParsePublicID(raw)
+	if err != nil {
+		return query.QueryOptions{}, apperror.ErrValidation.WithFields([]apperror.FieldError{
+			{Field: "view", Message: "ID bộ lọc đã lưu không hợp lệ"},
+		})
+	}
+	id, err := views.ResolveID(c.Request.Context(), publicID)
+	if err != nil {
+		return query.QueryOptions{}, err
+	}
+
+	view, err := views.Resolve(c.Request.Context(), id, resource)
+	if err != nil {
+		return query.QueryOptions{}, err
+	}
+
+	// Stored options are re-checked, since the schema may have changed since they were saved
+	stored, err := query.ValidateOptions(view.Options, savedViewResources[resource].schema)
+	if err != nil {
+		return query.QueryOptions{}, err
+	}
+	return query.MergeOptions(stored, opts), nil
+}
+
+func toSavedViewResponse(v *entity.SavedView) dto.SavedViewResponse {
+	return dto.SavedViewResponse{
+		ID:        v.PublicID.String(),
+		OwnerID:   v.OwnerPublicID.String(),
+		Resource:  v.Resource,
+		Name:      v.Name,
+		Shared:    v.Shared,
+		Options:   v.Options,
+		CreatedAt: v.CreatedAt,
+		UpdatedAt: v.UpdatedAt,
+	}
+}
@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/thienel/go-backend-template/internal/domain/entity"
+	"github.com/thienel/go-backend-template/internal/domain/repository"
+	"github.com/thienel/go-backend-template/internal/interface/api/dto"
+	"github.com/thienel/go-backend-template/internal/usecase/service"
+	apperror "github.com/thienel/go-backend-template/pkg/error"
+	"github.com/thienel/go-backend-template/pkg/query"
+	"github.com/thienel/go-backend-template/pkg/response"
+)
+
+const (
+	bulkModeAtomic     = "atomic"
+	bulkModeBestEffort = "best_effort"
+)
+
+func (h *userHandlerImpl) Bulk(c *gin.Context) {
+	var req dto.BulkUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.WriteErrorResponse(c, apperror.ErrValidation.WithMessage("Dữ liệu không hợp lệ"))
+		return
+	}
+
+	if len(req.IDs) > 0 && len(req.Filter) > 0 {
+		response.WriteErrorResponse(c, apperror.ErrValidation.WithMessage("Chỉ được chọn danh sách ID hoặc điều kiện lọc"))
+		return
+	}
+
+	ids := make([]entity.PublicID, len(req.IDs))
+	for i, raw := range req.IDs {
+		id, err := entity.ParsePublicID(raw)
+		if err != nil {
+			response.WriteErrorResponse(c, apperror.ErrValidation.WithMessage(fmt.Sprintf("ID %q không hợp lệ", raw)))
+			return
+		}
+		ids[i] = id
+	}
+
+	filter, err := query.ParseQueryParams(req.Filter, repository.UserQuerySchema)
+	if err != nil {
+		response.WriteErrorResponse(c, err)
+		return
+	}
+
+	mode := req.Mode
+	if mode == "" {
+		mode = bulkModeAtomic
+	}
+
+	report, err := h.userService.Bulk(c.Request.Context(), service.BulkUserCommand{
+		IDs:    ids,
+		Filter: filter,
+		Action: req.Action,
+		Role:   req.Role,
+		Reason: req.Reason,
+		Atomic: mode == bulkModeAtomic,
+	})
+	if err != nil {
+		response.WriteErrorResponse(c, err)
+		return
+	}
+
+	items := make([]dto.BulkUserItemResponse, len(report.Items))
+	for i, item := range report.Items {
+		items[i] = dto.BulkUserItemResponse{
+			ID:     item.ID.String(),
+			Status: item.Status,
+		}
+		if item.Error != nil {
+			items[i].ErrorCode, items[i].ErrorMessage = describeError(item.Error)
+		}
+	}
+
+	response.OK(c, dto.BulkUserResponse{
+		Action:     report.Action,
+		Mode:       mode,
+		Total:      report.Total,
+		Succeeded:  report.Succeeded,
+		Failed:     report.Failed,
+		RolledBack: report.RolledBack,
+		Items:      items,
+	}, "Thực hiện thao tác hàng loạt hoàn tất")
+}
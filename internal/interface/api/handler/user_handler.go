@@ -2,11 +2,11 @@ package handler
 
 import (
 	"net/http"
-	"strconv"
 
 	"github.com/gin-gonic/gin"
 
 	"github.com/thienel/go-backend-template/internal/domain/entity"
+	"github.com/thienel/go-backend-template/internal/domain/repository"
 	"github.com/thienel/go-backend-template/internal/interface/api/dto"
 	"github.com/thienel/go-backend-template/internal/usecase/service"
 	apperror "github.com/thienel/go-backend-template/pkg/error"
@@ -14,16 +14,6 @@ import (
 	"github.com/thienel/go-backend-template/pkg/response"
 )
 
-var userAllowedFields = map[string]bool{
-	"id":         true,
-	"username":   true,
-	"email":      true,
-	"role":       true,
-	"status":     true,
-	"created_at": true,
-	"search":     true,
-}
-
 // UserHandler interface
 type UserHandler interface {
 	List(c *gin.Context)
@@ -31,15 +21,33 @@ type UserHandler interface {
 	Create(c *gin.Context)
 	Update(c *gin.Context)
 	Delete(c *gin.Context)
+	Restore(c *gin.Context)
+	Purge(c *gin.Context)
+
+	// Bulk operations
+	Bulk(c *gin.Context)
+	Import(c *gin.Context)
+	Export(c *gin.Context)
+
+	// Status lifecycle
+	Activate(c *gin.Context)
+	Suspend(c *gin.Context)
+	Unsuspend(c *gin.Context)
+	Lock(c *gin.Context)
+	Unlock(c *gin.Context)
+	Deactivate(c *gin.Context)
+	Reactivate(c *gin.Context)
+	GetStatusHistory(c *gin.Context)
 }
 
 type userHandlerImpl struct {
-	userService service.UserService
+	userService      service.UserService
+	savedViewService service.SavedViewService
 }
 
 // NewUserHandler creates a new user handler
-func NewUserHandler(userService service.UserService) UserHandler {
-	return &userHandlerImpl{userService: userService}
+func NewUserHandler(userService service.UserService, savedViewService service.SavedViewService) UserHandler {
+	return &userHandlerImpl{userService: userService, savedViewService: savedViewService}
 }
 
 func (h *userHandlerImpl) List(c *gin.Context) {
@@ -50,46 +58,67 @@ func (h *userHandlerImpl) List(c *gin.Context) {
 		}
 	}
 
-	offset, limit := query.GetPagination(params, 20)
-	opts := query.ParseQueryParams(params, userAllowedFields)
-
-	users, total, err := h.userService.List(c.Request.Context(), offset, limit, opts)
+	pagination, err := query.ParsePagination(params, 20)
 	if err != nil {
 		response.WriteErrorResponse(c, err)
 		return
 	}
+	opts, err := query.ParseQueryParams(params, repository.UserQuerySchema)
+	if err != nil {
+		response.WriteErrorResponse(c, err)
+		return
+	}
+	if err := query.ParseProjectionParams(params, repository.UserProjection, &opts); err != nil {
+		response.WriteErrorResponse(c, err)
+		return
+	}
+	if opts, err = applySavedView(c, h.savedViewService, entity.SavedViewResourceUsers, params, opts); err != nil {
+		response.WriteErrorResponse(c, err)
+		return
+	}
 
-	items := make([]dto.UserResponse, len(users))
-	for i, u := range users {
-		items[i] = toUserResponse(&u)
+	page, err := h.userService.List(c.Request.Context(), pagination, opts)
+	if err != nil {
+		response.WriteErrorResponse(c, err)
+		return
 	}
 
-	page := (offset / limit) + 1
-	totalPages := int((total + int64(limit) - 1) / int64(limit))
+	resp := toListResponse(page, pagination, toUserResponse)
+	for i := range page.Highlights {
+		resp.Items[i].Highlights = page.Highlights[i]
+	}
 
-	response.OK(c, dto.ListResponse[dto.UserResponse]{
-		Items:      items,
-		Total:      total,
-		Page:       page,
-		Limit:      limit,
-		TotalPages: totalPages,
-	}, "")
+	writeListResponse(c, resp, opts)
 }
 
 func (h *userHandlerImpl) GetByID(c *gin.Context) {
-	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	id, err := h.userID(c)
 	if err != nil {
-		response.WriteErrorResponse(c, apperror.ErrBadRequest.WithMessage("ID không hợp lệ"))
+		response.WriteErrorResponse(c, err)
+		return
+	}
+
+	params := make(map[string]string)
+	for key, values := range c.Request.URL.Query() {
+		if len(values) > 0 {
+			params[key] = values[0]
+		}
+	}
+
+	opts := query.NewQueryOptions()
+	if err := query.ParseProjectionParams(params, repository.UserProjection, &opts); err != nil {
+		response.WriteErrorResponse(c, err)
 		return
 	}
 
-	user, err := h.userService.GetByID(c.Request.Context(), uint(id))
+	user, err := h.userService.GetByIDWithOptions(c.Request.Context(), id, opts)
 	if err != nil {
 		response.WriteErrorResponse(c, err)
 		return
 	}
 
-	response.OK(c, toUserResponse(user), "")
+	setETag(c, user.Version)
+	writeItemResponse(c, toUserResponse(user), opts)
 }
 
 func (h *userHandlerImpl) Create(c *gin.Context) {
@@ -104,19 +133,27 @@ func (h *userHandlerImpl) Create(c *gin.Context) {
 		Email:    req.Email,
 		Password: req.Password,
 		Role:     req.Role,
+		Status:   req.Status,
 	})
 	if err != nil {
 		response.WriteErrorResponse(c, err)
 		return
 	}
 
+	setETag(c, user.Version)
 	response.Created(c, toUserResponse(user), "Tạo người dùng thành công")
 }
 
 func (h *userHandlerImpl) Update(c *gin.Context) {
-	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	id, err := h.userID(c)
 	if err != nil {
-		response.WriteErrorResponse(c, apperror.ErrBadRequest.WithMessage("ID không hợp lệ"))
+		response.WriteErrorResponse(c, err)
+		return
+	}
+
+	version, err := requireIfMatch(c)
+	if err != nil {
+		response.WriteErrorResponse(c, err)
 		return
 	}
 
@@ -127,28 +164,35 @@ func (h *userHandlerImpl) Update(c *gin.Context) {
 	}
 
 	user, err := h.userService.Update(c.Request.Context(), service.UpdateUserCommand{
-		ID:       uint(id),
+		ID:       id,
 		Username: req.Username,
 		Email:    req.Email,
 		Role:     req.Role,
-		Status:   req.Status,
+		Version:  version,
 	})
 	if err != nil {
 		response.WriteErrorResponse(c, err)
 		return
 	}
 
+	setETag(c, user.Version)
 	response.OK(c, toUserResponse(user), "Cập nhật thành công")
 }
 
 func (h *userHandlerImpl) Delete(c *gin.Context) {
-	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	id, err := h.userID(c)
 	if err != nil {
-		response.WriteErrorResponse(c, apperror.ErrBadRequest.WithMessage("ID không hợp lệ"))
+		response.WriteErrorResponse(c, err)
 		return
 	}
 
-	if err := h.userService.Delete(c.Request.Context(), uint(id)); err != nil {
+	version, err := requireIfMatch(c)
+	if err != nil {
+		response.WriteErrorResponse(c, err)
+		return
+	}
+
+	if err := h.userService.Delete(c.Request.Context(), service.DeleteUserCommand{ID: id, Version: version}); err != nil {
 		response.WriteErrorResponse(c, err)
 		return
 	}
@@ -156,18 +200,84 @@ func (h *userHandlerImpl) Delete(c *gin.Context) {
 	c.Status(http.StatusNoContent)
 }
 
+func (h *userHandlerImpl) Restore(c *gin.Context) {
+	id, err := h.userID(c)
+	if err != nil {
+		response.WriteErrorResponse(c, err)
+		return
+	}
+
+	user, err := h.userService.Restore(c.Request.Context(), id)
+	if err != nil {
+		response.WriteErrorResponse(c, err)
+		return
+	}
+
+	response.OK(c, toUserResponse(user), "Khôi phục người dùng thành công")
+}
+
+func (h *userHandlerImpl) Purge(c *gin.Context) {
+	id, err := h.userID(c)
+	if err != nil {
+		response.WriteErrorResponse(c, err)
+		return
+	}
+
+	version, err := requireIfMatch(c)
+	if err != nil {
+		response.WriteErrorResponse(c, err)
+		return
+	}
+
+	if err := h.userService.Purge(c.Request.Context(), service.PurgeUserCommand{ID: id, Version: version}); err != nil {
+		response.WriteErrorResponse(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// userID resolves the public ID in the path to the ID of the user
+func (h *userHandlerImpl) userID(c *gin.Context) (uint, error) {
+	publicID, err := entity.ParsePublicID(c.Param("id"))
+	if err != nil {
+		return 0, apperror.ErrBadRequest.WithMessage("ID không hợp lệ")
+	}
+	return h.userService.ResolveID(c.Request.Context(), publicID)
+}
+
+// publicIDString returns the textual form of an optional public ID
+func publicIDString(id *entity.PublicID) *string {
+	if id == nil {
+		return nil
+	}
+	s := id.String()
+	return &s
+}
+
 func toUserResponse(user *entity.User) dto.UserResponse {
 	resp := dto.UserResponse{
-		ID:        user.ID,
-		Username:  user.Username,
-		Email:     user.Email,
-		Role:      user.Role,
-		Status:    user.Status,
-		CreatedAt: user.CreatedAt,
-		UpdatedAt: user.UpdatedAt,
+		ID:             user.PublicID.String(),
+		Username:       user.Username,
+		Email:          user.Email,
+		Role:           user.Role,
+		Status:         user.Status,
+		StatusReason:   user.StatusReason,
+		SuspendedUntil: user.SuspendedUntil,
+		LastLoginAt:    user.LastLoginAt,
+		LastLoginIP:    user.LastLoginIP,
+		CreatedAt:      user.CreatedAt,
+		UpdatedAt:      user.UpdatedAt,
+		Version:        user.Version,
 	}
 	if user.DeletedAt.Valid {
 		resp.DeletedAt = &user.DeletedAt.Time
 	}
+	if user.StatusHistory != nil {
+		resp.StatusHistory = make([]dto.UserStatusHistoryResponse, len(user.StatusHistory))
+		for i := range user.StatusHistory {
+			resp.StatusHistory[i] = toUserStatusHistoryResponse(&user.StatusHistory[i])
+		}
+	}
 	return resp
 }
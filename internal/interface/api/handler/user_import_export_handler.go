@@ -0,0 +1,438 @@
+package handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/thienel/tlog"
+	"go.uber.org/zap"
+
+	"github.com/thienel/go-backend-template/internal/domain/entity"
+	"github.com/thienel/go-backend-template/internal/domain/repository"
+	"github.com/thienel/go-backend-template/internal/interface/api/dto"
+	"github.com/thienel/go-backend-template/internal/usecase/service"
+	apperror "github.com/thienel/go-backend-template/pkg/error"
+	"github.com/thienel/go-backend-template/pkg/query"
+	"github.com/thienel/go-backend-template/pkg/response"
+)
+
+const (
+	formatCSV  = "csv"
+	formatJSON = "json"
+
+	maxImportBodyBytes = 10 << 20
+
+	// Imports and exports outlast the server timeouts, which are sized for ordinary requests
+	importTimeout = 5 * time.Minute
+	exportTimeout = 15 * time.Minute
+)
+
+var userExportColumns = []string{
+	"id", "username", "email", "role", "status", "last_login_at", "created_at", "updated_at",
+}
+
+func (h *userHandlerImpl) Import(c *gin.Context) {
+	extendDeadlines(c, importTimeout)
+
+	format := c.Query("format")
+	if format == "" {
+		format = formatFromContentType(c.ContentType())
+	}
+
+	dryRun, _ := strconv.ParseBool(c.Query("dry_run"))
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, maxImportBodyBytes)
+
+	var source service.ImportUserSource
+	switch format {
+	case formatCSV:
+		csvSource, err := newCSVUserSource(body)
+		if err != nil {
+			response.WriteErrorResponse(c, apperror.ErrBadRequest.WithMessage("Tệp CSV không hợp lệ").WithError(err))
+			return
+		}
+		source = csvSource
+	case formatJSON:
+		jsonSource, err := newJSONUserSource(body)
+		if err != nil {
+			response.WriteErrorResponse(c, apperror.ErrBadRequest.WithMessage("Dữ liệu JSON không hợp lệ").WithError(err))
+			return
+		}
+		source = jsonSource
+	default:
+		response.WriteErrorResponse(c, apperror.ErrBadRequest.WithMessage("Định dạng không được hỗ trợ"))
+		return
+	}
+
+	report, err := h.userService.Import(c.Request.Context(), source, dryRun)
+	if err != nil {
+		response.WriteErrorResponse(c, err)
+		return
+	}
+
+	rows := make([]dto.ImportUserRowResponse, len(report.Rows))
+	for i, r := range report.Rows {
+		rows[i] = dto.ImportUserRowResponse{
+			Line:     r.Line,
+			Username: r.Username,
+			Success:  r.Error == nil,
+		}
+		// Dry runs create no users, so there is no ID to report
+		if !r.UserID.IsZero() {
+			rows[i].UserID = r.UserID.String()
+		}
+		if r.Error != nil {
+			rows[i].ErrorCode, rows[i].ErrorMessage = describeError(r.Error)
+		}
+	}
+
+	message := "Nhập người dùng hoàn tất"
+	if dryRun {
+		message = "Kiểm tra dữ liệu nhập hoàn tất"
+	}
+
+	response.OK(c, dto.ImportUsersResponse{
+		DryRun:    report.DryRun,
+		Total:     report.Total,
+		Succeeded: report.Succeeded,
+		Failed:    report.Failed,
+		Rows:      rows,
+	}, message)
+}
+
+func (h *userHandlerImpl) Export(c *gin.Context) {
+	extendDeadlines(c, exportTimeout)
+
+	params := make(map[string]string)
+	for key, values := range c.Request.URL.Query() {
+		if len(values) > 0 {
+			params[key] = values[0]
+		}
+	}
+
+	format := params["format"]
+	if format == "" {
+		format = formatCSV
+	}
+	if format != formatCSV && format != formatJSON {
+		response.WriteErrorResponse(c, apperror.ErrBadRequest.WithMessage("Định dạng không được hỗ trợ"))
+		return
+	}
+
+	opts, err := query.ParseQueryParams(params, repository.UserQuerySchema)
+	if err != nil {
+		response.WriteErrorResponse(c, err)
+		return
+	}
+
+	var writer userExportWriter
+	if format == formatCSV {
+		writer = &csvUserExportWriter{writer: csv.NewWriter(c.Writer)}
+	} else {
+		writer = &jsonUserExportWriter{writer: c.Writer}
+	}
+
+	// Headers are sent lazily so that an error before the first batch still gets a JSON error response
+	started := false
+	start := func() error {
+		started = true
+		filename := fmt.Sprintf("users-%s.%s", time.Now().Format("20060102-150405"), format)
+		c.Header("Content-Disposition", "attachment; filename="+filename)
+		c.Header("Content-Type", writer.contentType())
+		c.Status(http.StatusOK)
+		return writer.begin()
+	}
+
+	err = h.userService.Export(c.Request.Context(), opts, func(users []entity.User) error {
+		if !started {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+
+		for i := range users {
+			if err := writer.write(&users[i]); err != nil {
+				return err
+			}
+		}
+		return writer.flush(c.Writer)
+	})
+	if err != nil && !started {
+		response.WriteErrorResponse(c, err)
+		return
+	}
+
+	if err == nil && !started {
+		err = start()
+	}
+	if err == nil {
+		err = writer.end()
+	}
+	if err == nil {
+		err = writer.flush(c.Writer)
+	}
+	if err != nil {
+		// Headers are already sent; the truncated body is all the client will get
+		tlog.Error("User export aborted", zap.Error(err))
+	}
+}
+
+// extendDeadlines replaces the server read and write timeouts of the request, so that a
+// long import or export is neither cut off mid-body nor loses its response
+func extendDeadlines(c *gin.Context, timeout time.Duration) {
+	deadline := time.Now().Add(timeout)
+	rc := http.NewResponseController(c.Writer)
+	if err := rc.SetReadDeadline(deadline); err != nil {
+		tlog.Warn("Failed to extend read deadline", zap.Error(err))
+	}
+	if err := rc.SetWriteDeadline(deadline); err != nil {
+		tlog.Warn("Failed to extend write deadline", zap.Error(err))
+	}
+}
+
+// newImportRow validates a decoded row with the same binding rules as POST /api/users
+func newImportRow(line int, req dto.CreateUserRequest) *service.ImportUserRow {
+	row := &service.ImportUserRow{
+		Line: line,
+		Command: service.CreateUserCommand{
+			Username: req.Username,
+			Email:    req.Email,
+			Password: req.Password,
+			Role:     req.Role,
+			Status:   req.Status,
+		},
+	}
+
+	if err := binding.Validator.ValidateStruct(&req); err != nil {
+		row.Err = apperror.ErrValidation.WithMessage(validationMessage(err)).WithError(err)
+	}
+	return row
+}
+
+func validationMessage(err error) string {
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return "Dữ liệu không hợp lệ"
+	}
+
+	fields := make([]string, len(validationErrs))
+	for i, fe := range validationErrs {
+		fields[i] = strings.ToLower(fe.Field())
+	}
+	return "Dữ liệu không hợp lệ: " + strings.Join(fields, ", ")
+}
+
+func describeError(err error) (code, message string) {
+	var appErr *apperror.AppError
+	if errors.As(err, &appErr) {
+		return appErr.Code, appErr.Message
+	}
+	return apperror.ErrInternalServerError.Code, apperror.ErrInternalServerError.Message
+}
+
+func formatFromContentType(contentType string) string {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "text/csv", "application/csv":
+		return formatCSV
+	case "application/json":
+		return formatJSON
+	default:
+		return ""
+	}
+}
+
+// csvUserSource streams import rows from a CSV file with a header row
+type csvUserSource struct {
+	reader  *csv.Reader
+	columns map[string]int
+}
+
+func newCSVUserSource(r io.Reader) (*csvUserSource, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		// Spreadsheet exports often start with a UTF-8 byte order mark
+		name = strings.TrimPrefix(name, "\ufeff")
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"username", "email", "password"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("missing column %q", required)
+		}
+	}
+
+	return &csvUserSource{reader: reader, columns: columns}, nil
+}
+
+func (s *csvUserSource) Next() (*service.ImportUserRow, error) {
+	record, err := s.reader.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	line, _ := s.reader.FieldPos(0)
+	return newImportRow(line, dto.CreateUserRequest{
+		Username: s.field(record, "username"),
+		Email:    s.field(record, "email"),
+		Password: s.field(record, "password"),
+		Role:     s.field(record, "role"),
+		Status:   s.field(record, "status"),
+	}), nil
+}
+
+func (s *csvUserSource) field(record []string, name string) string {
+	i, ok := s.columns[name]
+	if !ok || i >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[i])
+}
+
+// jsonUserSource streams import rows from a JSON array of users
+type jsonUserSource struct {
+	decoder *json.Decoder
+	index   int
+}
+
+func newJSONUserSource(r io.Reader) (*jsonUserSource, error) {
+	decoder := json.NewDecoder(r)
+
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '[' {
+		return nil, errors.New("expected a JSON array")
+	}
+
+	return &jsonUserSource{decoder: decoder}, nil
+}
+
+func (s *jsonUserSource) Next() (*service.ImportUserRow, error) {
+	if !s.decoder.More() {
+		return nil, io.EOF
+	}
+
+	s.index++
+	var req dto.CreateUserRequest
+	if err := s.decoder.Decode(&req); err != nil {
+		return nil, fmt.Errorf("item %d: %w", s.index, err)
+	}
+	return newImportRow(s.index, req), nil
+}
+
+// userExportWriter encodes exported users in a streaming format
+type userExportWriter interface {
+	contentType() string
+	begin() error
+	write(user *entity.User) error
+	end() error
+	flush(w gin.ResponseWriter) error
+}
+
+type csvUserExportWriter struct {
+	writer *csv.Writer
+}
+
+func (w *csvUserExportWriter) contentType() string { return "text/csv; charset=utf-8" }
+
+func (w *csvUserExportWriter) begin() error {
+	return w.writer.Write(userExportColumns)
+}
+
+func (w *csvUserExportWriter) write(user *entity.User) error {
+	lastLoginAt := ""
+	if user.LastLoginAt != nil {
+		lastLoginAt = user.LastLoginAt.Format(time.RFC3339)
+	}
+
+	return w.writer.Write(csvCells(
+		user.PublicID.String(),
+		user.Username,
+		user.Email,
+		user.Role,
+		user.Status,
+		lastLoginAt,
+		user.CreatedAt.Format(time.RFC3339),
+		user.UpdatedAt.Format(time.RFC3339),
+	))
+}
+
+// csvCells returns the CSV cells of values, quoting values that spreadsheets would run as
+// formulas with a leading ' (CSV injection)
+func csvCells(values ...string) []string {
+	for i, value := range values {
+		if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+			values[i] = "'" + value
+		}
+	}
+	return values
+}
+
+func (w *csvUserExportWriter) end() error { return nil }
+
+func (w *csvUserExportWriter) flush(rw gin.ResponseWriter) error {
+	w.writer.Flush()
+	if err := w.writer.Error(); err != nil {
+		return err
+	}
+	rw.Flush()
+	return nil
+}
+
+type jsonUserExportWriter struct {
+	writer  io.Writer
+	written int
+}
+
+func (w *jsonUserExportWriter) contentType() string { return "application/json; charset=utf-8" }
+
+func (w *jsonUserExportWriter) begin() error {
+	_, err := io.WriteString(w.writer, "[")
+	return err
+}
+
+func (w *jsonUserExportWriter) write(user *entity.User) error {
+	if w.written > 0 {
+		if _, err := io.WriteString(w.writer, ","); err != nil {
+			return err
+		}
+	}
+	w.written++
+
+	data, err := json.Marshal(toUserResponse(user))
+	if err != nil {
+		return err
+	}
+	_, err = w.writer.Write(data)
+	return err
+}
+
+func (w *jsonUserExportWriter) end() error {
+	_, err := io.WriteString(w.writer, "]")
+	return err
+}
+
+func (w *jsonUserExportWriter) flush(rw gin.ResponseWriter) error {
+	rw.Flush()
+	return nil
+}
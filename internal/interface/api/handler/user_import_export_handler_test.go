@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+	"time"
+
+	"github.com/thienel/go-backend-template/internal/domain/entity"
+)
+
+func TestCSVUserExportWriterEscapesFormulas(t *testing.T) {
+	tests := []struct {
+		username string
+		want     string
+	}{
+		{username: "=HYPERLINK(\"http://example.com\")", want: "'=HYPERLINK(\"http://example.com\")"},
+		{username: "+1", want: "'+1"},
+		{username: "-1", want: "'-1"},
+		{username: "@SUM(A1)", want: "'@SUM(A1)"},
+		{username: "\tname", want: "'\tname"},
+		{username: "\rname", want: "'\rname"},
+		{username: "alice", want: "alice"},
+		{username: "a=b", want: "a=b"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.username, func(t *testing.T) {
+			var buf bytes.Buffer
+			w := &csvUserExportWriter{writer: csv.NewWriter(&buf)}
+			user := &entity.User{Username: tt.username, Email: "alice@example.com", CreatedAt: time.Now(), UpdatedAt: time.Now()}
+			if err := w.write(user); err != nil {
+				t.Fatalf("write: %v", err)
+			}
+			w.writer.Flush()
+
+			record, err := csv.NewReader(&buf).Read()
+			if err != nil {
+				t.Fatalf("read: %v", err)
+			}
+			if record[1] != tt.want {
+				t.Errorf("username cell = %q, want %q", record[1], tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/thienel/go-backend-template/internal/domain/entity"
+	"github.com/thienel/go-backend-template/internal/interface/api/dto"
+	"github.com/thienel/go-backend-template/internal/usecase/service"
+	apperror "github.com/thienel/go-backend-template/pkg/error"
+	"github.com/thienel/go-backend-template/pkg/query"
+	"github.com/thienel/go-backend-template/pkg/response"
+)
+
+type statusChangeFunc func(ctx context.Context, cmd service.ChangeUserStatusCommand) (*entity.User, error)
+
+func (h *userHandlerImpl) Activate(c *gin.Context) {
+	h.changeStatus(c, h.userService.Activate, "Kích hoạt người dùng thành công")
+}
+
+func (h *userHandlerImpl) Suspend(c *gin.Context) {
+	id, err := h.userID(c)
+	if err != nil {
+		response.WriteErrorResponse(c, err)
+		return
+	}
+
+	var req dto.SuspendUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.WriteErrorResponse(c, apperror.ErrValidation.WithMessage("Dữ liệu không hợp lệ"))
+		return
+	}
+
+	user, err := h.userService.Suspend(c.Request.Context(), service.SuspendUserCommand{
+		ID:     id,
+		Reason: req.Reason,
+		Until:  req.Until,
+	})
+	if err != nil {
+		response.WriteErrorResponse(c, err)
+		return
+	}
+
+	response.OK(c, toUserResponse(user), "Tạm ngưng người dùng thành công")
+}
+
+func (h *userHandlerImpl) Unsuspend(c *gin.Context) {
+	h.changeStatus(c, h.userService.Unsuspend, "Bỏ tạm ngưng người dùng thành công")
+}
+
+func (h *userHandlerImpl) Lock(c *gin.Context) {
+	h.changeStatus(c, h.userService.Lock, "Khóa người dùng thành công")
+}
+
+func (h *userHandlerImpl) Unlock(c *gin.Context) {
+	h.changeStatus(c, h.userService.Unlock, "Mở khóa người dùng thành công")
+}
+
+func (h *userHandlerImpl) Deactivate(c *gin.Context) {
+	h.changeStatus(c, h.userService.Deactivate, "Vô hiệu hóa người dùng thành công")
+}
+
+func (h *userHandlerImpl) Reactivate(c *gin.Context) {
+	h.changeStatus(c, h.userService.Reactivate, "Kích hoạt lại người dùng thành công")
+}
+
+func (h *userHandlerImpl) GetStatusHistory(c *gin.Context) {
+	id, err := h.userID(c)
+	if err != nil {
+		response.WriteErrorResponse(c, err)
+		return
+	}
+
+	params := make(map[string]string)
+	for key, values := range c.Request.URL.Query() {
+		if len(values) > 0 {
+			params[key] = values[0]
+		}
+	}
+
+	pagination, err := query.ParsePagination(params, 20)
+	if err != nil {
+		response.WriteErrorResponse(c, err)
+		return
+	}
+
+	page, err := h.userService.GetStatusHistory(c.Request.Context(), id, pagination)
+	if err != nil {
+		response.WriteErrorResponse(c, err)
+		return
+	}
+
+	response.OK(c, toListResponse(page, pagination, toUserStatusHistoryResponse), "")
+}
+
+func toUserStatusHistoryResponse(history *entity.UserStatusHistory) dto.UserStatusHistoryResponse {
+	return dto.UserStatusHistoryResponse{
+		ID:             history.PublicID.String(),
+		Transition:     history.Transition,
+		FromStatus:     history.FromStatus,
+		ToStatus:       history.ToStatus,
+		Reason:         history.Reason,
+		SuspendedUntil: history.SuspendedUntil,
+		ActorID:        publicIDString(history.ActorPublicID),
+		CreatedAt:      history.CreatedAt,
+	}
+}
+
+// changeStatus handles transitions that take an optional reason in the request body
+func (h *userHandlerImpl) changeStatus(c *gin.Context, change statusChangeFunc, message string) {
+	id, err := h.userID(c)
+	if err != nil {
+		response.WriteErrorResponse(c, err)
+		return
+	}
+
+	var req dto.ChangeUserStatusRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.WriteErrorResponse(c, apperror.ErrValidation.WithMessage("Dữ liệu không hợp lệ"))
+			return
+		}
+	}
+
+	user, err := change(c.Request.Context(), service.ChangeUserStatusCommand{
+		ID:     id,
+		Reason: req.Reason,
+	})
+	if err != nil {
+		response.WriteErrorResponse(c, err)
+		return
+	}
+
+	response.OK(c, toUserResponse(user), message)
+}
@@ -0,0 +1,251 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/thienel/go-backend-template/internal/domain/entity"
+	"github.com/thienel/go-backend-template/internal/domain/repository"
+	"github.com/thienel/go-backend-template/internal/interface/api/dto"
+	"github.com/thienel/go-backend-template/internal/usecase/service"
+	apperror "github.com/thienel/go-backend-template/pkg/error"
+	"github.com/thienel/go-backend-template/pkg/query"
+	"github.com/thienel/go-backend-template/pkg/response"
+)
+
+// WebhookHandler interface
+type WebhookHandler interface {
+	List(c *gin.Context)
+	GetByID(c *gin.Context)
+	Create(c *gin.Context)
+	Update(c *gin.Context)
+	Delete(c *gin.Context)
+	ListDeliveries(c *gin.Context)
+	Redeliver(c *gin.Context)
+}
+
+type webhookHandlerImpl struct {
+	webhookService service.WebhookService
+}
+
+// NewWebhookHandler creates a new webhook handler
+func NewWebhookHandler(webhookService service.WebhookService) WebhookHandler {
+	return &webhookHandlerImpl{webhookService: webhookService}
+}
+
+func (h *webhookHandlerImpl) List(c *gin.Context) {
+	params := make(map[string]string)
+	for key, values := range c.Request.URL.Query() {
+		if len(values) > 0 {
+			params[key] = values[0]
+		}
+	}
+
+	pagination, err := query.ParsePagination(params, 20)
+	if err != nil {
+		response.WriteErrorResponse(c, err)
+		return
+	}
+	opts, err := query.ParseQueryParams(params, repository.WebhookSubscriptionQuerySchema)
+	if err != nil {
+		response.WriteErrorResponse(c, err)
+		return
+	}
+
+	page, err := h.webhookService.List(c.Request.Context(), pagination, opts)
+	if err != nil {
+		response.WriteErrorResponse(c, err)
+		return
+	}
+
+	response.OK(c, toListResponse(page, pagination, toWebhookResponse), "")
+}
+
+func (h *webhookHandlerImpl) GetByID(c *gin.Context) {
+	id, err := h.subscriptionID(c)
+	if err != nil {
+		response.WriteErrorResponse(c, err)
+		return
+	}
+
+	subscription, err := h.webhookService.GetByID(c.Request.Context(), id)
+	if err != nil {
+		response.WriteErrorResponse(c, err)
+		return
+	}
+
+	response.OK(c, toWebhookResponse(subscription), "")
+}
+
+func (h *webhookHandlerImpl) Create(c *gin.Context) {
+	var req dto.CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.WriteErrorResponse(c, apperror.ErrValidation.WithMessage("Dữ liệu không hợp lệ"))
+		return
+	}
+
+	subscription, err := h.webhookService.Create(c.Request.Context(), service.CreateWebhookCommand{
+		URL:         req.URL,
+		EventTypes:  req.EventTypes,
+		Secret:      req.Secret,
+		Description: req.Description,
+		Active:      req.Active,
+	})
+	if err != nil {
+		response.WriteErrorResponse(c, err)
+		return
+	}
+
+	// The secret is shown once, so that the receiver can verify signatures
+	resp := toWebhookResponse(subscription)
+	resp.Secret = subscription.Secret
+	response.Created(c, resp, "Tạo webhook thành công")
+}
+
+func (h *webhookHandlerImpl) Update(c *gin.Context) {
+	id, err := h.subscriptionID(c)
+	if err != nil {
+		response.WriteErrorResponse(c, err)
+		return
+	}
+
+	var req dto.UpdateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.WriteErrorResponse(c, apperror.ErrValidation.WithMessage("Dữ liệu không hợp lệ"))
+		return
+	}
+
+	subscription, err := h.webhookService.Update(c.Request.Context(), service.UpdateWebhookCommand{
+		ID:          id,
+		URL:         req.URL,
+		EventTypes:  req.EventTypes,
+		Secret:      req.Secret,
+		Description: req.Description,
+		Active:      req.Active,
+	})
+	if err != nil {
+		response.WriteErrorResponse(c, err)
+		return
+	}
+
+	response.OK(c, toWebhookResponse(subscription), "Cập nhật thành công")
+}
+
+func (h *webhookHandlerImpl) Delete(c *gin.Context) {
+	id, err := h.subscriptionID(c)
+	if err != nil {
+		response.WriteErrorResponse(c, err)
+		return
+	}
+
+	if err := h.webhookService.Delete(c.Request.Context(), id); err != nil {
+		response.WriteErrorResponse(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *webhookHandlerImpl) ListDeliveries(c *gin.Context) {
+	id, err := h.subscriptionID(c)
+	if err != nil {
+		response.WriteErrorResponse(c, err)
+		return
+	}
+
+	params := make(map[string]string)
+	for key, values := range c.Request.URL.Query() {
+		if len(values) > 0 {
+			params[key] = values[0]
+		}
+	}
+
+	pagination, err := query.ParsePagination(params, 20)
+	if err != nil {
+		response.WriteErrorResponse(c, err)
+		return
+	}
+	opts, err := query.ParseQueryParams(params, repository.WebhookDeliveryQuerySchema)
+	if err != nil {
+		response.WriteErrorResponse(c, err)
+		return
+	}
+
+	page, err := h.webhookService.ListDeliveries(c.Request.Context(), id, pagination, opts)
+	if err != nil {
+		response.WriteErrorResponse(c, err)
+		return
+	}
+
+	writeListResponse(c, toListResponse(page, pagination, toWebhookDeliveryResponse), opts)
+}
+
+func (h *webhookHandlerImpl) Redeliver(c *gin.Context) {
+	id, err := h.subscriptionID(c)
+	if err != nil {
+		response.WriteErrorResponse(c, err)
+		return
+	}
+	deliveryID, err := h.deliveryID(c)
+	if err != nil {
+		response.WriteErrorResponse(c, err)
+		return
+	}
+
+	delivery, err := h.webhookService.Redeliver(c.Request.Context(), id, deliveryID)
+	if err != nil {
+		response.WriteErrorResponse(c, err)
+		return
+	}
+
+	response.OK(c, toWebhookDeliveryResponse(delivery), "Đã xếp lịch gửi lại webhook")
+}
+
+// subscriptionID resolves the public ID in the path to the ID of the subscription
+func (h *webhookHandlerImpl) subscriptionID(c *gin.Context) (uint, error) {
+	publicID, err := entity.ParsePublicID(c.Param("id"))
+	if err != nil {
+		return 0, apperror.ErrBadRequest.WithMessage("ID không hợp lệ")
+	}
+	return h.webhookService.ResolveID(c.Request.Context(), publicID)
+}
+
+// deliveryID resolves the public ID in the path to the ID of the delivery
+func (h *webhookHandlerImpl) deliveryID(c *gin.Context) (uint, error) {
+	publicID, err := entity.ParsePublicID(c.Param("deliveryId"))
+	if err != nil {
+		return 0, apperror.ErrBadRequest.WithMessage("ID lượt gửi không hợp lệ")
+	}
+	return h.webhookService.ResolveDeliveryID(c.Request.Context(), publicID)
+}
+
+func toWebhookResponse(s *entity.WebhookSubscription) dto.WebhookResponse {
+	return dto.WebhookResponse{
+		ID:          s.PublicID.String(),
+		URL:         s.URL,
+		EventTypes:  s.EventTypes,
+		Description: s.Description,
+		Active:      s.Active,
+		CreatedAt:   s.CreatedAt,
+		UpdatedAt:   s.UpdatedAt,
+	}
+}
+
+func toWebhookDeliveryResponse(d *entity.WebhookDelivery) dto.WebhookDeliveryResponse {
+	return dto.WebhookDeliveryResponse{
+		ID:             d.PublicID.String(),
+		SubscriptionID: d.SubscriptionPublicID.String(),
+		EventName:      d.EventName,
+		Payload:        d.Payload,
+		Status:         d.Status,
+		Attempts:       d.Attempts,
+		NextAttemptAt:  d.NextAttemptAt,
+		ResponseCode:   d.ResponseCode,
+		ResponseBody:   d.ResponseBody,
+		LastError:      d.LastError,
+		DurationMs:     d.DurationMs,
+		DeliveredAt:    d.DeliveredAt,
+		CreatedAt:      d.CreatedAt,
+	}
+}
@@ -31,8 +31,35 @@ func (m *Middleware) Auth() gin.HandlerFunc {
 			c.Abort()
 			return
 		}
+		user, err := m.authService.Authorize(c.Request.Context(), claims.UserID)
+		if err != nil {
+			response.WriteErrorResponse(c, err)
+			c.Abort()
+			return
+		}
 
 		c.Set(string(UserContextKey), claims)
+		setActor(c, claims, user)
+		c.Next()
+	}
+}
+
+// OptionalAuth returns middleware that identifies the user when a valid token is present,
+// without rejecting anonymous requests
+func (m *Middleware) OptionalAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := getTokenFromHeader(c.GetHeader("Authorization"))
+		if token != "" {
+			claims, err := m.jwtService.ValidateToken(token)
+			var user *entity.User
+			if err == nil {
+				user, err = m.authService.Authorize(c.Request.Context(), claims.UserID)
+			}
+			if err == nil {
+				c.Set(string(UserContextKey), claims)
+				setActor(c, claims, user)
+			}
+		}
 		c.Next()
 	}
 }
@@ -32,7 +32,8 @@ func (m *Middleware) CORS() gin.HandlerFunc {
 		}
 
 		headers := c.Writer.Header()
-		headers.Set("Access-Control-Allow-Headers", "Authorization, Content-Type, X-Requested-With, Accept, Origin")
+		headers.Set("Access-Control-Allow-Headers", "Authorization, Content-Type, X-Requested-With, Accept, Origin, If-Match")
+		headers.Set("Access-Control-Expose-Headers", "ETag")
 		headers.Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
 		headers.Set("Access-Control-Max-Age", "86400")
 
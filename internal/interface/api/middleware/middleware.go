@@ -9,13 +9,14 @@ import (
 // Middleware holds all middleware dependencies
 type Middleware struct {
 	jwtService     service.JWTService
+	authService    service.AuthService
 	origins        string
 	allowedOrigins []string
 	allowAll       bool
 }
 
 // New creates a new Middleware instance
-func New(jwtService service.JWTService, origins string) *Middleware {
+func New(jwtService service.JWTService, authService service.AuthService, origins string) *Middleware {
 	allowed := strings.Split(origins, ",")
 	allowAll := len(allowed) == 1 && strings.TrimSpace(allowed[0]) == "*"
 
@@ -25,6 +26,7 @@ func New(jwtService service.JWTService, origins string) *Middleware {
 
 	return &Middleware{
 		jwtService:     jwtService,
+		authService:    authService,
 		origins:        origins,
 		allowedOrigins: allowed,
 		allowAll:       allowAll,
@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/thienel/go-backend-template/internal/domain/entity"
+	"github.com/thienel/go-backend-template/internal/domain/repository"
+	"github.com/thienel/go-backend-template/internal/domain/valueobject"
+)
+
+const requestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength matches the request_id columns the ID is stored in
+const maxRequestIDLength = 64
+
+// RequestMeta returns middleware that attaches request metadata to the request context.
+// Requests that may write also read from the primary database, so that they see their
+// own writes.
+func (m *Middleware) RequestMeta() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// IDs from clients are kept only if they fit the columns that log them
+		requestID := c.GetHeader(requestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = newRequestID()
+			c.Request.Header.Set(requestIDHeader, requestID)
+		}
+		c.Writer.Header().Set(requestIDHeader, requestID)
+
+		ctx := valueobject.WithRequestMeta(c.Request.Context(), valueobject.RequestMeta{
+			RequestID: requestID,
+			IP:        c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+		})
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+		default:
+			ctx = repository.WithReadYourWrites(ctx)
+		}
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// setActor records the authenticated user in the request metadata
+func setActor(c *gin.Context, claims *valueobject.JWTClaims, user *entity.User) {
+	ctx := c.Request.Context()
+	meta := valueobject.RequestMetaFromContext(ctx)
+	meta.ActorID = claims.UserID
+	meta.ActorPublicID = user.PublicID.String()
+	meta.ActorRole = claims.Role
+	c.Request = c.Request.WithContext(valueobject.WithRequestMeta(ctx, meta))
+}
+
+// validRequestID reports whether id is a non-empty request ID of at most
+// maxRequestIDLength letters, digits, dots, underscores and dashes
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '_', r == '-':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/thienel/go-backend-template/internal/domain/valueobject"
+)
+
+func TestRequestMetaRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name   string
+		header string
+		keep   bool
+	}{
+		{name: "valid", header: "req-01.abc_DEF", keep: true},
+		{name: "longest", header: strings.Repeat("a", maxRequestIDLength), keep: true},
+		{name: "missing"},
+		{name: "oversized", header: strings.Repeat("a", maxRequestIDLength+1)},
+		{name: "invalid characters", header: "abc def;drop"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			engine := gin.New()
+			engine.Use((&Middleware{}).RequestMeta())
+			engine.GET("/", func(c *gin.Context) {
+				got = valueobject.RequestMetaFromContext(c.Request.Context()).RequestID
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				req.Header.Set(requestIDHeader, tt.header)
+			}
+			rec := httptest.NewRecorder()
+			engine.ServeHTTP(rec, req)
+
+			if tt.keep && got != tt.header {
+				t.Errorf("request ID = %q, want the header %q", got, tt.header)
+			}
+			if !tt.keep && (got == tt.header || !validRequestID(got)) {
+				t.Errorf("request ID = %q, want a generated one", got)
+			}
+			if rec.Header().Get(requestIDHeader) != got {
+				t.Errorf("response header = %q, want %q", rec.Header().Get(requestIDHeader), got)
+			}
+		})
+	}
+}
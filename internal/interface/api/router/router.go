@@ -9,31 +9,44 @@ import (
 )
 
 type routeRegister struct {
-	auth handler.AuthHandler
-	user handler.UserHandler
-	mw   *middleware.Middleware
+	auth      handler.AuthHandler
+	authEvent handler.AuthEventHandler
+	user      handler.UserHandler
+	savedView handler.SavedViewHandler
+	auditLog  handler.AuditLogHandler
+	webhook   handler.WebhookHandler
+	health    handler.HealthHandler
+	mw        *middleware.Middleware
 }
 
 // SetupRouter configures all routes following THD-Checkin-App pattern
 func SetupRouter(
 	authHandler handler.AuthHandler,
+	authEventHandler handler.AuthEventHandler,
 	userHandler handler.UserHandler,
+	savedViewHandler handler.SavedViewHandler,
+	auditLogHandler handler.AuditLogHandler,
+	webhookHandler handler.WebhookHandler,
+	healthHandler handler.HealthHandler,
 	mw *middleware.Middleware,
 ) *gin.Engine {
 
 	routes := routeRegister{
-		auth: authHandler,
-		user: userHandler,
-		mw:   mw,
+		auth:      authHandler,
+		authEvent: authEventHandler,
+		user:      userHandler,
+		savedView: savedViewHandler,
+		auditLog:  auditLogHandler,
+		webhook:   webhookHandler,
+		health:    healthHandler,
+		mw:        mw,
 	}
 
 	router := gin.New()
-	router.Use(gin.Recovery(), mw.CORS(), tlog.GinMiddleware(tlog.WithSkipPaths("/health")))
+	router.Use(gin.Recovery(), mw.CORS(), mw.RequestMeta(), tlog.GinMiddleware(tlog.WithSkipPaths("/health")))
 
-	// Health check
-	router.GET("/health", func(c *gin.Context) {
-		c.JSON(200, gin.H{"status": "ok"})
-	})
+	// Health check; metrics are served on their own listener
+	router.GET("/health", healthHandler.Health)
 
 	// Public API
 	api := router.Group("/api")
@@ -45,22 +58,38 @@ func SetupRouter(
 	protected := api.Group("", mw.Auth())
 	{
 		routes.registerUserRoutes(protected)
+		routes.registerAuthEventRoutes(protected)
+		routes.registerSavedViewRoutes(protected)
+		routes.registerAuditLogRoutes(protected)
+		routes.registerWebhookRoutes(protected)
 	}
 
 	return router
 }
 
+// SetupMetricsRouter configures the metrics endpoint, served on a separate port that is
+// not exposed publicly, since the statistics reveal the internals of the service
+func SetupMetricsRouter(healthHandler handler.HealthHandler) *gin.Engine {
+	router := gin.New()
+	router.Use(gin.Recovery())
+	router.GET("/metrics", healthHandler.Metrics)
+	return router
+}
+
 func (r *routeRegister) registerAuthRoutes(rg *gin.RouterGroup) {
 	auth := rg.Group("/auth")
 	{
 		auth.POST("/login", r.auth.Login)
-		auth.POST("/logout", r.auth.Logout)
+		auth.POST("/refresh", r.auth.Refresh)
+		auth.POST("/logout", r.mw.OptionalAuth(), r.auth.Logout)
 	}
 
 	// Protected auth routes
 	authProtected := auth.Group("", r.mw.Auth())
 	{
 		authProtected.GET("/me", r.auth.GetMe)
+		authProtected.GET("/me/events", r.authEvent.ListMine)
+		authProtected.POST("/change-password", r.auth.ChangePassword)
 	}
 }
 
@@ -68,9 +97,63 @@ func (r *routeRegister) registerUserRoutes(rg *gin.RouterGroup) {
 	users := rg.Group("/users", r.mw.RequireAdmin())
 	{
 		users.GET("", r.user.List)
+		users.GET("/export", r.user.Export)
+		users.POST("/import", r.user.Import)
+		users.POST("/bulk", r.user.Bulk)
 		users.GET("/:id", r.user.GetByID)
 		users.POST("", r.user.Create)
 		users.PUT("/:id", r.user.Update)
+		users.PATCH("/:id", r.user.Update)
 		users.DELETE("/:id", r.user.Delete)
+		users.POST("/:id/restore", r.user.Restore)
+		users.DELETE("/:id/purge", r.user.Purge)
+
+		// Status lifecycle
+		users.POST("/:id/activate", r.user.Activate)
+		users.POST("/:id/suspend", r.user.Suspend)
+		users.POST("/:id/unsuspend", r.user.Unsuspend)
+		users.POST("/:id/lock", r.user.Lock)
+		users.POST("/:id/unlock", r.user.Unlock)
+		users.POST("/:id/deactivate", r.user.Deactivate)
+		users.POST("/:id/reactivate", r.user.Reactivate)
+		users.GET("/:id/status-history", r.user.GetStatusHistory)
+	}
+}
+
+func (r *routeRegister) registerAuthEventRoutes(rg *gin.RouterGroup) {
+	authEvents := rg.Group("/auth-events", r.mw.RequireAdmin())
+	{
+		authEvents.GET("", r.authEvent.List)
+	}
+}
+
+func (r *routeRegister) registerSavedViewRoutes(rg *gin.RouterGroup) {
+	savedViews := rg.Group("/saved-views")
+	{
+		savedViews.GET("", r.savedView.List)
+		savedViews.GET("/:id", r.savedView.GetByID)
+		savedViews.POST("", r.savedView.Create)
+		savedViews.PUT("/:id", r.savedView.Update)
+		savedViews.DELETE("/:id", r.savedView.Delete)
+	}
+}
+
+func (r *routeRegister) registerAuditLogRoutes(rg *gin.RouterGroup) {
+	auditLogs := rg.Group("/audit-logs", r.mw.RequireAdmin())
+	{
+		auditLogs.GET("", r.auditLog.List)
+	}
+}
+
+func (r *routeRegister) registerWebhookRoutes(rg *gin.RouterGroup) {
+	webhooks := rg.Group("/webhooks", r.mw.RequireAdmin())
+	{
+		webhooks.GET("", r.webhook.List)
+		webhooks.GET("/:id", r.webhook.GetByID)
+		webhooks.POST("", r.webhook.Create)
+		webhooks.PUT("/:id", r.webhook.Update)
+		webhooks.DELETE("/:id", r.webhook.Delete)
+		webhooks.GET("/:id/deliveries", r.webhook.ListDeliveries)
+		webhooks.POST("/:id/deliveries/:deliveryId/redeliver", r.webhook.Redeliver)
 	}
 }
@@ -0,0 +1,89 @@
+package job
+
+import (
+	"context"
+	"time"
+
+	"github.com/thienel/tlog"
+	"go.uber.org/zap"
+
+	"github.com/thienel/go-backend-template/internal/usecase/service"
+	"github.com/thienel/go-backend-template/pkg/config"
+)
+
+// defaultRetention applies when the configured retention period is not positive
+const defaultRetention = 30 * 24 * time.Hour
+
+// RetentionJob periodically purges users that stayed soft-deleted past the retention period
+type RetentionJob struct {
+	userService service.UserService
+	retention   time.Duration
+	interval    time.Duration
+	cancel      context.CancelFunc
+	done        chan struct{}
+}
+
+// NewRetentionJob creates a new retention job
+func NewRetentionJob(userService service.UserService, cfg config.RetentionConfig) *RetentionJob {
+	interval := time.Duration(cfg.IntervalMinutes) * time.Minute
+	if interval <= 0 {
+		interval = time.Hour
+	}
+
+	// A retention of zero would purge every deleted user on the first run
+	retention := time.Duration(cfg.DeletedUserDays) * 24 * time.Hour
+	if retention <= 0 {
+		retention = defaultRetention
+	}
+
+	return &RetentionJob{
+		userService: userService,
+		retention:   retention,
+		interval:    interval,
+	}
+}
+
+// Start runs the job in the background until Stop is called
+func (j *RetentionJob) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	j.cancel = cancel
+	j.done = make(chan struct{})
+
+	go func() {
+		defer close(j.done)
+
+		ticker := time.NewTicker(j.interval)
+		defer ticker.Stop()
+
+		j.run(ctx)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				j.run(ctx)
+			}
+		}
+	}()
+
+	tlog.Info("Retention job started",
+		zap.Duration("retention", j.retention),
+		zap.Duration("interval", j.interval),
+	)
+}
+
+// Stop stops the job and waits for the current run to finish
+func (j *RetentionJob) Stop() {
+	if j.cancel == nil {
+		return
+	}
+	j.cancel()
+	<-j.done
+}
+
+func (j *RetentionJob) run(ctx context.Context) {
+	cutoff := time.Now().Add(-j.retention)
+	if _, err := j.userService.PurgeDeleted(ctx, cutoff); err != nil {
+		tlog.Error("Retention job failed", zap.Error(err))
+	}
+}
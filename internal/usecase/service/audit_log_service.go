@@ -0,0 +1,15 @@
+package service
+
+import (
+	"context"
+
+	"github.com/thienel/go-backend-template/internal/domain/entity"
+	"github.com/thienel/go-backend-template/pkg/query"
+)
+
+// AuditLogService defines the audit log interface. Entries are written by the
+// repositories of audited entities, so the service only reads them.
+type AuditLogService interface {
+	// Query
+	List(ctx context.Context, page query.Pagination, opts query.QueryOptions) (query.Page[entity.AuditLog], error)
+}
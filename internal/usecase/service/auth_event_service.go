@@ -0,0 +1,27 @@
+package service
+
+import (
+	"context"
+
+	"github.com/thienel/go-backend-template/internal/domain/entity"
+	"github.com/thienel/go-backend-template/pkg/query"
+)
+
+// RecordAuthEventCommand represents the command to record an authentication event
+type RecordAuthEventCommand struct {
+	UserID   *uint
+	Username string
+	Type     string
+	Success  bool
+	Reason   string
+}
+
+// AuthEventService defines the authentication event log interface
+type AuthEventService interface {
+	// Record stores an event, taking IP, user agent and request ID from the request context
+	Record(ctx context.Context, cmd RecordAuthEventCommand) error
+
+	// Query
+	List(ctx context.Context, page query.Pagination, opts query.QueryOptions) (query.Page[entity.AuthEvent], error)
+	ListByUser(ctx context.Context, userID uint, page query.Pagination, opts query.QueryOptions) (query.Page[entity.AuthEvent], error)
+}
@@ -3,11 +3,25 @@ package service
 import (
 	"context"
 
+	"github.com/thienel/go-backend-template/internal/domain/entity"
 	"github.com/thienel/go-backend-template/internal/interface/api/dto"
 )
 
+// ChangePasswordCommand represents the command to change the current user's password
+type ChangePasswordCommand struct {
+	UserID          uint
+	CurrentPassword string
+	NewPassword     string
+}
+
 // AuthService defines authentication service interface
 type AuthService interface {
 	Login(ctx context.Context, username, password string) (*dto.LoginResponse, error)
+	Refresh(ctx context.Context, refreshToken string) (*dto.LoginResponse, error)
+	ChangePassword(ctx context.Context, cmd ChangePasswordCommand) error
 	Logout(ctx context.Context) error
+	// Authorize checks that the user of a valid access token may still use the API.
+	// Tokens outlive status changes, so it runs on every authenticated request, and
+	// reads the user from the primary database rather than a cache. It returns the user.
+	Authorize(ctx context.Context, userID uint) (*entity.User, error)
 }
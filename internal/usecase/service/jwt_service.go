@@ -7,6 +7,7 @@ type JWTService interface {
 	GenerateAccessToken(userID uint, username, role string) (string, error)
 	GenerateRefreshToken(userID uint, username, role string) (string, error)
 	ValidateToken(tokenString string) (*valueobject.JWTClaims, error)
+	ValidateRefreshToken(tokenString string) (*valueobject.JWTClaims, error)
 	GetAccessExpirySeconds() int
 	GetRefreshExpirySeconds() int
 }
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"context"
+
+	"github.com/thienel/go-backend-template/internal/domain/entity"
+	"github.com/thienel/go-backend-template/pkg/query"
+)
+
+// CreateSavedViewCommand represents the command to save a view for the current user
+type CreateSavedViewCommand struct {
+	Name     string
+	Resource string
+	Shared   bool
+	Options  query.QueryOptions
+}
+
+// UpdateSavedViewCommand represents the command to update a saved view; nil fields are left unchanged
+type UpdateSavedViewCommand struct {
+	ID      uint
+	Name    string
+	Shared  *bool
+	Options *query.QueryOptions
+}
+
+// SavedViewService defines the saved view management interface
+type SavedViewService interface {
+	Create(ctx context.Context, cmd CreateSavedViewCommand) (*entity.SavedView, error)
+	Update(ctx context.Context, cmd UpdateSavedViewCommand) (*entity.SavedView, error)
+	Delete(ctx context.Context, id uint) error
+	// ResolveID returns the ID of the view that clients know by publicID
+	ResolveID(ctx context.Context, publicID entity.PublicID) (uint, error)
+
+	// Query; only the current user's own and shared views are visible
+	GetByID(ctx context.Context, id uint) (*entity.SavedView, error)
+	List(ctx context.Context, page query.Pagination, opts query.QueryOptions) (query.Page[entity.SavedView], error)
+
+	// Resolve returns a visible view of the given resource, for applying it to a list query
+	Resolve(ctx context.Context, id uint, resource string) (*entity.SavedView, error)
+}
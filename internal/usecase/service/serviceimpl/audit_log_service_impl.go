@@ -0,0 +1,45 @@
+package serviceimpl
+
+import (
+	"context"
+
+	"github.com/thienel/go-backend-template/internal/domain/entity"
+	"github.com/thienel/go-backend-template/internal/domain/repository"
+	"github.com/thienel/go-backend-template/internal/usecase/service"
+	"github.com/thienel/go-backend-template/pkg/query"
+)
+
+type auditLogServiceImpl struct {
+	auditLogRepo repository.AuditLogRepository
+	userRepo     repository.UserRepository
+}
+
+// NewAuditLogService creates a new audit log service
+func NewAuditLogService(auditLogRepo repository.AuditLogRepository, userRepo repository.UserRepository) service.AuditLogService {
+	return &auditLogServiceImpl{auditLogRepo: auditLogRepo, userRepo: userRepo}
+}
+
+func (s *auditLogServiceImpl) List(ctx context.Context, page query.Pagination, opts query.QueryOptions) (query.Page[entity.AuditLog], error) {
+	if err := resolvePublicIDFilters(ctx, s.userRepo.FindIDByPublicID, &opts, "actor_id"); err != nil {
+		return query.Page[entity.AuditLog]{}, err
+	}
+
+	result, err := s.auditLogRepo.List(ctx, page, opts)
+	if err != nil {
+		return result, err
+	}
+
+	ids := make([]*uint, len(result.Items))
+	for i := range result.Items {
+		ids[i] = result.Items[i].ActorID
+	}
+	publicIDs, err := publicIDs(ctx, s.userRepo, ids...)
+	if err != nil {
+		return result, err
+	}
+	for i := range result.Items {
+		l := &result.Items[i]
+		l.ActorPublicID = publicIDOf(publicIDs, l.ActorID)
+	}
+	return result, nil
+}
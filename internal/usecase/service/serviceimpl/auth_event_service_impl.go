@@ -0,0 +1,93 @@
+package serviceimpl
+
+import (
+	"context"
+	"unicode/utf8"
+
+	"github.com/thienel/go-backend-template/internal/domain/entity"
+	"github.com/thienel/go-backend-template/internal/domain/repository"
+	"github.com/thienel/go-backend-template/internal/domain/valueobject"
+	"github.com/thienel/go-backend-template/internal/usecase/service"
+	"github.com/thienel/go-backend-template/pkg/query"
+)
+
+// Lengths of the auth event columns filled from client input, which is cut to fit so
+// that no event is lost to an oversized value
+const (
+	maxUsernameLength  = 50
+	maxUserAgentLength = 255
+	maxRequestIDLength = 64
+)
+
+type authEventServiceImpl struct {
+	authEventRepo repository.AuthEventRepository
+	userRepo      repository.UserRepository
+}
+
+// NewAuthEventService creates a new auth event service
+func NewAuthEventService(authEventRepo repository.AuthEventRepository, userRepo repository.UserRepository) service.AuthEventService {
+	return &authEventServiceImpl{authEventRepo: authEventRepo, userRepo: userRepo}
+}
+
+func (s *authEventServiceImpl) Record(ctx context.Context, cmd service.RecordAuthEventCommand) error {
+	meta := valueobject.RequestMetaFromContext(ctx)
+
+	return s.authEventRepo.Create(ctx, &entity.AuthEvent{
+		UserID:    cmd.UserID,
+		Username:  truncate(cmd.Username, maxUsernameLength),
+		Type:      cmd.Type,
+		Success:   cmd.Success,
+		Reason:    cmd.Reason,
+		IP:        meta.IP,
+		UserAgent: truncate(meta.UserAgent, maxUserAgentLength),
+		RequestID: truncate(meta.RequestID, maxRequestIDLength),
+	})
+}
+
+func (s *authEventServiceImpl) List(ctx context.Context, page query.Pagination, opts query.QueryOptions) (query.Page[entity.AuthEvent], error) {
+	if err := resolvePublicIDFilters(ctx, s.userRepo.FindIDByPublicID, &opts, "user_id"); err != nil {
+		return query.Page[entity.AuthEvent]{}, err
+	}
+	return s.list(ctx, page, opts)
+}
+
+func (s *authEventServiceImpl) ListByUser(ctx context.Context, userID uint, page query.Pagination, opts query.QueryOptions) (query.Page[entity.AuthEvent], error) {
+	if err := resolvePublicIDFilters(ctx, s.userRepo.FindIDByPublicID, &opts, "user_id"); err != nil {
+		return query.Page[entity.AuthEvent]{}, err
+	}
+	// Scope to the user, overriding any user_id filter from the caller
+	opts.AddFilter("user_id", "eq", userID)
+	return s.list(ctx, page, opts)
+}
+
+// list lists events along with the public IDs of their users
+func (s *authEventServiceImpl) list(ctx context.Context, page query.Pagination, opts query.QueryOptions) (query.Page[entity.AuthEvent], error) {
+	result, err := s.authEventRepo.List(ctx, page, opts)
+	if err != nil {
+		return result, err
+	}
+
+	ids := make([]*uint, len(result.Items))
+	for i := range result.Items {
+		ids[i] = result.Items[i].UserID
+	}
+	publicIDs, err := publicIDs(ctx, s.userRepo, ids...)
+	if err != nil {
+		return result, err
+	}
+	for i := range result.Items {
+		result.Items[i].UserPublicID = publicIDOf(publicIDs, result.Items[i].UserID)
+	}
+	return result, nil
+}
+
+// truncate cuts s to at most n bytes, without splitting a UTF-8 sequence
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
@@ -2,6 +2,8 @@ package serviceimpl
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/thienel/tlog"
 	"go.uber.org/zap"
@@ -9,21 +11,29 @@ import (
 
 	"github.com/thienel/go-backend-template/internal/domain/entity"
 	"github.com/thienel/go-backend-template/internal/domain/repository"
+	"github.com/thienel/go-backend-template/internal/domain/valueobject"
 	"github.com/thienel/go-backend-template/internal/interface/api/dto"
 	"github.com/thienel/go-backend-template/internal/usecase/service"
 	apperror "github.com/thienel/go-backend-template/pkg/error"
+	"github.com/thienel/go-backend-template/pkg/timezone"
 )
 
 type authServiceImpl struct {
-	userRepo   repository.UserRepository
-	jwtService service.JWTService
+	userRepo         repository.UserRepository
+	jwtService       service.JWTService
+	authEventService service.AuthEventService
 }
 
 // NewAuthService creates a new auth service
-func NewAuthService(userRepo repository.UserRepository, jwtService service.JWTService) service.AuthService {
+func NewAuthService(
+	userRepo repository.UserRepository,
+	jwtService service.JWTService,
+	authEventService service.AuthEventService,
+) service.AuthService {
 	return &authServiceImpl{
-		userRepo:   userRepo,
-		jwtService: jwtService,
+		userRepo:         userRepo,
+		jwtService:       jwtService,
+		authEventService: authEventService,
 	}
 }
 
@@ -31,19 +41,108 @@ func (s *authServiceImpl) Login(ctx context.Context, username, password string)
 	user, err := s.userRepo.FindByUsername(ctx, username)
 	if err != nil {
 		tlog.Debug("Login failed: user not found", zap.String("username", username))
+		s.recordEvent(ctx, nil, username, entity.AuthEventLoginFailure, false, "user_not_found")
 		return nil, apperror.ErrInvalidCredentials
 	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
 		tlog.Debug("Login failed: invalid password", zap.String("username", username))
+		s.recordEvent(ctx, &user.ID, username, entity.AuthEventLoginFailure, false, "invalid_password")
 		return nil, apperror.ErrInvalidCredentials
 	}
 
-	if user.Status != entity.UserStatusActive {
-		tlog.Debug("Login failed: user inactive", zap.String("username", username))
-		return nil, apperror.ErrForbidden.WithMessage("Tài khoản đã bị vô hiệu hóa")
+	if err := checkUserStatus(user); err != nil {
+		tlog.Debug("Login failed: user not active", zap.String("username", username), zap.String("status", user.Status))
+		s.recordEvent(ctx, &user.ID, username, entity.AuthEventLoginFailure, false, "status_"+user.Status)
+		return nil, err
 	}
 
+	loginResp, err := s.issueTokens(user)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	meta := valueobject.RequestMetaFromContext(ctx)
+	if err := s.userRepo.UpdateLastLogin(ctx, user.ID, now, meta.IP); err != nil {
+		tlog.Error("Failed to update last login", zap.Uint("user_id", user.ID), zap.Error(err))
+	} else {
+		loginResp.User.LastLoginAt = &now
+		loginResp.User.LastLoginIP = meta.IP
+	}
+	s.recordEvent(ctx, &user.ID, user.Username, entity.AuthEventLoginSuccess, true, "")
+
+	tlog.Info("User logged in", zap.Uint("user_id", user.ID), zap.String("username", user.Username))
+	return loginResp, nil
+}
+
+func (s *authServiceImpl) Refresh(ctx context.Context, refreshToken string) (*dto.LoginResponse, error) {
+	claims, err := s.jwtService.ValidateRefreshToken(refreshToken)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.FindByID(ctx, claims.UserID)
+	if err != nil {
+		tlog.Debug("Refresh failed: user not found", zap.Uint("user_id", claims.UserID))
+		s.recordEvent(ctx, nil, claims.Username, entity.AuthEventTokenRefresh, false, "user_not_found")
+		return nil, apperror.ErrUnauthorized.WithMessage("Refresh token không hợp lệ")
+	}
+
+	if err := checkUserStatus(user); err != nil {
+		tlog.Debug("Refresh failed: user not active", zap.Uint("user_id", user.ID), zap.String("status", user.Status))
+		s.recordEvent(ctx, &user.ID, user.Username, entity.AuthEventTokenRefresh, false, "status_"+user.Status)
+		return nil, err
+	}
+
+	loginResp, err := s.issueTokens(user)
+	if err != nil {
+		return nil, err
+	}
+
+	s.recordEvent(ctx, &user.ID, user.Username, entity.AuthEventTokenRefresh, true, "")
+	return loginResp, nil
+}
+
+func (s *authServiceImpl) ChangePassword(ctx context.Context, cmd service.ChangePasswordCommand) error {
+	user, err := s.userRepo.FindByID(ctx, cmd.UserID)
+	if err != nil {
+		return err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(cmd.CurrentPassword)); err != nil {
+		tlog.Debug("Change password failed: invalid current password", zap.Uint("user_id", user.ID))
+		s.recordEvent(ctx, &user.ID, user.Username, entity.AuthEventPasswordChange, false, "invalid_password")
+		return apperror.ErrInvalidCredentials.WithMessage("Mật khẩu hiện tại không chính xác")
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(cmd.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return apperror.ErrInternalServerError.WithMessage("Không thể mã hóa mật khẩu").WithError(err)
+	}
+
+	user.Password = string(hashedPassword)
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return err
+	}
+
+	s.recordEvent(ctx, &user.ID, user.Username, entity.AuthEventPasswordChange, true, "")
+
+	tlog.Info("User changed password", zap.Uint("user_id", user.ID))
+	return nil
+}
+
+func (s *authServiceImpl) Logout(ctx context.Context) error {
+	// For stateless JWT, logout is handled at the handler level by clearing cookies
+	// If you need blacklist/revocation, implement it here with Redis
+	meta := valueobject.RequestMetaFromContext(ctx)
+	if meta.ActorID != 0 {
+		s.recordEvent(ctx, &meta.ActorID, "", entity.AuthEventLogout, true, "")
+	}
+	return nil
+}
+
+func (s *authServiceImpl) issueTokens(user *entity.User) (*dto.LoginResponse, error) {
 	accessToken, err := s.jwtService.GenerateAccessToken(user.ID, user.Username, user.Role)
 	if err != nil {
 		return nil, apperror.ErrInternalServerError.WithMessage("Không thể tạo access token").WithError(err)
@@ -54,25 +153,73 @@ func (s *authServiceImpl) Login(ctx context.Context, username, password string)
 		return nil, apperror.ErrInternalServerError.WithMessage("Không thể tạo refresh token").WithError(err)
 	}
 
-	tlog.Info("User logged in", zap.Uint("user_id", user.ID), zap.String("username", user.Username))
-
 	return &dto.LoginResponse{
 		User: dto.UserResponse{
-			ID:        user.ID,
-			Username:  user.Username,
-			Email:     user.Email,
-			Role:      user.Role,
-			Status:    user.Status,
-			CreatedAt: user.CreatedAt,
-			UpdatedAt: user.UpdatedAt,
+			ID:             user.PublicID.String(),
+			Username:       user.Username,
+			Email:          user.Email,
+			Role:           user.Role,
+			Status:         user.Status,
+			StatusReason:   user.StatusReason,
+			SuspendedUntil: user.SuspendedUntil,
+			LastLoginAt:    user.LastLoginAt,
+			LastLoginIP:    user.LastLoginIP,
+			CreatedAt:      user.CreatedAt,
+			UpdatedAt:      user.UpdatedAt,
 		},
 		AccessToken:  accessToken,
 		RefreshToken: refreshToken,
 	}, nil
 }
 
-func (s *authServiceImpl) Logout(ctx context.Context) error {
-	// For stateless JWT, logout is handled at the handler level by clearing cookies
-	// If you need blacklist/revocation, implement it here with Redis
-	return nil
+// recordEvent writes to the auth event log. Failures are logged and never block authentication.
+func (s *authServiceImpl) recordEvent(ctx context.Context, userID *uint, username, eventType string, success bool, reason string) {
+	if err := s.authEventService.Record(ctx, service.RecordAuthEventCommand{
+		UserID:   userID,
+		Username: username,
+		Type:     eventType,
+		Success:  success,
+		Reason:   reason,
+	}); err != nil {
+		tlog.Error("Failed to record auth event", zap.String("type", eventType), zap.Error(err))
+	}
+}
+
+func (s *authServiceImpl) Authorize(ctx context.Context, userID uint) (*entity.User, error) {
+	// Read past the cache and replicas: a cached or lagging copy could still let a
+	// suspended, locked or deleted user in
+	user, err := s.userRepo.FindByID(repository.WithReadYourWrites(ctx), userID)
+	if err != nil {
+		var appErr *apperror.AppError
+		if errors.As(err, &appErr) && appErr.Code == apperror.ErrNotFound.Code {
+			// Deleted since the token was issued
+			return nil, apperror.ErrUnauthorized
+		}
+		return nil, err
+	}
+	if err := checkUserStatus(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// checkUserStatus enforces the user status state machine on authentication
+func checkUserStatus(user *entity.User) error {
+	switch user.EffectiveStatus(time.Now()) {
+	case entity.UserStatusActive:
+		return nil
+	case entity.UserStatusPending:
+		return apperror.ErrAccountPending
+	case entity.UserStatusSuspended:
+		if user.SuspendedUntil != nil {
+			return apperror.ErrAccountSuspended.WithMessage(
+				"Tài khoản đang bị tạm ngưng đến " + user.SuspendedUntil.In(timezone.VietnamLocation).Format("15:04 02/01/2006"),
+			)
+		}
+		return apperror.ErrAccountSuspended
+	case entity.UserStatusLocked:
+		return apperror.ErrAccountLocked
+	default:
+		return apperror.ErrAccountDeactivated
+	}
 }
@@ -26,10 +26,17 @@ func NewJWTService(secret string, accessExpiryMinutes, refreshExpiryHours int) s
 	}
 }
 
+// Token types, so a refresh token cannot be used as an access token and vice versa
+const (
+	tokenTypeAccess  = "access"
+	tokenTypeRefresh = "refresh"
+)
+
 type jwtClaims struct {
-	UserID   uint   `json:"user_id"`
-	Username string `json:"username"`
-	Role     string `json:"role"`
+	UserID    uint   `json:"user_id"`
+	Username  string `json:"username"`
+	Role      string `json:"role"`
+	TokenType string `json:"token_type,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -37,9 +44,10 @@ func (s *jwtServiceImpl) GenerateAccessToken(userID uint, username, role string)
 	expiry := time.Now().Add(time.Duration(s.accessExpiryMinutes) * time.Minute)
 
 	claims := jwtClaims{
-		UserID:   userID,
-		Username: username,
-		Role:     role,
+		UserID:    userID,
+		Username:  username,
+		Role:      role,
+		TokenType: tokenTypeAccess,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expiry),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -55,9 +63,10 @@ func (s *jwtServiceImpl) GenerateRefreshToken(userID uint, username, role string
 	expiry := time.Now().Add(time.Duration(s.refreshExpiryHours) * time.Hour)
 
 	claims := jwtClaims{
-		UserID:   userID,
-		Username: username,
-		Role:     role,
+		UserID:    userID,
+		Username:  username,
+		Role:      role,
+		TokenType: tokenTypeRefresh,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expiry),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -70,6 +79,33 @@ func (s *jwtServiceImpl) GenerateRefreshToken(userID uint, username, role string
 }
 
 func (s *jwtServiceImpl) ValidateToken(tokenString string) (*valueobject.JWTClaims, error) {
+	claims, err := s.parseToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	// Tokens issued before token types were introduced carry no type and are treated as access tokens
+	if claims.TokenType == tokenTypeRefresh {
+		return nil, apperror.ErrUnauthorized.WithMessage("Token không hợp lệ")
+	}
+
+	return toJWTClaims(claims), nil
+}
+
+func (s *jwtServiceImpl) ValidateRefreshToken(tokenString string) (*valueobject.JWTClaims, error) {
+	claims, err := s.parseToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.TokenType != tokenTypeRefresh {
+		return nil, apperror.ErrUnauthorized.WithMessage("Refresh token không hợp lệ")
+	}
+
+	return toJWTClaims(claims), nil
+}
+
+func (s *jwtServiceImpl) parseToken(tokenString string) (*jwtClaims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &jwtClaims{}, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, errors.New("invalid signing method")
@@ -89,11 +125,15 @@ func (s *jwtServiceImpl) ValidateToken(tokenString string) (*valueobject.JWTClai
 		return nil, apperror.ErrUnauthorized.WithMessage("Token không hợp lệ")
 	}
 
+	return claims, nil
+}
+
+func toJWTClaims(claims *jwtClaims) *valueobject.JWTClaims {
 	return &valueobject.JWTClaims{
 		UserID:   claims.UserID,
 		Username: claims.Username,
 		Role:     claims.Role,
-	}, nil
+	}
 }
 
 func (s *jwtServiceImpl) GetAccessExpirySeconds() int {
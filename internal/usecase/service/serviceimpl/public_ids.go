@@ -0,0 +1,72 @@
+package serviceimpl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/thienel/go-backend-template/internal/domain/entity"
+	"github.com/thienel/go-backend-template/internal/domain/repository"
+	apperror "github.com/thienel/go-backend-template/pkg/error"
+	"github.com/thienel/go-backend-template/pkg/query"
+)
+
+// publicIDs maps the IDs of users referenced by other entities to the public IDs that
+// API clients know them by, as internal IDs are never exposed. Nil IDs are skipped.
+func publicIDs(ctx context.Context, userRepo repository.UserRepository, ids ...*uint) (map[uint]entity.PublicID, error) {
+	seen := make(map[uint]struct{}, len(ids))
+	unique := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if id == nil {
+			continue
+		}
+		if _, ok := seen[*id]; !ok {
+			seen[*id] = struct{}{}
+			unique = append(unique, *id)
+		}
+	}
+	return userRepo.FindPublicIDs(ctx, unique)
+}
+
+// publicIDOf returns the public ID of a user in the map, or nil if the user has none,
+// having been purged
+func publicIDOf(ids map[uint]entity.PublicID, id *uint) *entity.PublicID {
+	if id == nil {
+		return nil
+	}
+	publicID, ok := ids[*id]
+	if !ok {
+		return nil
+	}
+	return &publicID
+}
+
+// resolvePublicIDFilters replaces the public IDs that the filters on fields compare
+// against with the internal IDs their columns hold, looked up with find. A public ID of
+// no entity resolves to 0, which no row refers to.
+func resolvePublicIDFilters(ctx context.Context, find func(context.Context, entity.PublicID) (uint, error), opts *query.QueryOptions, fields ...string) error {
+	resolve := func(value any) (any, error) {
+		publicID, err := entity.ParsePublicID(fmt.Sprint(value))
+		if err != nil {
+			return nil, apperror.ErrValidation.WithMessage("ID không hợp lệ").WithError(err)
+		}
+		id, err := find(ctx, publicID)
+		var appErr *apperror.AppError
+		if errors.As(err, &appErr) && appErr.Code == apperror.ErrNotFound.Code {
+			return uint(0), nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return id, nil
+	}
+
+	for _, field := range fields {
+		filter, err := opts.Filter.MapValues(field, resolve)
+		if err != nil {
+			return err
+		}
+		opts.Filter = filter
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+package serviceimpl
+
+import (
+	"context"
+
+	"github.com/thienel/tlog"
+	"go.uber.org/zap"
+
+	"github.com/thienel/go-backend-template/internal/domain/entity"
+	"github.com/thienel/go-backend-template/internal/domain/repository"
+	"github.com/thienel/go-backend-template/internal/domain/valueobject"
+	"github.com/thienel/go-backend-template/internal/usecase/service"
+	apperror "github.com/thienel/go-backend-template/pkg/error"
+	"github.com/thienel/go-backend-template/pkg/query"
+)
+
+type savedViewServiceImpl struct {
+	savedViewRepo repository.SavedViewRepository
+	userRepo      repository.UserRepository
+}
+
+// NewSavedViewService creates a new saved view service
+func NewSavedViewService(savedViewRepo repository.SavedViewRepository, userRepo repository.UserRepository) service.SavedViewService {
+	return &savedViewServiceImpl{savedViewRepo: savedViewRepo, userRepo: userRepo}
+}
+
+func (s *savedViewServiceImpl) Create(ctx context.Context, cmd service.CreateSavedViewCommand) (*entity.SavedView, error) {
+	if !entity.IsValidSavedViewResource(cmd.Resource) {
+		return nil, apperror.ErrValidation.WithMessage("Tài nguyên không hỗ trợ bộ lọc đã lưu")
+	}
+
+	view := &entity.SavedView{
+		OwnerID:  valueobject.RequestMetaFromContext(ctx).ActorID,
+		Resource: cmd.Resource,
+		Name:     cmd.Name,
+		Shared:   cmd.Shared,
+		Options:  cmd.Options,
+	}
+	if err := s.savedViewRepo.Create(ctx, view); err != nil {
+		return nil, err
+	}
+
+	tlog.Info("Saved view created", zap.Uint("view_id", view.ID), zap.Uint("owner_id", view.OwnerID), zap.String("resource", view.Resource))
+	if err := s.setOwners(ctx, view); err != nil {
+		return nil, err
+	}
+	return view, nil
+}
+
+func (s *savedViewServiceImpl) Update(ctx context.Context, cmd service.UpdateSavedViewCommand) (*entity.SavedView, error) {
+	view, err := s.findOwned(ctx, cmd.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if cmd.Name != "" {
+		view.Name = cmd.Name
+	}
+	if cmd.Shared != nil {
+		view.Shared = *cmd.Shared
+	}
+	if cmd.Options != nil {
+		view.Options = *cmd.Options
+	}
+
+	if err := s.savedViewRepo.Update(ctx, view); err != nil {
+		return nil, err
+	}
+
+	tlog.Info("Saved view updated", zap.Uint("view_id", view.ID))
+	if err := s.setOwners(ctx, view); err != nil {
+		return nil, err
+	}
+	return view, nil
+}
+
+func (s *savedViewServiceImpl) Delete(ctx context.Context, id uint) error {
+	if _, err := s.findOwned(ctx, id); err != nil {
+		return err
+	}
+
+	if err := s.savedViewRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	tlog.Info("Saved view deleted", zap.Uint("view_id", id))
+	return nil
+}
+
+func (s *savedViewServiceImpl) ResolveID(ctx context.Context, publicID entity.PublicID) (uint, error) {
+	id, err := s.savedViewRepo.FindIDByPublicID(ctx, publicID)
+	if err != nil {
+		tlog.Debug("Resolve saved view failed: not found", zap.Stringer("public_id", publicID))
+		return 0, err
+	}
+	return id, nil
+}
+
+func (s *savedViewServiceImpl) GetByID(ctx context.Context, id uint) (*entity.SavedView, error) {
+	view, err := s.find(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.setOwners(ctx, view); err != nil {
+		return nil, err
+	}
+	return view, nil
+}
+
+// find returns a view that the current user may see
+func (s *savedViewServiceImpl) find(ctx context.Context, id uint) (*entity.SavedView, error) {
+	view, err := s.savedViewRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !view.IsVisibleTo(valueobject.RequestMetaFromContext(ctx).ActorID) {
+		// Private views of others are reported as missing rather than forbidden
+		return nil, apperror.ErrNotFound.WithMessage("Không tìm thấy bộ lọc đã lưu")
+	}
+	return view, nil
+}
+
+func (s *savedViewServiceImpl) List(ctx context.Context, page query.Pagination, opts query.QueryOptions) (query.Page[entity.SavedView], error) {
+	if err := resolvePublicIDFilters(ctx, s.userRepo.FindIDByPublicID, &opts, "owner_id"); err != nil {
+		return query.Page[entity.SavedView]{}, err
+	}
+	opts.AddFilterExpr(query.Or(
+		query.Cond("owner_id", "eq", valueobject.RequestMetaFromContext(ctx).ActorID),
+		query.Cond("shared", "eq", true),
+	))
+	result, err := s.savedViewRepo.List(ctx, page, opts)
+	if err != nil {
+		return result, err
+	}
+
+	views := make([]*entity.SavedView, len(result.Items))
+	for i := range result.Items {
+		views[i] = &result.Items[i]
+	}
+	if err := s.setOwners(ctx, views...); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+func (s *savedViewServiceImpl) Resolve(ctx context.Context, id uint, resource string) (*entity.SavedView, error) {
+	view, err := s.find(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if view.Resource != resource {
+		return nil, apperror.ErrValidation.WithMessage("Bộ lọc đã lưu không dành cho danh sách này")
+	}
+	return view, nil
+}
+
+// findOwned returns a view that the current user owns; only owners may change a view
+func (s *savedViewServiceImpl) findOwned(ctx context.Context, id uint) (*entity.SavedView, error) {
+	view, err := s.find(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if view.OwnerID != valueobject.RequestMetaFromContext(ctx).ActorID {
+		return nil, apperror.ErrForbidden.WithMessage("Chỉ người tạo mới được thay đổi bộ lọc đã lưu")
+	}
+	return view, nil
+}
+
+// setOwners sets the public IDs of the owners of views
+func (s *savedViewServiceImpl) setOwners(ctx context.Context, views ...*entity.SavedView) error {
+	ids := make([]*uint, len(views))
+	for i, view := range views {
+		ids[i] = &view.OwnerID
+	}
+	publicIDs, err := publicIDs(ctx, s.userRepo, ids...)
+	if err != nil {
+		return err
+	}
+	for _, view := range views {
+		view.OwnerPublicID = publicIDs[view.OwnerID]
+	}
+	return nil
+}
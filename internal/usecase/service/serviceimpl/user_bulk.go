@@ -0,0 +1,175 @@
+package serviceimpl
+
+import (
+	"context"
+	"errors"
+
+	"github.com/thienel/tlog"
+	"go.uber.org/zap"
+
+	"github.com/thienel/go-backend-template/internal/domain/entity"
+	"github.com/thienel/go-backend-template/internal/usecase/service"
+	apperror "github.com/thienel/go-backend-template/pkg/error"
+	"github.com/thienel/go-backend-template/pkg/query"
+)
+
+const maxBulkUsers = 1000
+
+// errBulkAborted rolls back an all-or-nothing bulk action after an item failed
+var errBulkAborted = errors.New("bulk action aborted")
+
+func (s *userServiceImpl) Bulk(ctx context.Context, cmd service.BulkUserCommand) (*service.BulkUserReport, error) {
+	action, err := s.bulkAction(cmd)
+	if err != nil {
+		return nil, err
+	}
+	// Targets are named by public ID; one that matches no user fails on its own
+	apply := s.byPublicID(action)
+
+	ids, err := s.resolveBulkTargets(ctx, cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &service.BulkUserReport{
+		Action: cmd.Action,
+		Atomic: cmd.Atomic,
+		Total:  len(ids),
+		Items:  make([]service.BulkUserItemResult, len(ids)),
+	}
+	for i, id := range ids {
+		report.Items[i] = service.BulkUserItemResult{ID: id, Status: service.BulkItemSkipped}
+	}
+
+	if cmd.Atomic {
+		err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+			for i, id := range ids {
+				if err := apply(ctx, id); err != nil {
+					report.Items[i].Status = service.BulkItemFailed
+					report.Items[i].Error = err
+					return errBulkAborted
+				}
+				report.Items[i].Status = service.BulkItemSucceeded
+			}
+			return nil
+		})
+
+		if err != nil {
+			report.RolledBack = true
+			for i := range report.Items {
+				if report.Items[i].Status == service.BulkItemSucceeded {
+					report.Items[i].Status = service.BulkItemRolledBack
+				}
+			}
+			if !errors.Is(err, errBulkAborted) {
+				return nil, err
+			}
+		}
+	} else {
+		for i, id := range ids {
+			// Each item is still applied atomically on its own
+			err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+				return apply(ctx, id)
+			})
+			if err != nil {
+				report.Items[i].Status = service.BulkItemFailed
+				report.Items[i].Error = err
+				continue
+			}
+			report.Items[i].Status = service.BulkItemSucceeded
+		}
+	}
+
+	for _, item := range report.Items {
+		switch item.Status {
+		case service.BulkItemSucceeded:
+			report.Succeeded++
+		case service.BulkItemFailed:
+			report.Failed++
+		}
+	}
+
+	tlog.Info("Bulk user action completed",
+		zap.String("action", cmd.Action),
+		zap.Bool("atomic", cmd.Atomic),
+		zap.Int("total", report.Total),
+		zap.Int("succeeded", report.Succeeded),
+		zap.Int("failed", report.Failed),
+		zap.Bool("rolled_back", report.RolledBack),
+	)
+	return report, nil
+}
+
+// byPublicID adapts a per-user operation to take the public ID of the user
+func (s *userServiceImpl) byPublicID(apply func(ctx context.Context, id uint) error) func(ctx context.Context, publicID entity.PublicID) error {
+	return func(ctx context.Context, publicID entity.PublicID) error {
+		id, err := s.ResolveID(ctx, publicID)
+		if err != nil {
+			return err
+		}
+		return apply(ctx, id)
+	}
+}
+
+// bulkAction returns the per-user operation for the action. Each one goes through the same
+// service method, and therefore the same permission checks, as the single-user route.
+func (s *userServiceImpl) bulkAction(cmd service.BulkUserCommand) (func(ctx context.Context, id uint) error, error) {
+	switch cmd.Action {
+	case service.BulkUserActionDeactivate:
+		return func(ctx context.Context, id uint) error {
+			_, err := s.transition(ctx, id, entity.UserTransitionDeactivate, cmd.Reason, nil)
+			return err
+		}, nil
+	case service.BulkUserActionChangeRole:
+		if !entity.IsValidUserRole(cmd.Role) {
+			return nil, apperror.ErrValidation.WithMessage("Role không hợp lệ")
+		}
+		return func(ctx context.Context, id uint) error {
+			_, err := s.Update(ctx, service.UpdateUserCommand{ID: id, Role: cmd.Role})
+			return err
+		}, nil
+	case service.BulkUserActionDelete:
+		return func(ctx context.Context, id uint) error {
+			return s.Delete(ctx, service.DeleteUserCommand{ID: id})
+		}, nil
+	default:
+		return nil, apperror.ErrValidation.WithMessage("Thao tác không hợp lệ")
+	}
+}
+
+// resolveBulkTargets returns the de-duplicated public IDs of the targets, either given or
+// matched by the filter
+func (s *userServiceImpl) resolveBulkTargets(ctx context.Context, cmd service.BulkUserCommand) ([]entity.PublicID, error) {
+	var ids []entity.PublicID
+
+	if len(cmd.IDs) > 0 {
+		seen := make(map[entity.PublicID]bool, len(cmd.IDs))
+		for _, id := range cmd.IDs {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	} else {
+		if !cmd.Filter.HasFilters() {
+			return nil, apperror.ErrValidation.WithMessage("Vui lòng chọn người dùng hoặc điều kiện lọc")
+		}
+
+		page, err := s.userRepo.ListWithQuery(ctx, query.Pagination{Mode: query.PaginationOffset, Limit: maxBulkUsers + 1}, cmd.Filter)
+		if err != nil {
+			return nil, err
+		}
+		for _, u := range page.Items {
+			ids = append(ids, u.PublicID)
+		}
+	}
+
+	if len(ids) == 0 {
+		return nil, apperror.ErrValidation.WithMessage("Không có người dùng nào phù hợp")
+	}
+	if len(ids) > maxBulkUsers {
+		return nil, apperror.ErrValidation.WithMessage("Số người dùng vượt quá giới hạn cho phép")
+	}
+
+	return ids, nil
+}
@@ -0,0 +1,42 @@
+package serviceimpl
+
+import (
+	"context"
+
+	"github.com/thienel/go-backend-template/internal/domain/entity"
+	"github.com/thienel/go-backend-template/internal/domain/valueobject"
+	apperror "github.com/thienel/go-backend-template/pkg/error"
+)
+
+// authorizeManage checks that the current actor may manage the target user.
+// Calls without an actor (background jobs, internal use) are trusted.
+func authorizeManage(ctx context.Context, target *entity.User, allowSelf bool) error {
+	meta := valueobject.RequestMetaFromContext(ctx)
+	if meta.ActorID == 0 {
+		return nil
+	}
+
+	if meta.ActorID == target.ID && !allowSelf {
+		return apperror.ErrForbidden.WithMessage("Không thể thực hiện thao tác này trên chính tài khoản của bạn")
+	}
+
+	if target.Role == entity.UserRoleSystemAdmin && meta.ActorRole != entity.UserRoleSystemAdmin {
+		return apperror.ErrForbidden.WithMessage("Không có quyền quản lý quản trị viên hệ thống")
+	}
+
+	return nil
+}
+
+// authorizeRoleAssignment checks that the current actor may grant the given role
+func authorizeRoleAssignment(ctx context.Context, role string) error {
+	meta := valueobject.RequestMetaFromContext(ctx)
+	if meta.ActorID == 0 {
+		return nil
+	}
+
+	if role == entity.UserRoleSystemAdmin && meta.ActorRole != entity.UserRoleSystemAdmin {
+		return apperror.ErrForbidden.WithMessage("Không có quyền gán vai trò quản trị viên hệ thống")
+	}
+
+	return nil
+}
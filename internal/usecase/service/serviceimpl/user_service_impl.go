@@ -2,28 +2,84 @@ package serviceimpl
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/thienel/tlog"
 	"go.uber.org/zap"
 	"golang.org/x/crypto/bcrypt"
 
 	"github.com/thienel/go-backend-template/internal/domain/entity"
+	"github.com/thienel/go-backend-template/internal/domain/event"
 	"github.com/thienel/go-backend-template/internal/domain/repository"
+	"github.com/thienel/go-backend-template/internal/domain/valueobject"
 	"github.com/thienel/go-backend-template/internal/usecase/service"
 	apperror "github.com/thienel/go-backend-template/pkg/error"
 	"github.com/thienel/go-backend-template/pkg/query"
 )
 
 type userServiceImpl struct {
-	userRepo repository.UserRepository
+	userRepo    repository.UserRepository
+	historyRepo repository.UserStatusHistoryRepository
+	txManager   repository.TxManager
+	publisher   event.Publisher
 }
 
 // NewUserService creates a new user service
-func NewUserService(userRepo repository.UserRepository) service.UserService {
-	return &userServiceImpl{userRepo: userRepo}
+func NewUserService(
+	userRepo repository.UserRepository,
+	historyRepo repository.UserStatusHistoryRepository,
+	txManager repository.TxManager,
+	publisher event.Publisher,
+) service.UserService {
+	return &userServiceImpl{
+		userRepo:    userRepo,
+		historyRepo: historyRepo,
+		txManager:   txManager,
+		publisher:   publisher,
+	}
 }
 
 func (s *userServiceImpl) Create(ctx context.Context, cmd service.CreateUserCommand) (*entity.User, error) {
+	user, err := s.validateCreate(ctx, cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	// Hash password
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(cmd.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, apperror.ErrInternalServerError.WithMessage("Không thể mã hóa mật khẩu").WithError(err)
+	}
+	user.Password = string(hashedPassword)
+
+	// The user and its event are written together
+	if err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.userRepo.Create(ctx, user); err != nil {
+			return err
+		}
+		meta := valueobject.RequestMetaFromContext(ctx)
+		return s.publisher.Publish(ctx, event.UserCreated{
+			UserID:        user.ID,
+			PublicID:      user.PublicID.String(),
+			Username:      user.Username,
+			Email:         user.Email,
+			Role:          user.Role,
+			Status:        user.Status,
+			ActorID:       meta.ActorID,
+			ActorPublicID: meta.ActorPublicID,
+			Timestamp:     user.CreatedAt,
+		})
+	}); err != nil {
+		return nil, err
+	}
+
+	tlog.Info("User created", zap.Uint("user_id", user.ID), zap.String("username", user.Username))
+	return user, nil
+}
+
+// validateCreate applies the creation rules and returns the user to insert, without a password hash
+func (s *userServiceImpl) validateCreate(ctx context.Context, cmd service.CreateUserCommand) (*entity.User, error) {
 	// Validate role
 	role := entity.UserRoleUser
 	if cmd.Role != "" {
@@ -32,6 +88,18 @@ func (s *userServiceImpl) Create(ctx context.Context, cmd service.CreateUserComm
 		}
 		role = cmd.Role
 	}
+	if err := authorizeRoleAssignment(ctx, role); err != nil {
+		return nil, err
+	}
+
+	// Validate initial status
+	status := entity.UserStatusActive
+	if cmd.Status != "" {
+		if cmd.Status != entity.UserStatusPending && cmd.Status != entity.UserStatusActive {
+			return nil, apperror.ErrValidation.WithMessage("Status không hợp lệ")
+		}
+		status = cmd.Status
+	}
 
 	// Check username exists
 	if _, err := s.userRepo.FindByUsernameIncludingDeleted(ctx, cmd.Username); err == nil {
@@ -45,43 +113,62 @@ func (s *userServiceImpl) Create(ctx context.Context, cmd service.CreateUserComm
 		return nil, apperror.ErrEmailExists
 	}
 
-	// Hash password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(cmd.Password), bcrypt.DefaultCost)
-	if err != nil {
-		return nil, apperror.ErrInternalServerError.WithMessage("Không thể mã hóa mật khẩu").WithError(err)
-	}
-
-	user := &entity.User{
+	return &entity.User{
 		Username: cmd.Username,
 		Email:    cmd.Email,
-		Password: string(hashedPassword),
 		Role:     role,
-		Status:   entity.UserStatusActive,
-	}
+		Status:   status,
+	}, nil
+}
 
-	if err := s.userRepo.Create(ctx, user); err != nil {
+func (s *userServiceImpl) GetByID(ctx context.Context, id uint) (*entity.User, error) {
+	user, err := s.userRepo.FindByID(ctx, id)
+	if err != nil {
+		tlog.Debug("Get user failed: not found", zap.Uint("user_id", id))
 		return nil, err
 	}
-
-	tlog.Info("User created", zap.Uint("user_id", user.ID), zap.String("username", user.Username))
 	return user, nil
 }
 
-func (s *userServiceImpl) GetByID(ctx context.Context, id uint) (*entity.User, error) {
-	user, err := s.userRepo.FindByID(ctx, id)
+func (s *userServiceImpl) GetByIDWithOptions(ctx context.Context, id uint, opts query.QueryOptions) (*entity.User, error) {
+	user, err := s.userRepo.FindByIDWithOptions(ctx, id, opts)
 	if err != nil {
 		tlog.Debug("Get user failed: not found", zap.Uint("user_id", id))
 		return nil, err
 	}
+	if err := s.setHistoryActors(ctx, user.StatusHistory); err != nil {
+		return nil, err
+	}
 	return user, nil
 }
 
+func (s *userServiceImpl) ResolveID(ctx context.Context, publicID entity.PublicID) (uint, error) {
+	id, err := s.userRepo.FindIDByPublicID(ctx, publicID)
+	if err != nil {
+		tlog.Debug("Resolve user failed: not found", zap.Stringer("public_id", publicID))
+		return 0, err
+	}
+	return id, nil
+}
+
 func (s *userServiceImpl) Update(ctx context.Context, cmd service.UpdateUserCommand) (*entity.User, error) {
 	user, err := s.userRepo.FindByID(ctx, cmd.ID)
 	if err != nil {
 		tlog.Debug("Update user failed: not found", zap.Uint("user_id", cmd.ID))
 		return nil, err
 	}
+	if err := checkVersion(user.Version, cmd.Version); err != nil {
+		return nil, err
+	}
+
+	// Users may edit their own profile but not their own role
+	roleChanged := cmd.Role != "" && cmd.Role != user.Role
+	if err := authorizeManage(ctx, user, !roleChanged); err != nil {
+		return nil, err
+	}
+
+	var changed []string
+	fromRole := user.Role
 
 	// Update username if changed
 	if cmd.Username != "" && cmd.Username != user.Username {
@@ -90,6 +177,7 @@ func (s *userServiceImpl) Update(ctx context.Context, cmd service.UpdateUserComm
 			return nil, apperror.ErrUsernameExists
 		}
 		user.Username = cmd.Username
+		changed = append(changed, "username")
 	}
 
 	// Update email if changed
@@ -99,25 +187,54 @@ func (s *userServiceImpl) Update(ctx context.Context, cmd service.UpdateUserComm
 			return nil, apperror.ErrEmailExists
 		}
 		user.Email = cmd.Email
+		changed = append(changed, "email")
 	}
 
 	// Update role
-	if cmd.Role != "" {
+	if roleChanged {
 		if !entity.IsValidUserRole(cmd.Role) {
 			return nil, apperror.ErrValidation.WithMessage("Role không hợp lệ")
 		}
+		if err := authorizeRoleAssignment(ctx, cmd.Role); err != nil {
+			return nil, err
+		}
 		user.Role = cmd.Role
+		changed = append(changed, "role")
 	}
 
-	// Update status
-	if cmd.Status != "" {
-		if !entity.IsValidUserStatus(cmd.Status) {
-			return nil, apperror.ErrValidation.WithMessage("Status không hợp lệ")
-		}
-		user.Status = cmd.Status
+	// Nothing to write, and no event to raise
+	if len(changed) == 0 {
+		return user, nil
 	}
 
-	if err := s.userRepo.Update(ctx, user); err != nil {
+	if err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.userRepo.Update(ctx, user); err != nil {
+			return err
+		}
+
+		meta := valueobject.RequestMetaFromContext(ctx)
+		events := []event.Event{event.UserUpdated{
+			UserID:        user.ID,
+			PublicID:      user.PublicID.String(),
+			ChangedFields: changed,
+			Version:       user.Version,
+			ActorID:       meta.ActorID,
+			ActorPublicID: meta.ActorPublicID,
+			Timestamp:     user.UpdatedAt,
+		}}
+		if roleChanged {
+			events = append(events, event.UserRoleChanged{
+				UserID:        user.ID,
+				PublicID:      user.PublicID.String(),
+				FromRole:      fromRole,
+				ToRole:        user.Role,
+				ActorID:       meta.ActorID,
+				ActorPublicID: meta.ActorPublicID,
+				Timestamp:     user.UpdatedAt,
+			})
+		}
+		return s.publisher.Publish(ctx, events...)
+	}); err != nil {
 		return nil, err
 	}
 
@@ -125,21 +242,297 @@ func (s *userServiceImpl) Update(ctx context.Context, cmd service.UpdateUserComm
 	return user, nil
 }
 
-func (s *userServiceImpl) Delete(ctx context.Context, id uint) error {
+func (s *userServiceImpl) Delete(ctx context.Context, cmd service.DeleteUserCommand) error {
 	// Check exists
-	if _, err := s.userRepo.FindByID(ctx, id); err != nil {
-		tlog.Debug("Delete user failed: not found", zap.Uint("user_id", id))
+	user, err := s.userRepo.FindByID(ctx, cmd.ID)
+	if err != nil {
+		tlog.Debug("Delete user failed: not found", zap.Uint("user_id", cmd.ID))
+		return err
+	}
+	if err := checkVersion(user.Version, cmd.Version); err != nil {
+		return err
+	}
+
+	if err := authorizeManage(ctx, user, false); err != nil {
 		return err
 	}
 
-	if err := s.userRepo.Delete(ctx, id); err != nil {
+	// Conditional on the version read above, so a concurrent update is not deleted unseen
+	if err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.userRepo.DeleteVersion(ctx, cmd.ID, user.Version); err != nil {
+			return err
+		}
+		return s.publishLifecycle(ctx, event.UserDeletedEvent, user)
+	}); err != nil {
 		return err
 	}
 
-	tlog.Info("User deleted", zap.Uint("user_id", id))
+	tlog.Info("User deleted", zap.Uint("user_id", cmd.ID))
 	return nil
 }
 
-func (s *userServiceImpl) List(ctx context.Context, offset, limit int, opts query.QueryOptions) ([]entity.User, int64, error) {
-	return s.userRepo.ListWithQuery(ctx, offset, limit, opts)
+// checkVersion fails with ErrVersionConflict if the caller expected a different version
+func checkVersion(current, expected uint) error {
+	if expected != 0 && expected != current {
+		return apperror.ErrVersionConflict
+	}
+	return nil
+}
+
+func (s *userServiceImpl) Restore(ctx context.Context, id uint) (*entity.User, error) {
+	user, err := s.userRepo.FindByIDIncludingDeleted(ctx, id)
+	if err != nil {
+		tlog.Debug("Restore user failed: not found", zap.Uint("user_id", id))
+		return nil, err
+	}
+
+	if !user.DeletedAt.Valid {
+		return nil, apperror.ErrBadRequest.WithMessage("Người dùng chưa bị xóa")
+	}
+
+	if err := authorizeManage(ctx, user, false); err != nil {
+		return nil, err
+	}
+
+	if err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.userRepo.Restore(ctx, id); err != nil {
+			return err
+		}
+		return s.publishLifecycle(ctx, event.UserRestoredEvent, user)
+	}); err != nil {
+		return nil, err
+	}
+
+	tlog.Info("User restored", zap.Uint("user_id", id))
+	return s.userRepo.FindByID(repository.WithReadYourWrites(ctx), id)
+}
+
+func (s *userServiceImpl) Purge(ctx context.Context, cmd service.PurgeUserCommand) error {
+	// Check exists, including soft-deleted users
+	user, err := s.userRepo.FindByIDIncludingDeleted(ctx, cmd.ID)
+	if err != nil {
+		tlog.Debug("Purge user failed: not found", zap.Uint("user_id", cmd.ID))
+		return err
+	}
+	if err := checkVersion(user.Version, cmd.Version); err != nil {
+		return err
+	}
+
+	if err := authorizeManage(ctx, user, false); err != nil {
+		return err
+	}
+
+	if err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.userRepo.Purge(ctx, cmd.ID, user.Version); err != nil {
+			return err
+		}
+		return s.publishLifecycle(ctx, event.UserPurgedEvent, user)
+	}); err != nil {
+		return err
+	}
+
+	tlog.Info("User purged", zap.Uint("user_id", cmd.ID))
+	return nil
+}
+
+// publishLifecycle raises a deletion, restoration or purge event for a user
+func (s *userServiceImpl) publishLifecycle(ctx context.Context, name string, user *entity.User) error {
+	meta := valueobject.RequestMetaFromContext(ctx)
+	return s.publisher.Publish(ctx, event.UserLifecycleChanged{
+		Name:          name,
+		UserID:        user.ID,
+		PublicID:      user.PublicID.String(),
+		ActorID:       meta.ActorID,
+		ActorPublicID: meta.ActorPublicID,
+		Timestamp:     time.Now(),
+	})
+}
+
+func (s *userServiceImpl) PurgeDeleted(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	purged, err := s.userRepo.PurgeDeletedBefore(ctx, deletedBefore)
+	if err != nil {
+		return 0, err
+	}
+
+	if purged > 0 {
+		tlog.Info("Soft-deleted users purged", zap.Int64("count", purged), zap.Time("deleted_before", deletedBefore))
+	}
+	return purged, nil
+}
+
+func (s *userServiceImpl) Activate(ctx context.Context, cmd service.ChangeUserStatusCommand) (*entity.User, error) {
+	return s.transition(ctx, cmd.ID, entity.UserTransitionActivate, cmd.Reason, nil)
+}
+
+func (s *userServiceImpl) Suspend(ctx context.Context, cmd service.SuspendUserCommand) (*entity.User, error) {
+	if cmd.Reason == "" {
+		return nil, apperror.ErrValidation.WithMessage("Vui lòng nhập lý do tạm ngưng")
+	}
+	if cmd.Until != nil && !cmd.Until.After(time.Now()) {
+		return nil, apperror.ErrValidation.WithMessage("Thời hạn tạm ngưng phải ở tương lai")
+	}
+	return s.transition(ctx, cmd.ID, entity.UserTransitionSuspend, cmd.Reason, cmd.Until)
+}
+
+func (s *userServiceImpl) Unsuspend(ctx context.Context, cmd service.ChangeUserStatusCommand) (*entity.User, error) {
+	return s.transition(ctx, cmd.ID, entity.UserTransitionUnsuspend, cmd.Reason, nil)
+}
+
+func (s *userServiceImpl) Lock(ctx context.Context, cmd service.ChangeUserStatusCommand) (*entity.User, error) {
+	return s.transition(ctx, cmd.ID, entity.UserTransitionLock, cmd.Reason, nil)
+}
+
+func (s *userServiceImpl) Unlock(ctx context.Context, cmd service.ChangeUserStatusCommand) (*entity.User, error) {
+	return s.transition(ctx, cmd.ID, entity.UserTransitionUnlock, cmd.Reason, nil)
+}
+
+func (s *userServiceImpl) Deactivate(ctx context.Context, cmd service.ChangeUserStatusCommand) (*entity.User, error) {
+	return s.transition(ctx, cmd.ID, entity.UserTransitionDeactivate, cmd.Reason, nil)
+}
+
+func (s *userServiceImpl) Reactivate(ctx context.Context, cmd service.ChangeUserStatusCommand) (*entity.User, error) {
+	return s.transition(ctx, cmd.ID, entity.UserTransitionReactivate, cmd.Reason, nil)
+}
+
+func (s *userServiceImpl) GetStatusHistory(ctx context.Context, id uint, page query.Pagination) (query.Page[entity.UserStatusHistory], error) {
+	if _, err := s.userRepo.FindByIDIncludingDeleted(ctx, id); err != nil {
+		return query.Page[entity.UserStatusHistory]{}, err
+	}
+
+	opts := query.NewQueryOptions()
+	opts.AddFilter("user_id", "eq", id)
+	result, err := s.historyRepo.List(ctx, page, opts)
+	if err != nil {
+		return result, err
+	}
+	if err := s.setHistoryActors(ctx, result.Items); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// statusHistories returns the loaded status histories of users
+func statusHistories(users []entity.User) [][]entity.UserStatusHistory {
+	histories := make([][]entity.UserStatusHistory, len(users))
+	for i := range users {
+		histories[i] = users[i].StatusHistory
+	}
+	return histories
+}
+
+// setHistoryActors sets the public IDs of the actors of status transitions
+func (s *userServiceImpl) setHistoryActors(ctx context.Context, histories ...[]entity.UserStatusHistory) error {
+	var ids []*uint
+	for _, list := range histories {
+		for i := range list {
+			ids = append(ids, list[i].ActorID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	publicIDs, err := publicIDs(ctx, s.userRepo, ids...)
+	if err != nil {
+		return err
+	}
+	for _, list := range histories {
+		for i := range list {
+			list[i].ActorPublicID = publicIDOf(publicIDs, list[i].ActorID)
+		}
+	}
+	return nil
+}
+
+// transition moves a user through the status state machine, records history and raises the event
+func (s *userServiceImpl) transition(
+	ctx context.Context,
+	id uint,
+	t entity.UserStatusTransition,
+	reason string,
+	suspendedUntil *time.Time,
+) (*entity.User, error) {
+	user, err := s.userRepo.FindByID(ctx, id)
+	if err != nil {
+		tlog.Debug("Change user status failed: not found", zap.Uint("user_id", id), zap.String("transition", t.Name))
+		return nil, err
+	}
+
+	if err := authorizeManage(ctx, user, false); err != nil {
+		return nil, err
+	}
+
+	if !t.Allows(user.Status) {
+		tlog.Debug("Change user status failed: transition not allowed",
+			zap.Uint("user_id", id),
+			zap.String("transition", t.Name),
+			zap.String("status", user.Status),
+		)
+		return nil, apperror.ErrInvalidStatusTransition.WithMessage(
+			fmt.Sprintf("Không thể %s người dùng ở trạng thái %s", t.Name, user.Status),
+		)
+	}
+
+	fromStatus := user.Status
+	user.Status = t.To
+	user.StatusReason = reason
+	user.SuspendedUntil = suspendedUntil
+
+	meta := valueobject.RequestMetaFromContext(ctx)
+	history := &entity.UserStatusHistory{
+		UserID:         user.ID,
+		Transition:     t.Name,
+		FromStatus:     fromStatus,
+		ToStatus:       t.To,
+		Reason:         reason,
+		SuspendedUntil: suspendedUntil,
+	}
+	if meta.ActorID != 0 {
+		history.ActorID = &meta.ActorID
+	}
+
+	// The status change, its history row and its event are written together
+	if err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.userRepo.Update(ctx, user); err != nil {
+			return err
+		}
+		if err := s.historyRepo.Create(ctx, history); err != nil {
+			return err
+		}
+		return s.publisher.Publish(ctx, event.UserStatusChanged{
+			UserID:         user.ID,
+			PublicID:       user.PublicID.String(),
+			Transition:     t.Name,
+			Name:           t.Event,
+			FromStatus:     fromStatus,
+			ToStatus:       t.To,
+			Reason:         reason,
+			SuspendedUntil: suspendedUntil,
+			ActorID:        meta.ActorID,
+			ActorPublicID:  meta.ActorPublicID,
+			Timestamp:      history.CreatedAt,
+		})
+	}); err != nil {
+		return nil, err
+	}
+
+	tlog.Info("User status changed",
+		zap.Uint("user_id", user.ID),
+		zap.String("transition", t.Name),
+		zap.String("from", fromStatus),
+		zap.String("to", t.To),
+	)
+	return user, nil
+}
+
+func (s *userServiceImpl) List(ctx context.Context, page query.Pagination, opts query.QueryOptions) (query.Page[entity.User], error) {
+	result, err := s.userRepo.ListWithQuery(ctx, page, opts)
+	if err != nil {
+		return result, err
+	}
+
+	if err := s.setHistoryActors(ctx, statusHistories(result.Items)...); err != nil {
+		return result, err
+	}
+	return result, nil
 }
@@ -0,0 +1,247 @@
+package serviceimpl
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/thienel/tlog"
+	"go.uber.org/zap"
+
+	"github.com/thienel/go-backend-template/internal/domain/entity"
+	"github.com/thienel/go-backend-template/internal/domain/event"
+	"github.com/thienel/go-backend-template/internal/domain/repository"
+	"github.com/thienel/go-backend-template/internal/usecase/service"
+	apperror "github.com/thienel/go-backend-template/pkg/error"
+	"github.com/thienel/go-backend-template/pkg/query"
+)
+
+// minWebhookSecretLength keeps given secrets hard to guess
+const minWebhookSecretLength = 16
+
+// webhookEventTypes lists the events a webhook can subscribe to, besides entity.WebhookEventAll
+var webhookEventTypes = []string{
+	event.UserCreatedEvent, event.UserUpdatedEvent, event.UserRoleChangedEvent,
+	event.UserDeletedEvent, event.UserRestoredEvent, event.UserPurgedEvent,
+	entity.UserTransitionActivate.Event, entity.UserTransitionSuspend.Event,
+	entity.UserTransitionUnsuspend.Event, entity.UserTransitionLock.Event,
+	entity.UserTransitionUnlock.Event, entity.UserTransitionDeactivate.Event,
+	entity.UserTransitionReactivate.Event,
+}
+
+type webhookServiceImpl struct {
+	subscriptionRepo repository.WebhookSubscriptionRepository
+	deliveryRepo     repository.WebhookDeliveryRepository
+}
+
+// NewWebhookService creates a new webhook service
+func NewWebhookService(
+	subscriptionRepo repository.WebhookSubscriptionRepository,
+	deliveryRepo repository.WebhookDeliveryRepository,
+) service.WebhookService {
+	return &webhookServiceImpl{subscriptionRepo: subscriptionRepo, deliveryRepo: deliveryRepo}
+}
+
+func (s *webhookServiceImpl) Create(ctx context.Context, cmd service.CreateWebhookCommand) (*entity.WebhookSubscription, error) {
+	if err := validateWebhookURL(cmd.URL); err != nil {
+		return nil, err
+	}
+	if err := validateWebhookEventTypes(cmd.EventTypes); err != nil {
+		return nil, err
+	}
+
+	secret := cmd.Secret
+	if secret == "" {
+		var err error
+		if secret, err = generateWebhookSecret(); err != nil {
+			return nil, apperror.ErrInternalServerError.WithMessage("Không thể tạo khóa bí mật").WithError(err)
+		}
+	} else if len(secret) < minWebhookSecretLength {
+		return nil, apperror.ErrValidation.WithMessage("Khóa bí mật phải có ít nhất 16 ký tự")
+	}
+
+	subscription := &entity.WebhookSubscription{
+		URL:         cmd.URL,
+		EventTypes:  cmd.EventTypes,
+		Secret:      secret,
+		Description: cmd.Description,
+		Active:      cmd.Active == nil || *cmd.Active,
+	}
+	if err := s.subscriptionRepo.Create(ctx, subscription); err != nil {
+		return nil, err
+	}
+
+	tlog.Info("Webhook created", zap.Uint("webhook_id", subscription.ID), zap.Strings("event_types", subscription.EventTypes))
+	return subscription, nil
+}
+
+func (s *webhookServiceImpl) Update(ctx context.Context, cmd service.UpdateWebhookCommand) (*entity.WebhookSubscription, error) {
+	subscription, err := s.subscriptionRepo.FindByID(ctx, cmd.ID)
+	if err != nil {
+		tlog.Debug("Update webhook failed: not found", zap.Uint("webhook_id", cmd.ID))
+		return nil, err
+	}
+
+	if cmd.URL != "" {
+		if err := validateWebhookURL(cmd.URL); err != nil {
+			return nil, err
+		}
+		subscription.URL = cmd.URL
+	}
+	if cmd.EventTypes != nil {
+		if err := validateWebhookEventTypes(cmd.EventTypes); err != nil {
+			return nil, err
+		}
+		subscription.EventTypes = cmd.EventTypes
+	}
+	if cmd.Secret != "" {
+		if len(cmd.Secret) < minWebhookSecretLength {
+			return nil, apperror.ErrValidation.WithMessage("Khóa bí mật phải có ít nhất 16 ký tự")
+		}
+		subscription.Secret = cmd.Secret
+	}
+	if cmd.Description != nil {
+		subscription.Description = *cmd.Description
+	}
+	if cmd.Active != nil {
+		subscription.Active = *cmd.Active
+	}
+
+	if err := s.subscriptionRepo.Update(ctx, subscription); err != nil {
+		return nil, err
+	}
+
+	tlog.Info("Webhook updated", zap.Uint("webhook_id", subscription.ID))
+	return subscription, nil
+}
+
+func (s *webhookServiceImpl) Delete(ctx context.Context, id uint) error {
+	if _, err := s.subscriptionRepo.FindByID(ctx, id); err != nil {
+		tlog.Debug("Delete webhook failed: not found", zap.Uint("webhook_id", id))
+		return err
+	}
+
+	// Queued deliveries are marked dead by the worker when it finds no subscription
+	if err := s.subscriptionRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	tlog.Info("Webhook deleted", zap.Uint("webhook_id", id))
+	return nil
+}
+
+func (s *webhookServiceImpl) ResolveID(ctx context.Context, publicID entity.PublicID) (uint, error) {
+	id, err := s.subscriptionRepo.FindIDByPublicID(ctx, publicID)
+	if err != nil {
+		tlog.Debug("Resolve webhook failed: not found", zap.Stringer("public_id", publicID))
+		return 0, err
+	}
+	return id, nil
+}
+
+func (s *webhookServiceImpl) ResolveDeliveryID(ctx context.Context, publicID entity.PublicID) (uint, error) {
+	id, err := s.deliveryRepo.FindIDByPublicID(ctx, publicID)
+	if err != nil {
+		tlog.Debug("Resolve webhook delivery failed: not found", zap.Stringer("public_id", publicID))
+		return 0, err
+	}
+	return id, nil
+}
+
+func (s *webhookServiceImpl) GetByID(ctx context.Context, id uint) (*entity.WebhookSubscription, error) {
+	subscription, err := s.subscriptionRepo.FindByID(ctx, id)
+	if err != nil {
+		tlog.Debug("Get webhook failed: not found", zap.Uint("webhook_id", id))
+		return nil, err
+	}
+	return subscription, nil
+}
+
+func (s *webhookServiceImpl) List(ctx context.Context, page query.Pagination, opts query.QueryOptions) (query.Page[entity.WebhookSubscription], error) {
+	return s.subscriptionRepo.List(ctx, page, opts)
+}
+
+func (s *webhookServiceImpl) ListDeliveries(ctx context.Context, subscriptionID uint, page query.Pagination, opts query.QueryOptions) (query.Page[entity.WebhookDelivery], error) {
+	subscription, err := s.subscriptionRepo.FindByID(ctx, subscriptionID)
+	if err != nil {
+		tlog.Debug("List webhook deliveries failed: not found", zap.Uint("webhook_id", subscriptionID))
+		return query.Page[entity.WebhookDelivery]{}, err
+	}
+
+	// Scope to the subscription, overriding any subscription_id filter from the caller
+	if err := resolvePublicIDFilters(ctx, s.subscriptionRepo.FindIDByPublicID, &opts, "subscription_id"); err != nil {
+		return query.Page[entity.WebhookDelivery]{}, err
+	}
+	opts.AddFilter("subscription_id", "eq", subscriptionID)
+	result, err := s.deliveryRepo.List(ctx, page, opts)
+	if err != nil {
+		return result, err
+	}
+
+	for i := range result.Items {
+		result.Items[i].SubscriptionPublicID = subscription.PublicID
+	}
+	return result, nil
+}
+
+func (s *webhookServiceImpl) Redeliver(ctx context.Context, subscriptionID, deliveryID uint) (*entity.WebhookDelivery, error) {
+	subscription, err := s.subscriptionRepo.FindByID(ctx, subscriptionID)
+	if err != nil {
+		tlog.Debug("Redeliver webhook failed: not found", zap.Uint("webhook_id", subscriptionID))
+		return nil, err
+	}
+	delivery, err := s.deliveryRepo.FindByID(ctx, deliveryID)
+	if err != nil {
+		tlog.Debug("Redeliver webhook failed: not found", zap.Uint("webhook_id", subscriptionID), zap.Uint("delivery_id", deliveryID))
+		return nil, err
+	}
+	if delivery.SubscriptionID != subscriptionID {
+		tlog.Debug("Redeliver webhook failed: not found", zap.Uint("webhook_id", subscriptionID), zap.Uint("delivery_id", deliveryID))
+		return nil, apperror.ErrNotFound.WithMessage("Không tìm thấy lượt gửi webhook")
+	}
+
+	delivery.SubscriptionPublicID = subscription.PublicID
+
+	delivery.Status = entity.WebhookDeliveryPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = time.Now()
+	delivery.LastError = ""
+	if err := s.deliveryRepo.Update(ctx, delivery); err != nil {
+		return nil, err
+	}
+
+	tlog.Info("Webhook delivery queued again", zap.Uint("webhook_id", subscriptionID), zap.Uint("delivery_id", deliveryID))
+	return delivery, nil
+}
+
+func validateWebhookURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return apperror.ErrValidation.WithMessage("URL webhook không hợp lệ")
+	}
+	return nil
+}
+
+func validateWebhookEventTypes(eventTypes []string) error {
+	if len(eventTypes) == 0 {
+		return apperror.ErrValidation.WithMessage("Vui lòng chọn ít nhất một loại sự kiện")
+	}
+	for _, eventType := range eventTypes {
+		if eventType != entity.WebhookEventAll && !slices.Contains(webhookEventTypes, eventType) {
+			return apperror.ErrValidation.WithMessage("Loại sự kiện không hợp lệ: " + eventType)
+		}
+	}
+	return nil
+}
+
+// generateWebhookSecret returns a random secret with a prefix that makes it recognizable
+func generateWebhookSecret() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
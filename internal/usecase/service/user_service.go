@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"time"
 
 	"github.com/thienel/go-backend-template/internal/domain/entity"
 	"github.com/thienel/go-backend-template/pkg/query"
@@ -13,6 +14,7 @@ type CreateUserCommand struct {
 	Email    string
 	Password string
 	Role     string
+	Status   string // PENDING or ACTIVE, defaults to ACTIVE
 }
 
 // UpdateUserCommand represents the command to update a user
@@ -21,7 +23,105 @@ type UpdateUserCommand struct {
 	Username string
 	Email    string
 	Role     string
-	Status   string
+	Version  uint // expected current version, e.g. from If-Match; 0 skips the check
+}
+
+// PurgeUserCommand represents the command to permanently remove a user
+type PurgeUserCommand struct {
+	ID      uint
+	Version uint // expected current version, e.g. from If-Match; 0 skips the check
+}
+
+// DeleteUserCommand represents the command to soft-delete a user
+type DeleteUserCommand struct {
+	ID      uint
+	Version uint // expected current version, e.g. from If-Match; 0 skips the check
+}
+
+// ChangeUserStatusCommand represents the command to move a user through a status transition
+type ChangeUserStatusCommand struct {
+	ID     uint
+	Reason string
+}
+
+// SuspendUserCommand represents the command to suspend a user
+type SuspendUserCommand struct {
+	ID     uint
+	Reason string
+	Until  *time.Time // nil suspends indefinitely
+}
+
+// ImportUserRow is a single decoded row of a bulk user import
+type ImportUserRow struct {
+	Line    int
+	Command CreateUserCommand
+	Err     error // decoding or field validation error from the API layer
+}
+
+// ImportUserSource streams rows of a bulk user import; Next returns io.EOF when exhausted
+type ImportUserSource interface {
+	Next() (*ImportUserRow, error)
+}
+
+// ImportUserResult is the outcome of importing a single row
+type ImportUserResult struct {
+	Line     int
+	Username string
+	UserID   entity.PublicID
+	Error    error
+}
+
+// ImportUsersReport summarizes a bulk user import
+type ImportUsersReport struct {
+	DryRun    bool
+	Total     int
+	Succeeded int
+	Failed    int
+	Rows      []ImportUserResult
+}
+
+// Bulk user actions
+const (
+	BulkUserActionDeactivate = "deactivate"
+	BulkUserActionChangeRole = "change_role"
+	BulkUserActionDelete     = "delete"
+)
+
+// Bulk item outcomes
+const (
+	BulkItemSucceeded  = "succeeded"
+	BulkItemFailed     = "failed"
+	BulkItemRolledBack = "rolled_back"
+	BulkItemSkipped    = "skipped"
+)
+
+// BulkUserCommand represents the command to apply one action to many users.
+// Targets are either public IDs or, when IDs is empty, every user matching Filter.
+type BulkUserCommand struct {
+	IDs    []entity.PublicID
+	Filter query.QueryOptions
+	Action string
+	Role   string // for change_role
+	Reason string // for deactivate
+	Atomic bool   // all-or-nothing; otherwise best-effort
+}
+
+// BulkUserItemResult is the outcome of the bulk action for a single user
+type BulkUserItemResult struct {
+	ID     entity.PublicID
+	Status string
+	Error  error
+}
+
+// BulkUserReport summarizes a bulk user action
+type BulkUserReport struct {
+	Action     string
+	Atomic     bool
+	Total      int
+	Succeeded  int
+	Failed     int
+	RolledBack bool
+	Items      []BulkUserItemResult
 }
 
 // UserService defines the user service interface
@@ -29,9 +129,35 @@ type UserService interface {
 	// CRUD
 	Create(ctx context.Context, cmd CreateUserCommand) (*entity.User, error)
 	GetByID(ctx context.Context, id uint) (*entity.User, error)
+	// GetByIDWithOptions gets a user with the sparse fieldset and includes of opts
+	GetByIDWithOptions(ctx context.Context, id uint, opts query.QueryOptions) (*entity.User, error)
 	Update(ctx context.Context, cmd UpdateUserCommand) (*entity.User, error)
-	Delete(ctx context.Context, id uint) error
+	Delete(ctx context.Context, cmd DeleteUserCommand) error
+	// ResolveID returns the ID of the user, deleted or not, that clients know by publicID
+	ResolveID(ctx context.Context, publicID entity.PublicID) (uint, error)
+
+	// Soft-delete lifecycle
+	Restore(ctx context.Context, id uint) (*entity.User, error)
+	Purge(ctx context.Context, cmd PurgeUserCommand) error
+	PurgeDeleted(ctx context.Context, deletedBefore time.Time) (int64, error)
+
+	// Status lifecycle
+	Activate(ctx context.Context, cmd ChangeUserStatusCommand) (*entity.User, error)
+	Suspend(ctx context.Context, cmd SuspendUserCommand) (*entity.User, error)
+	Unsuspend(ctx context.Context, cmd ChangeUserStatusCommand) (*entity.User, error)
+	Lock(ctx context.Context, cmd ChangeUserStatusCommand) (*entity.User, error)
+	Unlock(ctx context.Context, cmd ChangeUserStatusCommand) (*entity.User, error)
+	Deactivate(ctx context.Context, cmd ChangeUserStatusCommand) (*entity.User, error)
+	Reactivate(ctx context.Context, cmd ChangeUserStatusCommand) (*entity.User, error)
+	GetStatusHistory(ctx context.Context, id uint, page query.Pagination) (query.Page[entity.UserStatusHistory], error)
+
+	// Bulk operations
+	Bulk(ctx context.Context, cmd BulkUserCommand) (*BulkUserReport, error)
+
+	// Bulk import/export
+	Import(ctx context.Context, source ImportUserSource, dryRun bool) (*ImportUsersReport, error)
+	Export(ctx context.Context, opts query.QueryOptions, write func(users []entity.User) error) error
 
 	// Query
-	List(ctx context.Context, offset, limit int, opts query.QueryOptions) ([]entity.User, int64, error)
+	List(ctx context.Context, page query.Pagination, opts query.QueryOptions) (query.Page[entity.User], error)
 }
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"context"
+
+	"github.com/thienel/go-backend-template/internal/domain/entity"
+	"github.com/thienel/go-backend-template/pkg/query"
+)
+
+// CreateWebhookCommand represents the command to subscribe a URL to events. A secret is
+// generated if none is given.
+type CreateWebhookCommand struct {
+	URL         string
+	EventTypes  []string
+	Secret      string
+	Description string
+	Active      *bool
+}
+
+// UpdateWebhookCommand represents the command to update a webhook subscription; empty
+// fields are left unchanged
+type UpdateWebhookCommand struct {
+	ID          uint
+	URL         string
+	EventTypes  []string
+	Secret      string
+	Description *string
+	Active      *bool
+}
+
+// WebhookService defines the webhook subscription management interface
+type WebhookService interface {
+	Create(ctx context.Context, cmd CreateWebhookCommand) (*entity.WebhookSubscription, error)
+	Update(ctx context.Context, cmd UpdateWebhookCommand) (*entity.WebhookSubscription, error)
+	Delete(ctx context.Context, id uint) error
+	// ResolveID returns the ID of the subscription that clients know by publicID
+	ResolveID(ctx context.Context, publicID entity.PublicID) (uint, error)
+	// ResolveDeliveryID returns the ID of the delivery that clients know by publicID
+	ResolveDeliveryID(ctx context.Context, publicID entity.PublicID) (uint, error)
+
+	// Query
+	GetByID(ctx context.Context, id uint) (*entity.WebhookSubscription, error)
+	List(ctx context.Context, page query.Pagination, opts query.QueryOptions) (query.Page[entity.WebhookSubscription], error)
+	ListDeliveries(ctx context.Context, subscriptionID uint, page query.Pagination, opts query.QueryOptions) (query.Page[entity.WebhookDelivery], error)
+
+	// Redeliver queues a delivery to be sent again with a fresh set of attempts
+	Redeliver(ctx context.Context, subscriptionID, deliveryID uint) (*entity.WebhookDelivery, error)
+}
@@ -0,0 +1,13 @@
+package backoff
+
+import "time"
+
+// Exponential returns the delay after the given number of failed attempts: base after
+// the first, doubling after each further one, and never more than limit
+func Exponential(attempts int, base, limit time.Duration) time.Duration {
+	delay := base
+	for i := 1; i < attempts && delay < limit; i++ {
+		delay *= 2
+	}
+	return min(delay, limit)
+}
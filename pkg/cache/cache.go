@@ -0,0 +1,16 @@
+// Package cache provides key-value cache backends, an in-memory LRU and Redis, behind
+// a common Store interface, and counters of cache lookups.
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// Store is a cache backend. Values are opaque bytes that expire after their TTL.
+type Store interface {
+	// Get returns the value of key, and false if it is missing or expired
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+}
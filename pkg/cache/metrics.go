@@ -0,0 +1,62 @@
+package cache
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Stats counts the lookups of a cache. Errors are failed backend calls, after which the
+// value is loaded from the source as on a miss.
+type Stats struct {
+	Hits   int64
+	Misses int64
+	Errors int64
+}
+
+type counters struct {
+	hits, misses, errors atomic.Int64
+}
+
+// Metrics counts lookups per cache name; it is safe for concurrent use
+type Metrics struct {
+	mu     sync.Mutex
+	caches map[string]*counters
+}
+
+// NewMetrics creates an empty set of counters
+func NewMetrics() *Metrics {
+	return &Metrics{caches: make(map[string]*counters)}
+}
+
+// Hit counts a lookup of cache name that found its value
+func (m *Metrics) Hit(name string) { m.counters(name).hits.Add(1) }
+
+// Miss counts a lookup of cache name that had to load its value
+func (m *Metrics) Miss(name string) { m.counters(name).misses.Add(1) }
+
+// Error counts a failed backend call of cache name
+func (m *Metrics) Error(name string) { m.counters(name).errors.Add(1) }
+
+// Stats returns the counts of each cache by name
+func (m *Metrics) Stats() map[string]Stats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats := make(map[string]Stats, len(m.caches))
+	for name, c := range m.caches {
+		stats[name] = Stats{Hits: c.hits.Load(), Misses: c.misses.Load(), Errors: c.errors.Load()}
+	}
+	return stats
+}
+
+func (m *Metrics) counters(name string) *counters {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	c, ok := m.caches[name]
+	if !ok {
+		c = &counters{}
+		m.caches[name] = c
+	}
+	return c
+}
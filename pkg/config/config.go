@@ -16,10 +16,17 @@ type ServerConfig struct {
 	Env         string
 	ServiceName string
 	Version     string
+	// MetricsPort serves /metrics on a separate listener; empty disables metrics
+	MetricsPort string
 }
 
 // DatabaseConfig holds PostgreSQL configuration
 type DatabaseConfig struct {
+	// Driver is postgres, mysql or sqlite. For sqlite DBName is the database file, or
+	// "file::memory:?cache=shared" for an in-memory database, and the server settings
+	// are ignored.
+	Driver string
+
 	Host     string
 	Port     int
 	User     string
@@ -27,6 +34,36 @@ type DatabaseConfig struct {
 	DBName   string
 	SSLMode  string
 	TimeZone string
+
+	// MigrateOnStart applies pending migrations at boot; RequireMigrations refuses to
+	// start while migrations are pending instead of only logging a warning
+	MigrateOnStart    bool
+	RequireMigrations bool
+
+	// SearchTrigram adds pg_trgm similarity to user search; its migration creates the
+	// extension, which takes privileges the application role may not have
+	SearchTrigram bool
+
+	// Connection pool, applied to the primary and each replica
+	MaxOpenConns           int
+	MaxIdleConns           int
+	ConnMaxLifetimeSeconds int
+	ConnMaxIdleTimeSeconds int
+
+	// ConnectTimeoutSeconds bounds opening a connection; StatementTimeoutMs bounds each
+	// statement and is disabled when 0
+	ConnectTimeoutSeconds int
+	StatementTimeoutMs    int
+
+	// ConnectRetries is how many more times startup tries to reach the primary, waiting
+	// ConnectRetryDelayMs and then twice as long each time
+	ConnectRetries      int
+	ConnectRetryDelayMs int
+
+	// ReplicaHosts lists read replicas as host or host:port, sharing the credentials and
+	// database name of the primary
+	ReplicaHosts                 []string
+	ReplicaHealthIntervalSeconds int
 }
 
 // JWTConfig holds JWT authentication configuration
@@ -63,6 +100,45 @@ type RateLimitConfig struct {
 	RequestsPerMinute int
 }
 
+// RetentionConfig holds soft-delete retention configuration
+type RetentionConfig struct {
+	Enabled         bool
+	DeletedUserDays int
+	IntervalMinutes int
+}
+
+// OutboxConfig holds domain event delivery configuration
+type OutboxConfig struct {
+	DispatchEnabled bool
+	PollIntervalMs  int
+	BatchSize       int
+	MaxAttempts     int
+	LeaseSeconds    int
+	// Sink also writes events to "stdout" or to a file path; empty disables it
+	Sink string
+}
+
+// WebhookConfig holds outbound webhook delivery configuration
+type WebhookConfig struct {
+	DeliveryEnabled bool
+	PollIntervalMs  int
+	BatchSize       int
+	MaxAttempts     int
+	LeaseSeconds    int
+	TimeoutSeconds  int
+	// AllowPrivateNetworks lets deliveries reach loopback and private addresses, for
+	// development; otherwise only public addresses are allowed
+	AllowPrivateNetworks bool
+}
+
+// CacheConfig holds repository cache configuration
+type CacheConfig struct {
+	// Backend is "memory", "redis" (at RedisURL) or "none" to disable caching
+	Backend        string
+	TTLSeconds     int
+	MemoryCapacity int
+}
+
 // Config holds all application configuration
 type Config struct {
 	Server    ServerConfig
@@ -71,6 +147,10 @@ type Config struct {
 	Log       LogConfig
 	Cookie    CookieConfig
 	RateLimit RateLimitConfig
+	Retention RetentionConfig
+	Outbox    OutboxConfig
+	Webhook   WebhookConfig
+	Cache     CacheConfig
 
 	RedisURL           string
 	CORSAllowedOrigins []string
@@ -91,6 +171,10 @@ func Load() (*Config, error) {
 		Log:       loadLogConfig(),
 		Cookie:    loadCookieConfig(),
 		RateLimit: loadRateLimitConfig(),
+		Retention: loadRetentionConfig(),
+		Outbox:    loadOutboxConfig(),
+		Webhook:   loadWebhookConfig(),
+		Cache:     loadCacheConfig(),
 
 		RedisURL:           getEnv("REDIS_URL", "redis://localhost:6379"),
 		CORSAllowedOrigins: parseCSV(getEnv("CORS_ALLOWED_ORIGINS", "http://localhost:3000")),
@@ -105,11 +189,14 @@ func loadServerConfig() ServerConfig {
 		Env:         getEnv("ENV", "development"),
 		ServiceName: getEnv("SERVICE_NAME", "go-backend-template"),
 		Version:     getEnv("SERVICE_VERSION", "1.0.0"),
+		MetricsPort: getEnv("METRICS_PORT", ""),
 	}
 }
 
 func loadDatabaseConfig() DatabaseConfig {
 	return DatabaseConfig{
+		Driver: getEnv("DB_DRIVER", "postgres"),
+
 		Host:     getEnv("DB_HOST", "localhost"),
 		Port:     getEnvInt("DB_PORT", 5432),
 		User:     getEnv("DB_USER", "postgres"),
@@ -117,6 +204,22 @@ func loadDatabaseConfig() DatabaseConfig {
 		DBName:   getEnv("DB_NAME", "go_backend_template"),
 		SSLMode:  getEnv("DB_SSLMODE", "disable"),
 		TimeZone: getEnv("DB_TIMEZONE", "Asia/Ho_Chi_Minh"),
+
+		MigrateOnStart:    getEnvBool("DB_MIGRATE_ON_START", true),
+		RequireMigrations: getEnvBool("DB_REQUIRE_MIGRATIONS", false),
+		SearchTrigram:     getEnvBool("DB_SEARCH_TRIGRAM", false),
+
+		MaxOpenConns:           getEnvInt("DB_MAX_OPEN_CONNS", 100),
+		MaxIdleConns:           getEnvInt("DB_MAX_IDLE_CONNS", 10),
+		ConnMaxLifetimeSeconds: getEnvInt("DB_CONN_MAX_LIFETIME_SECONDS", 1800),
+		ConnMaxIdleTimeSeconds: getEnvInt("DB_CONN_MAX_IDLE_TIME_SECONDS", 300),
+		ConnectTimeoutSeconds:  getEnvInt("DB_CONNECT_TIMEOUT_SECONDS", 5),
+		StatementTimeoutMs:     getEnvInt("DB_STATEMENT_TIMEOUT_MS", 0),
+		ConnectRetries:         getEnvInt("DB_CONNECT_RETRIES", 5),
+		ConnectRetryDelayMs:    getEnvInt("DB_CONNECT_RETRY_DELAY_MS", 500),
+
+		ReplicaHosts:                 parseCSV(getEnv("DB_REPLICA_HOSTS", "")),
+		ReplicaHealthIntervalSeconds: getEnvInt("DB_REPLICA_HEALTH_INTERVAL_SECONDS", 10),
 	}
 }
 
@@ -158,6 +261,46 @@ func loadRateLimitConfig() RateLimitConfig {
 	}
 }
 
+func loadRetentionConfig() RetentionConfig {
+	return RetentionConfig{
+		Enabled:         getEnvBool("RETENTION_ENABLED", false),
+		DeletedUserDays: getEnvInt("RETENTION_DELETED_USER_DAYS", 30),
+		IntervalMinutes: getEnvInt("RETENTION_INTERVAL_MINUTES", 60),
+	}
+}
+
+func loadOutboxConfig() OutboxConfig {
+	return OutboxConfig{
+		DispatchEnabled: getEnvBool("OUTBOX_DISPATCH_ENABLED", true),
+		PollIntervalMs:  getEnvInt("OUTBOX_POLL_INTERVAL_MS", 1000),
+		BatchSize:       getEnvInt("OUTBOX_BATCH_SIZE", 50),
+		MaxAttempts:     getEnvInt("OUTBOX_MAX_ATTEMPTS", 10),
+		LeaseSeconds:    getEnvInt("OUTBOX_LEASE_SECONDS", 300),
+		Sink:            getEnv("OUTBOX_SINK", ""),
+	}
+}
+
+func loadWebhookConfig() WebhookConfig {
+	return WebhookConfig{
+		DeliveryEnabled: getEnvBool("WEBHOOK_DELIVERY_ENABLED", true),
+		PollIntervalMs:  getEnvInt("WEBHOOK_POLL_INTERVAL_MS", 1000),
+		BatchSize:       getEnvInt("WEBHOOK_BATCH_SIZE", 20),
+		MaxAttempts:     getEnvInt("WEBHOOK_MAX_ATTEMPTS", 8),
+		LeaseSeconds:    getEnvInt("WEBHOOK_LEASE_SECONDS", 300),
+		TimeoutSeconds:  getEnvInt("WEBHOOK_TIMEOUT_SECONDS", 10),
+
+		AllowPrivateNetworks: getEnvBool("WEBHOOK_ALLOW_PRIVATE_NETWORKS", false),
+	}
+}
+
+func loadCacheConfig() CacheConfig {
+	return CacheConfig{
+		Backend:        getEnv("CACHE_BACKEND", "memory"),
+		TTLSeconds:     getEnvInt("CACHE_TTL_SECONDS", 60),
+		MemoryCapacity: getEnvInt("CACHE_MEMORY_CAPACITY", 10000),
+	}
+}
+
 // Helper functions
 
 func getEnv(key, defaultValue string) string {
@@ -11,6 +11,15 @@ type AppError struct {
 	Message    string `json:"message"`
 	HTTPStatus int    `json:"-"`
 	Err        error  `json:"-"`
+
+	// Fields holds per-field validation failures, if any
+	Fields []FieldError `json:"fields,omitempty"`
+}
+
+// FieldError describes why the value of a single field was rejected
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
 }
 
 func (e *AppError) Error() string {
@@ -20,6 +29,11 @@ func (e *AppError) Error() string {
 	return fmt.Sprintf("%s: %s", e.Code, e.Message)
 }
 
+// Unwrap returns the underlying error, so errors.Is and errors.As see through AppError
+func (e *AppError) Unwrap() error {
+	return e.Err
+}
+
 // WithMessage returns a new AppError with an updated message
 func (e *AppError) WithMessage(message string) *AppError {
 	return &AppError{
@@ -27,6 +41,7 @@ func (e *AppError) WithMessage(message string) *AppError {
 		Message:    message,
 		HTTPStatus: e.HTTPStatus,
 		Err:        e.Err,
+		Fields:     e.Fields,
 	}
 }
 
@@ -37,6 +52,18 @@ func (e *AppError) WithError(err error) *AppError {
 		Message:    e.Message,
 		HTTPStatus: e.HTTPStatus,
 		Err:        err,
+		Fields:     e.Fields,
+	}
+}
+
+// WithFields returns a new AppError carrying per-field validation failures
+func (e *AppError) WithFields(fields []FieldError) *AppError {
+	return &AppError{
+		Code:       e.Code,
+		Message:    e.Message,
+		HTTPStatus: e.HTTPStatus,
+		Err:        e.Err,
+		Fields:     fields,
 	}
 }
 
@@ -81,6 +108,30 @@ var (
 		HTTPStatus: http.StatusForbidden,
 	}
 
+	ErrAccountPending = &AppError{
+		Code:       "ACCOUNT_PENDING",
+		Message:    "Tài khoản chưa được kích hoạt",
+		HTTPStatus: http.StatusForbidden,
+	}
+
+	ErrAccountSuspended = &AppError{
+		Code:       "ACCOUNT_SUSPENDED",
+		Message:    "Tài khoản đang bị tạm ngưng",
+		HTTPStatus: http.StatusForbidden,
+	}
+
+	ErrAccountLocked = &AppError{
+		Code:       "ACCOUNT_LOCKED",
+		Message:    "Tài khoản đã bị khóa",
+		HTTPStatus: http.StatusForbidden,
+	}
+
+	ErrAccountDeactivated = &AppError{
+		Code:       "ACCOUNT_DEACTIVATED",
+		Message:    "Tài khoản đã bị vô hiệu hóa",
+		HTTPStatus: http.StatusForbidden,
+	}
+
 	// 404 Not Found
 	ErrNotFound = &AppError{
 		Code:       "NOT_FOUND",
@@ -113,6 +164,50 @@ var (
 		HTTPStatus: http.StatusConflict,
 	}
 
+	ErrVersionConflict = &AppError{
+		Code:       "VERSION_CONFLICT",
+		Message:    "Dữ liệu đã được người khác thay đổi, vui lòng tải lại và thử lại",
+		HTTPStatus: http.StatusConflict,
+	}
+
+	ErrResourceInUse = &AppError{
+		Code:       "RESOURCE_IN_USE",
+		Message:    "Dữ liệu đang được tham chiếu ở nơi khác",
+		HTTPStatus: http.StatusConflict,
+	}
+
+	ErrInvalidStatusTransition = &AppError{
+		Code:       "INVALID_STATUS_TRANSITION",
+		Message:    "Không thể chuyển trạng thái người dùng",
+		HTTPStatus: http.StatusConflict,
+	}
+
+	// 422 Unprocessable Entity
+	ErrReferenceNotFound = &AppError{
+		Code:       "REFERENCE_NOT_FOUND",
+		Message:    "Dữ liệu được tham chiếu không tồn tại",
+		HTTPStatus: http.StatusUnprocessableEntity,
+	}
+
+	ErrRequiredField = &AppError{
+		Code:       "REQUIRED_FIELD",
+		Message:    "Thiếu dữ liệu bắt buộc",
+		HTTPStatus: http.StatusUnprocessableEntity,
+	}
+
+	ErrConstraintViolation = &AppError{
+		Code:       "CONSTRAINT_VIOLATION",
+		Message:    "Dữ liệu vi phạm ràng buộc",
+		HTTPStatus: http.StatusUnprocessableEntity,
+	}
+
+	// 428 Precondition Required
+	ErrPreconditionRequired = &AppError{
+		Code:       "PRECONDITION_REQUIRED",
+		Message:    "Thiếu header If-Match",
+		HTTPStatus: http.StatusPreconditionRequired,
+	}
+
 	// 429 Too Many Requests
 	ErrTooManyRequests = &AppError{
 		Code:       "TOO_MANY_REQUESTS",
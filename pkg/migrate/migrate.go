@@ -0,0 +1,318 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/thienel/tlog"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// lockKey and lockName identify the Postgres advisory lock and the MySQL named lock held
+// while migrating, so that replicas starting together do not run the same migrations twice
+const (
+	lockKey  int64 = 727_001_337
+	lockName       = "schema_migrations"
+)
+
+// DefaultTable is the table that records applied migrations
+const DefaultTable = "schema_migrations"
+
+// Migration is a numbered schema or data change. Up and Down run in a transaction.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      func(tx *gorm.DB) error
+	// Down is nil for migrations that cannot be reverted
+	Down func(tx *gorm.DB) error
+}
+
+// Status is a migration and when it was applied, if it was
+type Status struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+// record is a row of the migrations table
+type record struct {
+	Version   int64 `gorm:"primaryKey;autoIncrement:false"`
+	Name      string
+	AppliedAt time.Time
+}
+
+// Migrator applies and reverts migrations, recording them in the migrations table
+type Migrator struct {
+	db         *gorm.DB
+	table      string
+	migrations []Migration
+}
+
+// New creates a migrator for the given migrations, which must have distinct versions
+func New(db *gorm.DB, migrations []Migration) (*Migrator, error) {
+	sorted := append([]Migration{}, migrations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Version < sorted[j].Version })
+	for i := 1; i < len(sorted); i++ {
+		if sorted[i].Version == sorted[i-1].Version {
+			return nil, fmt.Errorf("migrate: duplicate version %d", sorted[i].Version)
+		}
+	}
+	return &Migrator{db: db, table: DefaultTable, migrations: sorted}, nil
+}
+
+// Up applies every pending migration in version order and returns the applied ones
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var applied []Migration
+	err := m.locked(ctx, func(conn *gorm.DB) error {
+		done, err := m.appliedVersions(conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range m.migrations {
+			if _, ok := done[migration.Version]; ok {
+				continue
+			}
+			if err := m.run(conn, migration, true); err != nil {
+				return err
+			}
+			applied = append(applied, migration)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down reverts the last steps applied migrations, newest first, and returns the reverted ones
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	var reverted []Migration
+	err := m.locked(ctx, func(conn *gorm.DB) error {
+		done, err := m.appliedVersions(conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0 && len(reverted) < steps; i-- {
+			migration := m.migrations[i]
+			if _, ok := done[migration.Version]; !ok {
+				continue
+			}
+			if migration.Down == nil {
+				return fmt.Errorf("migrate: %d_%s cannot be reverted", migration.Version, migration.Name)
+			}
+			if err := m.run(conn, migration, false); err != nil {
+				return err
+			}
+			reverted = append(reverted, migration)
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// Status returns every known migration with the time it was applied. It takes the
+// migration lock too, as it creates the migrations table if missing, and so waits for
+// migrations in progress elsewhere.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	var done map[int64]time.Time
+	err := m.locked(ctx, func(conn *gorm.DB) error {
+		var err error
+		done, err = m.appliedVersions(conn)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, len(m.migrations))
+	for i, migration := range m.migrations {
+		statuses[i] = Status{Migration: migration}
+		if at, ok := done[migration.Version]; ok {
+			statuses[i].AppliedAt = &at
+		}
+	}
+	return statuses, nil
+}
+
+// Pending returns the migrations that have not been applied yet
+func (m *Migrator) Pending(ctx context.Context) ([]Migration, error) {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []Migration
+	for _, status := range statuses {
+		if status.AppliedAt == nil {
+			pending = append(pending, status.Migration)
+		}
+	}
+	return pending, nil
+}
+
+// locked runs fn on a single connection that holds the migration lock
+func (m *Migrator) locked(ctx context.Context, fn func(conn *gorm.DB) error) error {
+	return m.db.WithContext(ctx).Connection(func(conn *gorm.DB) error {
+		// The connection DB keeps the conditions chained on it; a session starts each
+		// chain afresh, so the migrations table does not leak into migrations
+		conn = conn.Session(&gorm.Session{})
+
+		unlock, err := lock(conn)
+		if err != nil {
+			return fmt.Errorf("migrate: acquire lock: %w", err)
+		}
+		defer func() {
+			if err := unlock(); err != nil {
+				tlog.Warn("Failed to release migration lock", zap.Error(err))
+			}
+		}()
+
+		if err := m.ensureTable(conn); err != nil {
+			return err
+		}
+		return fn(conn)
+	})
+}
+
+// lock takes the session lock of the database of conn, waiting for it if another
+// instance holds it, and returns the function that releases it. SQLite has no such
+// lock; a SQLite database is only ever used by a single instance.
+func lock(conn *gorm.DB) (func() error, error) {
+	switch conn.Dialector.Name() {
+	case "postgres":
+		if err := conn.Exec("SELECT pg_advisory_lock(?)", lockKey).Error; err != nil {
+			return nil, err
+		}
+		return func() error {
+			return conn.Exec("SELECT pg_advisory_unlock(?)", lockKey).Error
+		}, nil
+	case "mysql":
+		// GET_LOCK returns 1 once the lock is held; a negative timeout waits forever
+		var acquired int
+		if err := conn.Raw("SELECT GET_LOCK(?, -1)", lockName).Scan(&acquired).Error; err != nil {
+			return nil, err
+		}
+		if acquired != 1 {
+			return nil, fmt.Errorf("lock %s not acquired", lockName)
+		}
+		return func() error {
+			return conn.Exec("SELECT RELEASE_LOCK(?)", lockName).Error
+		}, nil
+	default:
+		return func() error { return nil }, nil
+	}
+}
+
+func (m *Migrator) ensureTable(conn *gorm.DB) error {
+	return conn.Table(m.table).AutoMigrate(&record{})
+}
+
+func (m *Migrator) appliedVersions(conn *gorm.DB) (map[int64]time.Time, error) {
+	var records []record
+	if err := conn.Table(m.table).Find(&records).Error; err != nil {
+		return nil, err
+	}
+
+	done := make(map[int64]time.Time, len(records))
+	for _, r := range records {
+		done[r.Version] = r.AppliedAt
+	}
+	return done, nil
+}
+
+// run applies or reverts one migration and records it in the same transaction
+func (m *Migrator) run(conn *gorm.DB, migration Migration, up bool) error {
+	direction, fn := "up", migration.Up
+	if !up {
+		direction, fn = "down", migration.Down
+	}
+
+	started := time.Now()
+	err := conn.Transaction(func(tx *gorm.DB) error {
+		if err := fn(tx); err != nil {
+			return err
+		}
+		if up {
+			return tx.Table(m.table).Create(&record{Version: migration.Version, Name: migration.Name, AppliedAt: time.Now()}).Error
+		}
+		return tx.Table(m.table).Where("version = ?", migration.Version).Delete(&record{}).Error
+	})
+	if err != nil {
+		return fmt.Errorf("migrate: %s %d_%s: %w", direction, migration.Version, migration.Name, err)
+	}
+
+	tlog.Info("Migration "+direction,
+		zap.Int64("version", migration.Version),
+		zap.String("name", migration.Name),
+		zap.Duration("duration", time.Since(started)),
+	)
+	return nil
+}
+
+// sqlFilePattern matches migration files such as 0001_create_users.up.sql
+var sqlFilePattern = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// LoadSQL reads migrations from the .up.sql and .down.sql files in the root of fsys.
+// A migration without a .down.sql file cannot be reverted.
+func LoadSQL(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]*Migration)
+	var versions []int64
+	for _, entry := range entries {
+		match := sqlFilePattern.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("migrate: %s: %w", entry.Name(), err)
+		}
+		data, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+			versions = append(versions, version)
+		} else if migration.Name != match[2] {
+			return nil, fmt.Errorf("migrate: version %d has two names, %s and %s", version, migration.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			migration.Up = execSQL(string(data))
+		} else {
+			migration.Down = execSQL(string(data))
+		}
+	}
+
+	migrations := make([]Migration, 0, len(versions))
+	for _, version := range versions {
+		migration := byVersion[version]
+		if migration.Up == nil {
+			return nil, fmt.Errorf("migrate: version %d has no .up.sql file", version)
+		}
+		migrations = append(migrations, *migration)
+	}
+	return migrations, nil
+}
+
+// execSQL returns a migration step that runs a SQL script; Postgres accepts several
+// statements in one simple query
+func execSQL(script string) func(tx *gorm.DB) error {
+	return func(tx *gorm.DB) error {
+		return tx.Exec(script).Error
+	}
+}
@@ -0,0 +1,239 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"testing/fstest"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	// Every connection to :memory: is a database of its own
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	return db
+}
+
+// tableMigration creates a table on the way up and drops it on the way down, noting
+// each step in log
+func tableMigration(version int64, table string, log *[]string) Migration {
+	return Migration{
+		Version: version,
+		Name:    "create_" + table,
+		Up: func(tx *gorm.DB) error {
+			*log = append(*log, "up "+table)
+			return tx.Exec("CREATE TABLE " + table + " (id INTEGER PRIMARY KEY)").Error
+		},
+		Down: func(tx *gorm.DB) error {
+			*log = append(*log, "down "+table)
+			return tx.Exec("DROP TABLE " + table).Error
+		},
+	}
+}
+
+func versions(migrations []Migration) []int64 {
+	out := make([]int64, len(migrations))
+	for i, migration := range migrations {
+		out[i] = migration.Version
+	}
+	return out
+}
+
+func TestNewRejectsDuplicateVersions(t *testing.T) {
+	var log []string
+	_, err := New(openTestDB(t), []Migration{tableMigration(2, "a", &log), tableMigration(1, "b", &log), tableMigration(2, "c", &log)})
+	if err == nil {
+		t.Fatal("New succeeded, want a duplicate version error")
+	}
+}
+
+func TestUpAndDown(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	var log []string
+	// Out of order, as they may be listed
+	m, err := New(db, []Migration{tableMigration(3, "c", &log), tableMigration(1, "a", &log), tableMigration(2, "b", &log)})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	applied, err := m.Up(ctx)
+	if err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	if got := versions(applied); !reflect.DeepEqual(got, []int64{1, 2, 3}) {
+		t.Errorf("applied %v, want [1 2 3]", got)
+	}
+
+	applied, err = m.Up(ctx)
+	if err != nil {
+		t.Fatalf("second Up: %v", err)
+	}
+	if len(applied) != 0 {
+		t.Errorf("second Up applied %v, want none", versions(applied))
+	}
+
+	reverted, err := m.Down(ctx, 2)
+	if err != nil {
+		t.Fatalf("Down: %v", err)
+	}
+	if got := versions(reverted); !reflect.DeepEqual(got, []int64{3, 2}) {
+		t.Errorf("reverted %v, want [3 2]", got)
+	}
+
+	pending, err := m.Pending(ctx)
+	if err != nil {
+		t.Fatalf("Pending: %v", err)
+	}
+	if got := versions(pending); !reflect.DeepEqual(got, []int64{2, 3}) {
+		t.Errorf("pending %v, want [2 3]", got)
+	}
+
+	want := []string{"up a", "up b", "up c", "down c", "down b"}
+	if !reflect.DeepEqual(log, want) {
+		t.Errorf("steps %v, want %v", log, want)
+	}
+	if !db.Migrator().HasTable("a") || db.Migrator().HasTable("b") {
+		t.Error("tables do not match the applied migrations")
+	}
+}
+
+func TestUpStopsAtFailedMigration(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	var log []string
+	failing := Migration{
+		Version: 2,
+		Name:    "fail",
+		Up: func(tx *gorm.DB) error {
+			if err := tx.Exec("CREATE TABLE partial (id INTEGER)").Error; err != nil {
+				return err
+			}
+			return errors.New("boom")
+		},
+	}
+	m, err := New(db, []Migration{tableMigration(1, "a", &log), failing, tableMigration(3, "c", &log)})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	if _, err := m.Up(ctx); err == nil {
+		t.Fatal("Up succeeded, want the migration error")
+	}
+	pending, err := m.Pending(ctx)
+	if err != nil {
+		t.Fatalf("Pending: %v", err)
+	}
+	if got := versions(pending); !reflect.DeepEqual(got, []int64{2, 3}) {
+		t.Errorf("pending %v, want [2 3]", got)
+	}
+	if db.Migrator().HasTable("partial") {
+		t.Error("failed migration was not rolled back")
+	}
+}
+
+func TestDownRefusesIrreversibleMigration(t *testing.T) {
+	ctx := context.Background()
+	var log []string
+	irreversible := tableMigration(2, "b", &log)
+	irreversible.Down = nil
+	m, err := New(openTestDB(t), []Migration{tableMigration(1, "a", &log), irreversible})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if _, err := m.Up(ctx); err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+
+	if _, err := m.Down(ctx, 1); err == nil {
+		t.Fatal("Down succeeded, want an error")
+	}
+	pending, err := m.Pending(ctx)
+	if err != nil {
+		t.Fatalf("Pending: %v", err)
+	}
+	if len(pending) != 0 {
+		t.Errorf("pending %v, want none", versions(pending))
+	}
+}
+
+func TestLoadSQL(t *testing.T) {
+	sql := &fstest.MapFile{Data: []byte("SELECT 1")}
+
+	tests := []struct {
+		name     string
+		files    fstest.MapFS
+		want     []int64
+		wantDown []bool
+		wantErr  bool
+	}{
+		{
+			name: "pairs files by version",
+			files: fstest.MapFS{
+				"0002_add_index.up.sql":      sql,
+				"0001_create_users.up.sql":   sql,
+				"0001_create_users.down.sql": sql,
+				"README.md":                  sql,
+				"0003_notes.sql":             sql,
+			},
+			want:     []int64{1, 2},
+			wantDown: []bool{true, false},
+		},
+		{
+			name:    "down without up",
+			files:   fstest.MapFS{"0001_create_users.down.sql": sql},
+			wantErr: true,
+		},
+		{
+			name: "two names for a version",
+			files: fstest.MapFS{
+				"0001_create_users.up.sql":    sql,
+				"0001_create_people.down.sql": sql,
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			migrations, err := LoadSQL(tt.files)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("LoadSQL = %v, want an error", versions(migrations))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadSQL: %v", err)
+			}
+
+			// LoadSQL leaves ordering to New
+			m, err := New(nil, migrations)
+			if err != nil {
+				t.Fatalf("New: %v", err)
+			}
+			if got := versions(m.migrations); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("versions %v, want %v", got, tt.want)
+			}
+			for i, migration := range m.migrations {
+				if hasDown := migration.Down != nil; hasDown != tt.wantDown[i] {
+					t.Errorf("version %d has down = %v, want %v", migration.Version, hasDown, tt.wantDown[i])
+				}
+			}
+		})
+	}
+}
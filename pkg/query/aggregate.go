@@ -0,0 +1,177 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+
+	apperror "github.com/thienel/go-backend-template/pkg/error"
+)
+
+// Aggregate functions
+const (
+	AggregateCount = "count"
+	AggregateMin   = "min"
+	AggregateMax   = "max"
+)
+
+// maxFacetValues caps the number of values returned per facet, most frequent first
+const maxFacetValues = 50
+
+// Aggregate is a summary of a field over all matching rows; Field is empty for a row count
+type Aggregate struct {
+	Func  string `json:"func"`
+	Field string `json:"field,omitempty"`
+}
+
+// FacetCount is the number of matching rows with a given value
+type FacetCount struct {
+	Value any   `json:"value"`
+	Count int64 `json:"count"`
+}
+
+// parseFacetsParam parses facets=role,status
+func parseFacetsParam(value string, schema Schema, opts *QueryOptions) []apperror.FieldError {
+	var errs []apperror.FieldError
+	for _, name := range splitList(value) {
+		field, ok := schema.Lookup(name)
+		if !ok || !field.Facetable || field.Virtual {
+			errs = append(errs, apperror.FieldError{Field: "facets", Message: "Không thể thống kê theo trường " + name})
+			continue
+		}
+		opts.Facets = appendUnique(opts.Facets, name)
+	}
+	return errs
+}
+
+// parseAggregateParam parses aggregate=count,min:created_at,max:created_at,count:last_login_at
+func parseAggregateParam(value string, schema Schema, opts *QueryOptions) []apperror.FieldError {
+	var errs []apperror.FieldError
+	for _, item := range splitList(value) {
+		fn, name, _ := strings.Cut(item, ":")
+		agg := Aggregate{Func: strings.ToLower(fn), Field: name}
+
+		switch agg.Func {
+		case AggregateCount:
+		case AggregateMin, AggregateMax:
+			if agg.Field == "" {
+				errs = append(errs, apperror.FieldError{Field: "aggregate", Message: "Hàm " + agg.Func + " cần tên trường"})
+				continue
+			}
+		default:
+			errs = append(errs, apperror.FieldError{Field: "aggregate", Message: "Hàm tổng hợp " + fn + " không được hỗ trợ"})
+			continue
+		}
+
+		if agg.Field != "" {
+			field, ok := schema.Lookup(agg.Field)
+			if !ok || !field.Aggregatable || field.Virtual {
+				errs = append(errs, apperror.FieldError{Field: "aggregate", Message: "Không thể tổng hợp theo trường " + agg.Field})
+				continue
+			}
+		}
+		opts.Aggregates = append(opts.Aggregates, agg)
+	}
+	return errs
+}
+
+// ComputeFacets counts the rows of the filtered query db per value of each facet field
+func ComputeFacets(db *gorm.DB, opts QueryOptions, schema Schema) (map[string][]FacetCount, error) {
+	if len(opts.Facets) == 0 {
+		return nil, nil
+	}
+
+	facets := make(map[string][]FacetCount, len(opts.Facets))
+	for _, name := range opts.Facets {
+		column, ok := schema.column(name)
+		if !ok {
+			continue
+		}
+
+		var rows []map[string]any
+		if err := db.Session(&gorm.Session{}).
+			Select(column + " AS value, COUNT(*) AS count").
+			Group(column).
+			Order("count DESC").
+			Limit(maxFacetValues).
+			Scan(&rows).Error; err != nil {
+			return nil, err
+		}
+
+		counts := make([]FacetCount, len(rows))
+		for i, row := range rows {
+			counts[i] = FacetCount{Value: row["value"], Count: toInt64(row["count"])}
+		}
+		facets[name] = counts
+	}
+	return facets, nil
+}
+
+// ComputeAggregates summarizes the rows of the filtered query db in a single query.
+// The result maps "count" to the row count and "<func>" to the values per field, e.g.
+// {"count": 42, "min": {"created_at": ...}, "count_of": {"last_login_at": 30}}.
+func ComputeAggregates(db *gorm.DB, opts QueryOptions, schema Schema) (map[string]any, error) {
+	if len(opts.Aggregates) == 0 {
+		return nil, nil
+	}
+
+	var selects []string
+	var valid []Aggregate
+	for _, agg := range opts.Aggregates {
+		expr := "COUNT(*)"
+		if agg.Field != "" {
+			column, ok := schema.column(agg.Field)
+			if !ok {
+				continue
+			}
+			expr = fmt.Sprintf("%s(%s)", strings.ToUpper(agg.Func), column)
+		}
+		selects = append(selects, fmt.Sprintf("%s AS agg_%d", expr, len(valid)))
+		valid = append(valid, agg)
+	}
+	if len(selects) == 0 {
+		return nil, nil
+	}
+
+	var row map[string]any
+	if err := db.Session(&gorm.Session{}).Select(strings.Join(selects, ", ")).Limit(1).Scan(&row).Error; err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]any)
+	for i, agg := range valid {
+		value := row[fmt.Sprintf("agg_%d", i)]
+
+		if agg.Field == "" {
+			result[AggregateCount] = toInt64(value)
+			continue
+		}
+
+		key := agg.Func
+		if agg.Func == AggregateCount {
+			key = "count_of"
+			value = toInt64(value)
+		}
+		byField, _ := result[key].(map[string]any)
+		if byField == nil {
+			byField = make(map[string]any)
+			result[key] = byField
+		}
+		byField[agg.Field] = value
+	}
+	return result, nil
+}
+
+func toInt64(value any) int64 {
+	switch v := value.(type) {
+	case int64:
+		return v
+	case int32:
+		return int64(v)
+	case int:
+		return int64(v)
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,302 @@
+package query
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gorm.io/gorm"
+
+	apperror "github.com/thienel/go-backend-template/pkg/error"
+)
+
+// keysetTiebreaker is the unique column appended to the sort so that keyset pages never overlap
+const keysetTiebreaker = "id"
+
+var errInvalidCursor = apperror.ErrValidation.WithFields([]apperror.FieldError{
+	{Field: "cursor", Message: "Con trỏ phân trang không hợp lệ"},
+})
+
+var errCursorSortMismatch = apperror.ErrValidation.WithFields([]apperror.FieldError{
+	{Field: "cursor", Message: "Con trỏ phân trang không khớp với thứ tự sắp xếp hiện tại"},
+})
+
+// cursorToken is the decoded form of an opaque cursor: the sort it was issued for, the
+// sort values of the row it points at, and whether it pages backwards from that row
+type cursorToken struct {
+	Sort   string `json:"s"`
+	Values []any  `json:"v"`
+	Before bool   `json:"b,omitempty"`
+}
+
+// Paginate runs a list query, with filters already applied to db, for one page.
+// In offset mode it sorts by opts (or defaultSort) and skips p.Offset rows. In cursor
+// mode it sorts by the same fields plus the id tiebreaker, seeks past the cursor row
+// with a keyset condition and returns cursors for the neighbouring pages.
+// scopes, such as ApplyProjection, apply to the page query but not to the count.
+func Paginate[T any](db *gorm.DB, p Pagination, opts QueryOptions, schema Schema, defaultSort SortField, scopes ...func(*gorm.DB) *gorm.DB) (Page[T], error) {
+	db = db.Session(&gorm.Session{})
+	var page Page[T]
+
+	if p.WithTotal {
+		if err := db.Count(&page.Total).Error; err != nil {
+			return Page[T]{}, err
+		}
+		page.HasTotal = true
+	}
+
+	if !p.IsCursor() {
+		err := db.Scopes(scopes...).Scopes(
+			ApplySort(opts, schema),
+			ApplyDefaultSort(opts, defaultSort.Field, defaultSort.Desc),
+		).Offset(p.Offset).Limit(p.Limit).Find(&page.Items).Error
+		return page, err
+	}
+
+	sort := keysetSort(opts, schema, defaultSort)
+	token, err := decodeCursor(p.Cursor, sort, schema)
+	if err != nil {
+		return Page[T]{}, err
+	}
+
+	columns := make([]string, len(sort))
+	for i, s := range sort {
+		columns[i] = keysetColumn(schema, s.Field)
+	}
+
+	var items []T
+	if err := db.Scopes(scopes...).Scopes(
+		selectColumns(columns),
+		applyKeyset(token, sort, schema),
+	).Limit(p.Limit + 1).Find(&items).Error; err != nil {
+		return Page[T]{}, err
+	}
+
+	hasMore := len(items) > p.Limit
+	if hasMore {
+		items = items[:p.Limit]
+	}
+	if token.Before {
+		for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+			items[i], items[j] = items[j], items[i]
+		}
+	}
+	page.Items = items
+	if len(items) == 0 {
+		return page, nil
+	}
+
+	// Going forward there is a next page if more rows were found and a previous page unless
+	// this is the first page; going backward it is the other way round
+	hasNext, hasPrev := hasMore, p.Cursor != ""
+	if token.Before {
+		hasNext, hasPrev = true, hasMore
+	}
+	if hasNext {
+		if page.NextCursor, err = encodeCursor(db, &items[len(items)-1], sort, schema, false); err != nil {
+			return Page[T]{}, err
+		}
+	}
+	if hasPrev {
+		if page.PrevCursor, err = encodeCursor(db, &items[0], sort, schema, true); err != nil {
+			return Page[T]{}, err
+		}
+	}
+	return page, nil
+}
+
+// keysetSort returns the sort of a cursor query: the allowed sort fields of opts, or the
+// default sort, followed by the tiebreaker
+func keysetSort(opts QueryOptions, schema Schema, defaultSort SortField) []SortField {
+	var sort []SortField
+	for _, s := range opts.Sort {
+		if _, ok := schema.column(s.Field); ok {
+			sort = append(sort, s)
+		}
+	}
+	if len(sort) == 0 {
+		sort = []SortField{defaultSort}
+	}
+
+	for _, s := range sort {
+		if s.Field == keysetTiebreaker {
+			return sort
+		}
+	}
+	return append(sort, SortField{Field: keysetTiebreaker, Desc: sort[len(sort)-1].Desc})
+}
+
+// sortSignature identifies a sort so that a cursor is only accepted for the sort it was issued for
+func sortSignature(sort []SortField) string {
+	parts := make([]string, len(sort))
+	for i, s := range sort {
+		parts[i] = s.Field
+		if s.Desc {
+			parts[i] = "-" + s.Field
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// keysetColumn returns the SQL column of a sort field; the tiebreaker is always usable
+func keysetColumn(schema Schema, field string) string {
+	if column, ok := schema.column(field); ok {
+		return column
+	}
+	return field
+}
+
+func encodeCursor(db *gorm.DB, item any, sort []SortField, schema Schema, before bool) (string, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(item); err != nil {
+		return "", err
+	}
+
+	rv := reflect.ValueOf(item).Elem()
+	values := make([]any, len(sort))
+	for i, s := range sort {
+		column := keysetColumn(schema, s.Field)
+		column = column[strings.LastIndex(column, ".")+1:]
+
+		field := stmt.Schema.LookUpField(column)
+		if field == nil {
+			return "", fmt.Errorf("cursor: no field for column %q", column)
+		}
+		value, _ := field.ValueOf(db.Statement.Context, rv)
+		values[i] = value
+	}
+
+	data, err := json.Marshal(cursorToken{Sort: sortSignature(sort), Values: values, Before: before})
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// decodeCursor decodes an opaque cursor and coerces its values to the sort field types.
+// An empty cursor is the first page.
+func decodeCursor(cursor string, sort []SortField, schema Schema) (cursorToken, error) {
+	if cursor == "" {
+		return cursorToken{}, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return cursorToken{}, errInvalidCursor
+	}
+
+	var token cursorToken
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&token); err != nil {
+		return cursorToken{}, errInvalidCursor
+	}
+	if token.Sort != sortSignature(sort) {
+		return cursorToken{}, errCursorSortMismatch
+	}
+	if len(token.Values) != len(sort) {
+		return cursorToken{}, errInvalidCursor
+	}
+
+	for i, s := range sort {
+		value := token.Values[i]
+		if number, ok := value.(json.Number); ok {
+			value = number.String()
+		}
+
+		field, ok := schema.Lookup(s.Field)
+		if !ok {
+			field = Field{Type: FieldInt}
+		}
+		if value == nil {
+			if !field.Nullable {
+				return cursorToken{}, errInvalidCursor
+			}
+			continue
+		}
+
+		coerced, err := field.coerceScalar(value)
+		if err != nil {
+			return cursorToken{}, errInvalidCursor
+		}
+		token.Values[i] = coerced
+	}
+
+	return token, nil
+}
+
+// applyKeyset returns a GORM scope that orders by sort and, when a cursor is given, keeps
+// only the rows after it (or before it, for a backward cursor). The conditions on
+// nullable fields follow where the database sorts NULLs: last ascending on Postgres,
+// first ascending on MySQL and SQLite.
+func applyKeyset(token cursorToken, sort []SortField, schema Schema) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		nullsFirst := dialectOf(db).nullsFirst()
+
+		if len(token.Values) > 0 {
+			var disjuncts []string
+			var args []any
+			var equalSQL []string
+			var equalArgs []any
+
+			for i, s := range sort {
+				column := keysetColumn(schema, s.Field)
+				field, _ := schema.Lookup(s.Field)
+				value := token.Values[i]
+				desc := s.Desc != token.Before
+				// NULL comes after every value when it sorts last in this direction
+				nullsAfter := desc == nullsFirst
+
+				var after string
+				var afterArgs []any
+				switch {
+				case value == nil && nullsAfter:
+					// Nothing sorts after NULL
+				case value == nil:
+					after = column + " IS NOT NULL"
+				case field.Nullable && nullsAfter:
+					after, afterArgs = "("+column+" "+keysetComparison(desc)+" ? OR "+column+" IS NULL)", []any{value}
+				default:
+					after, afterArgs = column+" "+keysetComparison(desc)+" ?", []any{value}
+				}
+
+				if after != "" {
+					disjuncts = append(disjuncts, "("+strings.Join(append(append([]string{}, equalSQL...), after), " AND ")+")")
+					args = append(append(args, equalArgs...), afterArgs...)
+				}
+
+				if value == nil {
+					equalSQL = append(equalSQL, column+" IS NULL")
+				} else {
+					equalSQL = append(equalSQL, column+" = ?")
+					equalArgs = append(equalArgs, value)
+				}
+			}
+
+			if len(disjuncts) == 0 {
+				return db.Where("1 = 0")
+			}
+			db = db.Where("("+strings.Join(disjuncts, " OR ")+")", args...)
+		}
+
+		for _, s := range sort {
+			direction := "ASC"
+			if s.Desc != token.Before {
+				direction = "DESC"
+			}
+			db = db.Order(fmt.Sprintf("%s %s", keysetColumn(schema, s.Field), direction))
+		}
+		return db
+	}
+}
+
+// keysetComparison returns the operator that keeps values after the cursor value
+func keysetComparison(desc bool) string {
+	if desc {
+		return "<"
+	}
+	return ">"
+}
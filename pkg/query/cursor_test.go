@@ -0,0 +1,184 @@
+package query
+
+import (
+	"encoding/base64"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type cursorRow struct {
+	ID        uint
+	Name      string
+	Score     *int64
+	CreatedAt time.Time
+}
+
+var cursorSchema = Schema{
+	"name":       {Type: FieldString},
+	"score":      {Type: FieldInt, Nullable: true},
+	"created_at": {Type: FieldTime},
+}
+
+// openCursorDB returns a database of rows 1 to 7; rows 3 and 6 have no score and the
+// others share scores in pairs, so pages have to break ties
+func openCursorDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = sqlDB.Close() })
+
+	if err := db.AutoMigrate(&cursorRow{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 1; i <= 7; i++ {
+		row := cursorRow{Name: fmt.Sprintf("row%d", i), CreatedAt: base.Add(time.Duration(i) * time.Hour)}
+		if i%3 != 0 {
+			score := int64(i / 2)
+			row.Score = &score
+		}
+		if err := db.Create(&row).Error; err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+	return db
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	db := openCursorDB(t)
+	var row cursorRow
+	if err := db.First(&row, 2).Error; err != nil {
+		t.Fatalf("find: %v", err)
+	}
+
+	sort := []SortField{{Field: "score", Desc: true}, {Field: "created_at"}, {Field: keysetTiebreaker}}
+	cursor, err := encodeCursor(db, &row, sort, cursorSchema, true)
+	if err != nil {
+		t.Fatalf("encodeCursor: %v", err)
+	}
+
+	token, err := decodeCursor(cursor, sort, cursorSchema)
+	if err != nil {
+		t.Fatalf("decodeCursor: %v", err)
+	}
+	want := []any{*row.Score, row.CreatedAt, int64(row.ID)}
+	if !token.Before || len(token.Values) != len(want) {
+		t.Fatalf("token = %+v, want a backward cursor with values %v", token, want)
+	}
+	for i := range want {
+		if ts, ok := token.Values[i].(time.Time); ok {
+			if !ts.Equal(want[i].(time.Time)) {
+				t.Errorf("value %d = %v, want %v", i, ts, want[i])
+			}
+			continue
+		}
+		if !reflect.DeepEqual(token.Values[i], want[i]) {
+			t.Errorf("value %d = %#v, want %#v", i, token.Values[i], want[i])
+		}
+	}
+}
+
+func TestDecodeCursorRejects(t *testing.T) {
+	sort := []SortField{{Field: "name"}, {Field: keysetTiebreaker}}
+	encode := func(token string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(token))
+	}
+
+	tests := []struct {
+		name   string
+		cursor string
+		want   error
+	}{
+		{name: "not base64", cursor: "%%%", want: errInvalidCursor},
+		{name: "not JSON", cursor: encode("nope"), want: errInvalidCursor},
+		{name: "other sort", cursor: encode(`{"s":"-name,-id","v":["a",1]}`), want: errCursorSortMismatch},
+		{name: "missing values", cursor: encode(`{"s":"name,id","v":["a"]}`), want: errInvalidCursor},
+		{name: "null for a field that is not nullable", cursor: encode(`{"s":"name,id","v":[null,1]}`), want: errInvalidCursor},
+		{name: "value of the wrong type", cursor: encode(`{"s":"name,id","v":["a","b"]}`), want: errInvalidCursor},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := decodeCursor(tt.cursor, sort, cursorSchema); err != tt.want {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestPaginateCursor(t *testing.T) {
+	db := openCursorDB(t)
+
+	tests := []struct {
+		name string
+		sort []SortField
+		want []uint
+	}{
+		{name: "default sort", want: []uint{1, 2, 3, 4, 5, 6, 7}},
+		{name: "descending", sort: []SortField{{Field: "created_at", Desc: true}}, want: []uint{7, 6, 5, 4, 3, 2, 1}},
+		// SQLite sorts NULL first ascending, so last descending
+		{name: "nullable with ties", sort: []SortField{{Field: "score", Desc: true}}, want: []uint{7, 5, 4, 2, 1, 6, 3}},
+		{name: "nullable ascending", sort: []SortField{{Field: "score"}, {Field: "name", Desc: true}}, want: []uint{6, 3, 1, 2, 5, 4, 7}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := NewQueryOptions()
+			opts.Sort = tt.sort
+			paginate := func(cursor string) Page[cursorRow] {
+				t.Helper()
+				p := Pagination{Mode: PaginationCursor, Limit: 3, Cursor: cursor}
+				page, err := Paginate[cursorRow](db.Model(&cursorRow{}), p, opts, cursorSchema, SortField{Field: "id"})
+				if err != nil {
+					t.Fatalf("Paginate: %v", err)
+				}
+				return page
+			}
+
+			// Forward through every page, then back again from the last
+			var forward []uint
+			var pages []Page[cursorRow]
+			for page := paginate(""); ; page = paginate(page.NextCursor) {
+				pages = append(pages, page)
+				forward = append(forward, rowIDs(page.Items)...)
+				if page.NextCursor == "" {
+					break
+				}
+			}
+			if !reflect.DeepEqual(forward, tt.want) {
+				t.Errorf("forward = %v, want %v", forward, tt.want)
+			}
+			if pages[0].PrevCursor != "" {
+				t.Error("first page has a previous cursor")
+			}
+
+			for i := len(pages) - 1; i > 0; i-- {
+				prev := paginate(pages[i].PrevCursor)
+				if got, want := rowIDs(prev.Items), rowIDs(pages[i-1].Items); !reflect.DeepEqual(got, want) {
+					t.Errorf("page %d backward = %v, want %v", i-1, got, want)
+				}
+			}
+		})
+	}
+}
+
+func rowIDs(rows []cursorRow) []uint {
+	ids := make([]uint, len(rows))
+	for i, row := range rows {
+		ids[i] = row.ID
+	}
+	return ids
+}
@@ -0,0 +1,134 @@
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+
+	apperror "github.com/thienel/go-backend-template/pkg/error"
+)
+
+// Dialect names, as reported by the GORM dialector of a connection
+const (
+	DialectPostgres = "postgres"
+	DialectMySQL    = "mysql"
+	DialectSQLite   = "sqlite"
+)
+
+// dialect compiles the parts of a query that differ between databases. Everything else
+// the package writes is SQL that Postgres, MySQL and SQLite all accept.
+type dialect string
+
+// dialectOf returns the dialect of the connection db, Postgres if it cannot be told
+func dialectOf(db *gorm.DB) dialect {
+	if db == nil || db.Dialector == nil {
+		return DialectPostgres
+	}
+	return dialect(db.Dialector.Name())
+}
+
+// unsupported is the client error for an operator the database cannot evaluate
+func (d dialect) unsupported(operator string) error {
+	return apperror.ErrValidation.WithMessage(fmt.Sprintf("Toán tử %q không được hỗ trợ trên cơ sở dữ liệu %s", operator, d))
+}
+
+// like matches column against a LIKE pattern whose wildcards are escaped with a backslash
+func (d dialect) like(column, pattern string, caseSensitive bool) (string, []any) {
+	switch {
+	case d == DialectMySQL && caseSensitive:
+		return fmt.Sprintf("CAST(%s AS BINARY) LIKE CAST(? AS BINARY)", column), []any{pattern}
+	case d == DialectMySQL:
+		// The column collation may be case-sensitive, so both sides are lowered
+		return fmt.Sprintf("LOWER(%s) LIKE LOWER(?)", column), []any{pattern}
+	case d == DialectSQLite:
+		// SQLite has no default escape character; its LIKE ignores ASCII case only
+		return fmt.Sprintf(`%s LIKE ? ESCAPE '\'`, column), []any{pattern}
+	case caseSensitive:
+		return fmt.Sprintf("%s LIKE ?", column), []any{pattern}
+	default:
+		return fmt.Sprintf("%s ILIKE ?", column), []any{pattern}
+	}
+}
+
+// contains matches column values that contain substr, with case
+func (d dialect) contains(column, substr string) (string, []any) {
+	if d == DialectSQLite {
+		// LIKE cannot be made case-sensitive per query in SQLite
+		return fmt.Sprintf("instr(%s, ?) > 0", column), []any{substr}
+	}
+	return d.like(column, "%"+likeEscaper.Replace(substr)+"%", true)
+}
+
+// regex matches column against a regular expression. SQLite has no regular expressions
+// unless an extension is loaded, so they are not supported there.
+func (d dialect) regex(column string, pattern any, caseSensitive bool) (string, []any, error) {
+	switch d {
+	case DialectSQLite:
+		operator := "iregex"
+		if caseSensitive {
+			operator = "regex"
+		}
+		return "", nil, d.unsupported(operator)
+	case DialectMySQL:
+		mode := "i"
+		if caseSensitive {
+			mode = "c"
+		}
+		return fmt.Sprintf("REGEXP_LIKE(%s, ?, '%s')", column, mode), []any{pattern}, nil
+	default:
+		operator := "~*"
+		if caseSensitive {
+			operator = "~"
+		}
+		return fmt.Sprintf("%s %s ?", column, operator), []any{pattern}, nil
+	}
+}
+
+// jsonContains matches JSON columns that contain the JSON document doc. SQLite has no
+// containment operator, so there doc must be an array of scalars, each of which has to
+// be an element of the column.
+func (d dialect) jsonContains(column string, doc any) (string, []any, error) {
+	switch d {
+	case DialectMySQL:
+		return fmt.Sprintf("JSON_CONTAINS(%s, ?)", column), []any{doc}, nil
+	case DialectSQLite:
+		var items []any
+		if err := json.Unmarshal([]byte(fmt.Sprint(doc)), &items); err != nil || len(items) == 0 {
+			return "", nil, d.unsupported("contains")
+		}
+		conditions := make([]string, len(items))
+		for i, item := range items {
+			switch item.(type) {
+			case map[string]any, []any, nil:
+				return "", nil, d.unsupported("contains")
+			}
+			conditions[i] = fmt.Sprintf("EXISTS (SELECT 1 FROM json_each(%s) WHERE json_each.value = ?)", column)
+		}
+		return "(" + strings.Join(conditions, " AND ") + ")", items, nil
+	default:
+		return fmt.Sprintf("%s @> CAST(? AS jsonb)", column), []any{doc}, nil
+	}
+}
+
+// arrayContains matches array columns that contain every item; only Postgres has arrays
+func (d dialect) arrayContains(column string, items []any) (string, []any, error) {
+	if d != DialectPostgres {
+		return "", nil, d.unsupported("contains")
+	}
+	// Each element gets its own placeholder; a single slice argument would be expanded as a list
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(items)), ",")
+	return fmt.Sprintf("%s @> CAST(ARRAY[%s] AS text[])", column, placeholders), items, nil
+}
+
+// nullsFirst reports whether NULL sorts before every value in ascending order. Postgres
+// sorts it last; MySQL and SQLite first.
+func (d dialect) nullsFirst() bool {
+	return d == DialectMySQL || d == DialectSQLite
+}
+
+// fullText reports whether the database has the full-text search SearchConfig describes
+func (d dialect) fullText() bool {
+	return d == DialectPostgres
+}
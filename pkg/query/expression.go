@@ -0,0 +1,347 @@
+package query
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Limits that keep user-supplied expressions cheap to parse and compile
+const (
+	maxExpressionLength     = 2000
+	maxExpressionDepth      = 10
+	maxExpressionConditions = 50
+)
+
+// symbolOperators maps comparison symbols to operator names
+var symbolOperators = map[string]string{
+	"=":  "eq",
+	"!=": "ne",
+	"<>": "ne",
+	">":  "gt",
+	">=": "gte",
+	"<":  "lt",
+	"<=": "lte",
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenString
+	tokenNumber
+	tokenSymbol
+	tokenLParen
+	tokenRParen
+	tokenComma
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// ParseFilterExpression parses the filter= expression language into a FilterExpr, e.g.
+//
+//	status eq 'ACTIVE' and (role in ('ADMIN', 'SYSTEM_ADMIN') or not created_at < '2024-01-01')
+//
+// Conditions are `field operator value`, where operator is any filter operator written as a
+// word (eq, like, between, isnull, ...) or a symbol (=, !=, <>, >, >=, <, <=). Values are
+// quoted strings, numbers, true/false, null or bare words; in, nin and between take a
+// parenthesized list, and isnull/notnull may omit their value. Conditions combine with
+// and, or, not and parentheses; and binds tighter than or.
+func ParseFilterExpression(input string) (FilterExpr, error) {
+	if len(input) > maxExpressionLength {
+		return FilterExpr{}, fmt.Errorf("biểu thức dài quá %d ký tự", maxExpressionLength)
+	}
+
+	tokens, err := tokenize(input)
+	if err != nil {
+		return FilterExpr{}, err
+	}
+
+	p := &expressionParser{tokens: tokens}
+	expr, err := p.parseOr()
+	if err != nil {
+		return FilterExpr{}, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return FilterExpr{}, fmt.Errorf("vị trí %d: thừa %q", tok.pos, tok.text)
+	}
+	return expr, nil
+}
+
+type expressionParser struct {
+	tokens     []token
+	pos        int
+	depth      int
+	conditions int
+}
+
+func (p *expressionParser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *expressionParser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *expressionParser) isKeyword(word string) bool {
+	tok := p.peek()
+	return tok.kind == tokenIdent && strings.EqualFold(tok.text, word)
+}
+
+func (p *expressionParser) parseOr() (FilterExpr, error) {
+	first, err := p.parseAnd()
+	if err != nil {
+		return FilterExpr{}, err
+	}
+
+	children := []FilterExpr{first}
+	for p.isKeyword(LogicOr) {
+		p.next()
+		child, err := p.parseAnd()
+		if err != nil {
+			return FilterExpr{}, err
+		}
+		children = append(children, child)
+	}
+
+	if len(children) == 1 {
+		return first, nil
+	}
+	return Or(children...), nil
+}
+
+func (p *expressionParser) parseAnd() (FilterExpr, error) {
+	first, err := p.parseUnary()
+	if err != nil {
+		return FilterExpr{}, err
+	}
+
+	children := []FilterExpr{first}
+	for p.isKeyword(LogicAnd) {
+		p.next()
+		child, err := p.parseUnary()
+		if err != nil {
+			return FilterExpr{}, err
+		}
+		children = append(children, child)
+	}
+
+	if len(children) == 1 {
+		return first, nil
+	}
+	return And(children...), nil
+}
+
+func (p *expressionParser) parseUnary() (FilterExpr, error) {
+	p.depth++
+	defer func() { p.depth-- }()
+	if p.depth > maxExpressionDepth {
+		return FilterExpr{}, fmt.Errorf("biểu thức lồng quá %d cấp", maxExpressionDepth)
+	}
+
+	if p.isKeyword(LogicNot) {
+		p.next()
+		child, err := p.parseUnary()
+		if err != nil {
+			return FilterExpr{}, err
+		}
+		return Not(child), nil
+	}
+
+	if p.peek().kind == tokenLParen {
+		p.next()
+		expr, err := p.parseOr()
+		if err != nil {
+			return FilterExpr{}, err
+		}
+		if tok := p.next(); tok.kind != tokenRParen {
+			return FilterExpr{}, fmt.Errorf("vị trí %d: thiếu dấu ')'", tok.pos)
+		}
+		return expr, nil
+	}
+
+	return p.parseCondition()
+}
+
+func (p *expressionParser) parseCondition() (FilterExpr, error) {
+	fieldTok := p.next()
+	if fieldTok.kind != tokenIdent || !validFieldName.MatchString(fieldTok.text) {
+		return FilterExpr{}, fmt.Errorf("vị trí %d: cần tên trường", fieldTok.pos)
+	}
+
+	opTok := p.next()
+	var operator string
+	switch {
+	case opTok.kind == tokenSymbol:
+		operator = symbolOperators[opTok.text]
+	case opTok.kind == tokenIdent && IsKnownOperator(strings.ToLower(opTok.text)):
+		operator = strings.ToLower(opTok.text)
+	}
+	if operator == "" {
+		return FilterExpr{}, fmt.Errorf("vị trí %d: toán tử không hợp lệ %q", opTok.pos, opTok.text)
+	}
+
+	p.conditions++
+	if p.conditions > maxExpressionConditions {
+		return FilterExpr{}, fmt.Errorf("biểu thức có quá %d điều kiện", maxExpressionConditions)
+	}
+
+	var value any
+	var err error
+	switch operatorValues[operator] {
+	case valueList, valueRange:
+		value, err = p.parseList()
+	case valueFlag:
+		// The flag is optional: "deleted_at isnull" means "deleted_at isnull true"
+		if p.isKeyword("true") || p.isKeyword("false") {
+			value, err = p.parseValue()
+		}
+	default:
+		value, err = p.parseValue()
+	}
+	if err != nil {
+		return FilterExpr{}, err
+	}
+
+	return Cond(fieldTok.text, operator, value), nil
+}
+
+func (p *expressionParser) parseList() ([]any, error) {
+	if tok := p.next(); tok.kind != tokenLParen {
+		return nil, fmt.Errorf("vị trí %d: danh sách phải đặt trong dấu ngoặc", tok.pos)
+	}
+
+	var values []any
+	for {
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+
+		tok := p.next()
+		if tok.kind == tokenRParen {
+			return values, nil
+		}
+		if tok.kind != tokenComma {
+			return nil, fmt.Errorf("vị trí %d: thiếu dấu ',' hoặc ')'", tok.pos)
+		}
+	}
+}
+
+func (p *expressionParser) parseValue() (any, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokenString:
+		return tok.text, nil
+	case tokenNumber:
+		if i, err := strconv.ParseInt(tok.text, 10, 64); err == nil {
+			return i, nil
+		}
+		f, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("vị trí %d: số không hợp lệ %q", tok.pos, tok.text)
+		}
+		return f, nil
+	case tokenIdent:
+		switch strings.ToLower(tok.text) {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		case "null":
+			return nil, nil
+		}
+		return tok.text, nil
+	default:
+		return nil, fmt.Errorf("vị trí %d: cần giá trị", tok.pos)
+	}
+}
+
+func tokenize(input string) ([]token, error) {
+	var tokens []token
+	runes := []rune(input)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{kind: tokenLParen, text: "(", pos: i})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokenRParen, text: ")", pos: i})
+			i++
+		case r == ',':
+			tokens = append(tokens, token{kind: tokenComma, text: ",", pos: i})
+			i++
+		case r == '\'' || r == '"':
+			text, end, err := readQuoted(runes, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokenString, text: text, pos: i})
+			i = end
+		case strings.ContainsRune("=!<>", r):
+			start := i
+			i++
+			if i < len(runes) && strings.ContainsRune("=>", runes[i]) {
+				i++
+			}
+			text := string(runes[start:i])
+			if _, ok := symbolOperators[text]; !ok {
+				return nil, fmt.Errorf("vị trí %d: ký hiệu không hợp lệ %q", start, text)
+			}
+			tokens = append(tokens, token{kind: tokenSymbol, text: text, pos: start})
+		case r == '-' || unicode.IsDigit(r):
+			start := i
+			i++
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: string(runes[start:i]), pos: start})
+		case r == '_' || unicode.IsLetter(r):
+			start := i
+			for i < len(runes) && (runes[i] == '_' || unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i])) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: string(runes[start:i]), pos: start})
+		default:
+			return nil, fmt.Errorf("vị trí %d: ký tự không hợp lệ %q", i, r)
+		}
+	}
+
+	return append(tokens, token{kind: tokenEOF, pos: len(runes)}), nil
+}
+
+// readQuoted reads a quoted string starting at runes[start]; the quote is escaped by doubling it
+func readQuoted(runes []rune, start int) (string, int, error) {
+	quote := runes[start]
+	var sb strings.Builder
+
+	for i := start + 1; i < len(runes); i++ {
+		if runes[i] != quote {
+			sb.WriteRune(runes[i])
+			continue
+		}
+		if i+1 < len(runes) && runes[i+1] == quote {
+			sb.WriteRune(quote)
+			i++
+			continue
+		}
+		return sb.String(), i + 1, nil
+	}
+
+	return "", 0, fmt.Errorf("vị trí %d: chuỗi chưa đóng", start)
+}
@@ -0,0 +1,110 @@
+package query
+
+// Logical operators of filter groups
+const (
+	LogicAnd = "and"
+	LogicOr  = "or"
+	LogicNot = "not"
+)
+
+// FilterExpr is a node of a boolean filter expression tree. A node is either a group
+// (Logic set, combining Children) or a single condition (Field, Operator, Value).
+type FilterExpr struct {
+	Logic    string       `json:"logic,omitempty"`
+	Children []FilterExpr `json:"children,omitempty"`
+
+	Field    string `json:"field,omitempty"`
+	Operator string `json:"operator,omitempty"` // see operatorValues
+	Value    any    `json:"value,omitempty"`
+}
+
+// And groups expressions that must all hold
+func And(children ...FilterExpr) FilterExpr {
+	return FilterExpr{Logic: LogicAnd, Children: children}
+}
+
+// Or groups expressions of which at least one must hold
+func Or(children ...FilterExpr) FilterExpr {
+	return FilterExpr{Logic: LogicOr, Children: children}
+}
+
+// Not negates an expression
+func Not(child FilterExpr) FilterExpr {
+	return FilterExpr{Logic: LogicNot, Children: []FilterExpr{child}}
+}
+
+// Cond creates a single condition
+func Cond(field, operator string, value any) FilterExpr {
+	return FilterExpr{Field: field, Operator: operator, Value: value}
+}
+
+// IsGroup reports whether the expression is a group rather than a condition
+func (e FilterExpr) IsGroup() bool {
+	return e.Logic != ""
+}
+
+// IsEmpty reports whether the expression contains no conditions
+func (e FilterExpr) IsEmpty() bool {
+	if !e.IsGroup() {
+		return e.Field == ""
+	}
+	for _, child := range e.Children {
+		if !child.IsEmpty() {
+			return false
+		}
+	}
+	return true
+}
+
+// Walk calls fn for every condition in the expression
+func (e FilterExpr) Walk(fn func(cond FilterExpr)) {
+	if !e.IsGroup() {
+		if e.Field != "" {
+			fn(e)
+		}
+		return
+	}
+	for _, child := range e.Children {
+		child.Walk(fn)
+	}
+}
+
+// MapValues returns a copy of the expression in which fn has replaced every value that
+// field is compared against, each element of a list separately. Null values and the
+// flags of isnull and notnull are left as they are.
+func (e FilterExpr) MapValues(field string, fn func(value any) (any, error)) (FilterExpr, error) {
+	if e.IsGroup() {
+		children := make([]FilterExpr, len(e.Children))
+		for i, child := range e.Children {
+			mapped, err := child.MapValues(field, fn)
+			if err != nil {
+				return FilterExpr{}, err
+			}
+			children[i] = mapped
+		}
+		e.Children = children
+		return e, nil
+	}
+
+	if e.Field != field || e.Value == nil || operatorValues[e.Operator] == valueFlag {
+		return e, nil
+	}
+	if items, ok := e.Value.([]any); ok {
+		mapped := make([]any, len(items))
+		for i, item := range items {
+			value, err := fn(item)
+			if err != nil {
+				return FilterExpr{}, err
+			}
+			mapped[i] = value
+		}
+		e.Value = mapped
+		return e, nil
+	}
+	value, err := fn(e.Value)
+	if err != nil {
+		return FilterExpr{}, err
+	}
+	e.Value = value
+	return e, nil
+}
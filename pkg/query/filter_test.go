@@ -0,0 +1,70 @@
+package query
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFilterExprMapValues(t *testing.T) {
+	expr := And(
+		Cond("owner", "eq", "a"),
+		Or(
+			Cond("owner", "in", []any{"b", "c"}),
+			Not(Cond("owner", "isnull", true)),
+			Cond("owner", "ne", nil),
+		),
+		Cond("name", "eq", "a"),
+	)
+
+	got, err := expr.MapValues("owner", func(value any) (any, error) {
+		return strings.ToUpper(value.(string)), nil
+	})
+	if err != nil {
+		t.Fatalf("MapValues: %v", err)
+	}
+
+	want := And(
+		Cond("owner", "eq", "A"),
+		Or(
+			Cond("owner", "in", []any{"B", "C"}),
+			Not(Cond("owner", "isnull", true)),
+			Cond("owner", "ne", nil),
+		),
+		Cond("name", "eq", "a"),
+	)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("MapValues = %+v, want %+v", got, want)
+	}
+	if expr.Children[0].Value != "a" {
+		t.Errorf("MapValues changed the original expression")
+	}
+}
+
+func TestFindFilter(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter FilterExpr
+		found  bool
+	}{
+		{name: "single condition", filter: Cond("q", "eq", "x"), found: true},
+		{name: "top-level AND", filter: And(Cond("age", "eq", 1), Cond("q", "eq", "x")), found: true},
+		{name: "nested AND", filter: And(Cond("age", "eq", 1), And(Cond("q", "eq", "x"))), found: true},
+		{name: "in OR", filter: Or(Cond("q", "eq", "x"), Cond("age", "eq", 1))},
+		{name: "in NOT", filter: And(Not(Cond("q", "eq", "x")))},
+		{name: "absent", filter: And(Cond("age", "eq", 1))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := QueryOptions{Filter: tt.filter}
+			cond, ok := opts.FindFilter("q")
+			if ok != tt.found {
+				t.Fatalf("found = %v, want %v", ok, tt.found)
+			}
+			if ok && cond.Value != "x" {
+				t.Errorf("condition = %+v, want the one on q", cond)
+			}
+		})
+	}
+}
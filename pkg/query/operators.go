@@ -0,0 +1,125 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+)
+
+// operatorValue describes the kind of value an operator takes
+type operatorValue int
+
+const (
+	valueScalar operatorValue = iota // a single value
+	valueList                        // one or more values
+	valueRange                       // exactly two values, lower and upper bound
+	valueFlag                        // an optional boolean, true when omitted
+)
+
+// operatorValues lists every known filter operator:
+//
+//	eq, ne, gt, gte, lt, lte   comparison; eq/ne null match IS NULL / IS NOT NULL
+//	in, nin                    membership in a list
+//	between                    inclusive range, e.g. created_at[between]=2024-01-01,2024-12-31
+//	isnull, notnull            null checks, e.g. last_login_at[isnull]=true
+//	like, clike                substring match, case-insensitive and case-sensitive
+//	startswith, endswith       case-insensitive prefix and suffix match
+//	regex, iregex              regular expression match; not on SQLite
+//	contains                   JSON containment, or array containment on Postgres
+var operatorValues = map[string]operatorValue{
+	"eq":         valueScalar,
+	"ne":         valueScalar,
+	"gt":         valueScalar,
+	"gte":        valueScalar,
+	"lt":         valueScalar,
+	"lte":        valueScalar,
+	"in":         valueList,
+	"nin":        valueList,
+	"between":    valueRange,
+	"isnull":     valueFlag,
+	"notnull":    valueFlag,
+	"like":       valueScalar,
+	"clike":      valueScalar,
+	"startswith": valueScalar,
+	"endswith":   valueScalar,
+	"regex":      valueScalar,
+	"iregex":     valueScalar,
+	"contains":   valueScalar,
+}
+
+// IsKnownOperator reports whether operator is a supported filter operator
+func IsKnownOperator(operator string) bool {
+	_, ok := operatorValues[operator]
+	return ok
+}
+
+// likeEscaper escapes LIKE wildcards so user input is matched literally
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// conditionSQL builds the SQL for a single condition based on operator, in the SQL of
+// dialect d. The value must already be coerced by the field schema.
+func conditionSQL(d dialect, column string, field Field, operator string, value any) (string, []any, error) {
+	switch operator {
+	case "eq":
+		if value == nil {
+			return fmt.Sprintf("%s IS NULL", column), nil, nil
+		}
+		return fmt.Sprintf("%s = ?", column), []any{value}, nil
+	case "ne":
+		if value == nil {
+			return fmt.Sprintf("%s IS NOT NULL", column), nil, nil
+		}
+		return fmt.Sprintf("%s != ?", column), []any{value}, nil
+	case "gt":
+		return fmt.Sprintf("%s > ?", column), []any{value}, nil
+	case "gte":
+		return fmt.Sprintf("%s >= ?", column), []any{value}, nil
+	case "lt":
+		return fmt.Sprintf("%s < ?", column), []any{value}, nil
+	case "lte":
+		return fmt.Sprintf("%s <= ?", column), []any{value}, nil
+	case "in":
+		return fmt.Sprintf("%s IN ?", column), []any{value}, nil
+	case "nin":
+		return fmt.Sprintf("%s NOT IN ?", column), []any{value}, nil
+	case "between":
+		bounds, _ := value.([]any)
+		if len(bounds) != 2 {
+			return "", nil, nil
+		}
+		return fmt.Sprintf("%s BETWEEN ? AND ?", column), bounds, nil
+	case "isnull", "notnull":
+		isNull := value != false
+		if operator == "notnull" {
+			isNull = !isNull
+		}
+		if isNull {
+			return fmt.Sprintf("%s IS NULL", column), nil, nil
+		}
+		return fmt.Sprintf("%s IS NOT NULL", column), nil, nil
+	case "like":
+		sql, args := d.like(column, "%"+likeEscaper.Replace(fmt.Sprint(value))+"%", false)
+		return sql, args, nil
+	case "clike":
+		sql, args := d.contains(column, fmt.Sprint(value))
+		return sql, args, nil
+	case "startswith":
+		sql, args := d.like(column, likeEscaper.Replace(fmt.Sprint(value))+"%", false)
+		return sql, args, nil
+	case "endswith":
+		sql, args := d.like(column, "%"+likeEscaper.Replace(fmt.Sprint(value)), false)
+		return sql, args, nil
+	case "regex", "iregex":
+		return d.regex(column, value, operator == "regex")
+	case "contains":
+		if field.Type == FieldJSON {
+			return d.jsonContains(column, value)
+		}
+		items, _ := value.([]any)
+		if len(items) == 0 {
+			return "", nil, nil
+		}
+		return d.arrayContains(column, items)
+	default:
+		return "", nil, nil
+	}
+}
@@ -1,37 +1,82 @@
 package query
 
-// QueryOptions holds dynamic filter and sort options for list queries
+// QueryOptions holds dynamic filter and sort options for list queries.
+// It serializes to JSON so that it can be stored, e.g. as a saved view.
 type QueryOptions struct {
-	Filters map[string]FilterValue
-	Sort    []SortField
-}
+	Filter FilterExpr  `json:"filter"`
+	Sort   []SortField `json:"sort,omitempty"`
+
+	// Soft-delete visibility: include deleted rows, or return only deleted rows
+	IncludeDeleted bool `json:"include_deleted,omitempty"`
+	OnlyDeleted    bool `json:"only_deleted,omitempty"`
 
-// FilterValue represents a filter with operator and value
-type FilterValue struct {
-	Operator string // eq, ne, gt, gte, lt, lte, like, in, nin
-	Value    any
+	// Sparse fieldset and relations to load (see Projection)
+	Fields  []string `json:"fields,omitempty"`
+	Include []string `json:"include,omitempty"`
+
+	// Facets and aggregates computed over all rows matching the filters
+	Facets     []string    `json:"facets,omitempty"`
+	Aggregates []Aggregate `json:"aggregates,omitempty"`
 }
 
 // SortField represents a single sort criterion
 type SortField struct {
-	Field string
-	Desc  bool
+	Field string `json:"field"`
+	Desc  bool   `json:"desc,omitempty"`
 }
 
 // NewQueryOptions creates a new QueryOptions instance
 func NewQueryOptions() QueryOptions {
 	return QueryOptions{
-		Filters: make(map[string]FilterValue),
-		Sort:    []SortField{},
+		Filter: And(),
+		Sort:   []SortField{},
 	}
 }
 
-// AddFilter adds a filter to the query options
+// AddFilter adds a condition that must hold in addition to all existing filters
 func (q *QueryOptions) AddFilter(field, operator string, value any) {
-	q.Filters[field] = FilterValue{
-		Operator: operator,
-		Value:    value,
+	q.AddFilterExpr(Cond(field, operator, value))
+}
+
+// AddFilterExpr adds an expression that must hold in addition to all existing filters
+func (q *QueryOptions) AddFilterExpr(expr FilterExpr) {
+	if q.Filter.Logic != LogicAnd {
+		if q.Filter.IsEmpty() {
+			q.Filter = And()
+		} else {
+			q.Filter = And(q.Filter)
+		}
 	}
+	q.Filter.Children = append(q.Filter.Children, expr)
+}
+
+// FindFilter returns the first condition on the given field that is AND-ed with the
+// rest of the filter, i.e. reached from the root through AND groups alone
+func (q *QueryOptions) FindFilter(field string) (FilterExpr, bool) {
+	return findAnded(q.Filter, field)
+}
+
+func findAnded(expr FilterExpr, field string) (FilterExpr, bool) {
+	if !expr.IsGroup() {
+		if expr.Field == field {
+			return expr, true
+		}
+		return FilterExpr{}, false
+	}
+	if expr.Logic != LogicAnd {
+		return FilterExpr{}, false
+	}
+	for _, child := range expr.Children {
+		if cond, ok := findAnded(child, field); ok {
+			return cond, true
+		}
+	}
+	return FilterExpr{}, false
+}
+
+// HasFilters reports whether any filter condition is set
+func (q *QueryOptions) HasFilters() bool {
+	return !q.Filter.IsEmpty()
 }
 
 // AddSort adds a sort field to the query options
@@ -41,3 +86,57 @@ func (q *QueryOptions) AddSort(field string, desc bool) {
 		Desc:  desc,
 	})
 }
+
+// MergeOptions combines stored options, such as a saved view, with options given
+// explicitly for one request. Explicit top-level conditions replace the stored ones on
+// the same field, other stored conditions still apply. Explicit sort, fields, includes,
+// facets and aggregates replace the stored ones when given.
+func MergeOptions(base, override QueryOptions) QueryOptions {
+	merged := override
+	merged.Filter = And()
+
+	overridden := make(map[string]bool)
+	override.Filter.Walk(func(cond FilterExpr) {
+		overridden[cond.Field] = true
+	})
+
+	baseConds := []FilterExpr{base.Filter}
+	if base.Filter.Logic == LogicAnd {
+		baseConds = base.Filter.Children
+	}
+	for _, expr := range baseConds {
+		if expr.IsEmpty() || (!expr.IsGroup() && overridden[expr.Field]) {
+			continue
+		}
+		merged.AddFilterExpr(expr)
+	}
+	// Keep top-level conditions flat so that FindFilter still sees them
+	overrideConds := []FilterExpr{override.Filter}
+	if override.Filter.Logic == LogicAnd {
+		overrideConds = override.Filter.Children
+	}
+	for _, expr := range overrideConds {
+		if !expr.IsEmpty() {
+			merged.AddFilterExpr(expr)
+		}
+	}
+
+	if len(merged.Sort) == 0 {
+		merged.Sort = base.Sort
+	}
+	if len(merged.Fields) == 0 {
+		merged.Fields = base.Fields
+	}
+	if len(merged.Include) == 0 {
+		merged.Include = base.Include
+	}
+	if len(merged.Facets) == 0 {
+		merged.Facets = base.Facets
+	}
+	if len(merged.Aggregates) == 0 {
+		merged.Aggregates = base.Aggregates
+	}
+	merged.IncludeDeleted = merged.IncludeDeleted || base.IncludeDeleted
+	merged.OnlyDeleted = merged.OnlyDeleted || base.OnlyDeleted
+	return merged
+}
@@ -0,0 +1,86 @@
+package query
+
+import (
+	"strconv"
+
+	apperror "github.com/thienel/go-backend-template/pkg/error"
+)
+
+// Pagination modes
+const (
+	PaginationOffset = "offset"
+	PaginationCursor = "cursor"
+)
+
+// Pagination selects a page of a list query, either by offset (page/offset and limit)
+// or by an opaque cursor returned with the previous page
+type Pagination struct {
+	Mode   string
+	Offset int
+	Limit  int
+	// Cursor is empty for the first page in cursor mode
+	Cursor string
+	// WithTotal runs a COUNT(*) for the total number of matching rows
+	WithTotal bool
+}
+
+// IsCursor reports whether the pagination is cursor based
+func (p Pagination) IsCursor() bool {
+	return p.Mode == PaginationCursor
+}
+
+// Page is a page of list results
+type Page[T any] struct {
+	Items []T
+	// Total is only set when HasTotal is true
+	Total    int64
+	HasTotal bool
+	// NextCursor and PrevCursor are set in cursor mode when there are more rows that way
+	NextCursor string
+	PrevCursor string
+	// Highlights holds search snippets per item, when the query searched
+	Highlights []map[string]string
+	// Facets and Aggregates hold the results requested in the query options
+	Facets     map[string][]FacetCount
+	Aggregates map[string]any
+}
+
+// ParsePagination extracts the pagination from query params.
+// Offset mode (page or offset, limit) is the default and counts the total unless total=false.
+// Passing cursor, or pagination=cursor for the first page, selects cursor mode, which
+// skips the count unless total=true.
+func ParsePagination(params map[string]string, defaultLimit int) (Pagination, error) {
+	offset, limit := GetPagination(params, defaultLimit)
+	p := Pagination{Mode: PaginationOffset, Offset: offset, Limit: limit}
+
+	switch params["pagination"] {
+	case "", PaginationOffset:
+	case PaginationCursor:
+		p.Mode = PaginationCursor
+	default:
+		return Pagination{}, apperror.ErrValidation.WithFields([]apperror.FieldError{
+			{Field: "pagination", Message: "Chỉ chấp nhận offset hoặc cursor"},
+		})
+	}
+
+	if cursor := params["cursor"]; cursor != "" {
+		p.Mode = PaginationCursor
+		p.Cursor = cursor
+	}
+	if p.IsCursor() {
+		p.Offset = 0
+	}
+
+	p.WithTotal = !p.IsCursor()
+	if total, ok := params["total"]; ok {
+		withTotal, err := strconv.ParseBool(total)
+		if err != nil {
+			return Pagination{}, apperror.ErrValidation.WithFields([]apperror.FieldError{
+				{Field: "total", Message: "Giá trị phải là true hoặc false"},
+			})
+		}
+		p.WithTotal = withTotal
+	}
+
+	return p, nil
+}
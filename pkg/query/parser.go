@@ -1,8 +1,11 @@
 package query
 
 import (
+	"sort"
 	"strconv"
 	"strings"
+
+	apperror "github.com/thienel/go-backend-template/pkg/error"
 )
 
 // parseOperator parses operator from query parameter format: field[op]=value
@@ -20,11 +23,26 @@ func parseOperator(key string) (field, operator string) {
 	return field, operator
 }
 
-// ParseQueryParams parses query parameters into QueryOptions
-func ParseQueryParams(params map[string]string, allowedFields map[string]bool) QueryOptions {
+// ParseQueryParams parses query parameters into QueryOptions.
+// Filters come from field[op]=value parameters, AND-ed together, and from an optional
+// filter=<expression> parameter (see ParseFilterExpression) for OR, NOT and nesting.
+// Filter values are coerced to the field types declared in schema; every rejected
+// condition is reported as a field error of a single validation error.
+// facets=a,b and aggregate=count,min:field,max:field request summaries over the
+// fields that schema marks Facetable and Aggregatable.
+func ParseQueryParams(params map[string]string, schema Schema) (QueryOptions, error) {
 	opts := NewQueryOptions()
+	var fieldErrs []apperror.FieldError
+
+	// Iterate in a stable order so the generated SQL and errors are deterministic
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
 
-	for key, value := range params {
+	for _, key := range keys {
+		value := params[key]
 		if value == "" {
 			continue
 		}
@@ -35,16 +53,122 @@ func ParseQueryParams(params map[string]string, allowedFields map[string]bool) Q
 			continue
 		}
 
-		// Handle filter parameters
+		// Handle soft-delete visibility parameters
+		if key == "include_deleted" || key == "only_deleted" {
+			parseDeletedParam(key, value, &opts)
+			continue
+		}
+
+		// Handle facets and aggregates
+		if key == "facets" {
+			fieldErrs = append(fieldErrs, parseFacetsParam(value, schema, &opts)...)
+			continue
+		}
+		if key == "aggregate" {
+			fieldErrs = append(fieldErrs, parseAggregateParam(value, schema, &opts)...)
+			continue
+		}
+
+		// Handle filter expression
+		if key == "filter" {
+			expr, err := ParseFilterExpression(value)
+			if err != nil {
+				fieldErrs = append(fieldErrs, apperror.FieldError{Field: "filter", Message: "Biểu thức lọc không hợp lệ: " + err.Error()})
+				continue
+			}
+			expr, errs := schema.validate(expr)
+			fieldErrs = append(fieldErrs, errs...)
+			opts.AddFilterExpr(expr)
+			continue
+		}
+
+		// Handle filter parameters; other parameters (page, limit, ...) are not fields
 		field, operator := parseOperator(key)
-		if !allowedFields[field] {
+		if _, ok := schema.Lookup(field); !ok {
+			continue
+		}
+
+		cond, errs := schema.validate(Cond(field, operator, value))
+		fieldErrs = append(fieldErrs, errs...)
+		opts.AddFilterExpr(cond)
+	}
+
+	if len(fieldErrs) > 0 {
+		return QueryOptions{}, apperror.ErrValidation.WithMessage("Tham số lọc không hợp lệ").WithFields(fieldErrs)
+	}
+	return opts, nil
+}
+
+// ValidateOptions checks options that did not come from ParseQueryParams, such as stored
+// ones decoded from JSON, against the schema and coerces filter values to the field types
+func ValidateOptions(opts QueryOptions, schema Schema) (QueryOptions, error) {
+	var fieldErrs []apperror.FieldError
+	if !opts.Filter.IsEmpty() {
+		opts.Filter, fieldErrs = schema.validate(opts.Filter)
+	}
+
+	for _, name := range opts.Facets {
+		if field, ok := schema.Lookup(name); !ok || !field.Facetable || field.Virtual {
+			fieldErrs = append(fieldErrs, apperror.FieldError{Field: "facets", Message: "Không thể thống kê theo trường " + name})
+		}
+	}
+	for _, agg := range opts.Aggregates {
+		if agg.Field == "" {
 			continue
 		}
+		if field, ok := schema.Lookup(agg.Field); !ok || !field.Aggregatable || field.Virtual {
+			fieldErrs = append(fieldErrs, apperror.FieldError{Field: "aggregate", Message: "Không thể tổng hợp theo trường " + agg.Field})
+		}
+	}
 
-		opts.AddFilter(field, operator, value)
+	if len(fieldErrs) > 0 {
+		return QueryOptions{}, apperror.ErrValidation.WithMessage("Tham số lọc không hợp lệ").WithFields(fieldErrs)
 	}
+	return opts, nil
+}
 
-	return opts
+// validate checks every condition of expr against the schema and returns a copy with
+// values coerced to the field types
+func (s Schema) validate(expr FilterExpr) (FilterExpr, []apperror.FieldError) {
+	return s.validateExpr(expr, true)
+}
+
+// validateExpr validates expr; anded reports whether it only narrows the filter it is
+// part of, being reached from the root through AND groups alone. Virtual fields are only
+// allowed there, as repositories find them with FindFilter.
+func (s Schema) validateExpr(expr FilterExpr, anded bool) (FilterExpr, []apperror.FieldError) {
+	if expr.IsGroup() {
+		var errs []apperror.FieldError
+		children := make([]FilterExpr, len(expr.Children))
+		for i, child := range expr.Children {
+			var childErrs []apperror.FieldError
+			children[i], childErrs = s.validateExpr(child, anded && expr.Logic == LogicAnd)
+			errs = append(errs, childErrs...)
+		}
+		expr.Children = children
+		return expr, errs
+	}
+
+	field, ok := s.Lookup(expr.Field)
+	if !ok {
+		return expr, []apperror.FieldError{{Field: expr.Field, Message: "Không thể lọc theo trường này"}}
+	}
+	if field.Virtual && !anded {
+		return expr, []apperror.FieldError{{Field: expr.Field, Message: "Trường này chỉ có thể kết hợp với các điều kiện khác bằng AND"}}
+	}
+	if !IsKnownOperator(expr.Operator) {
+		return expr, []apperror.FieldError{{Field: expr.Field, Message: "Toán tử " + expr.Operator + " không tồn tại"}}
+	}
+	if !field.AllowsOperator(expr.Operator) {
+		return expr, []apperror.FieldError{{Field: expr.Field, Message: "Toán tử " + expr.Operator + " không được hỗ trợ cho trường này"}}
+	}
+
+	value, err := field.coerce(expr.Operator, expr.Value)
+	if err != nil {
+		return expr, []apperror.FieldError{{Field: expr.Field, Message: err.Error()}}
+	}
+	expr.Value = value
+	return expr, nil
 }
 
 // parseSortParam parses sort parameter: sort=field1,-field2 (- prefix for DESC)
@@ -66,6 +190,21 @@ func parseSortParam(value string, opts *QueryOptions) {
 	}
 }
 
+// parseDeletedParam parses include_deleted=true / only_deleted=true
+func parseDeletedParam(key, value string, opts *QueryOptions) {
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		return
+	}
+
+	switch key {
+	case "include_deleted":
+		opts.IncludeDeleted = enabled
+	case "only_deleted":
+		opts.OnlyDeleted = enabled
+	}
+}
+
 // GetPagination extracts offset and limit from query params
 func GetPagination(params map[string]string, defaultLimit int) (offset, limit int) {
 	limit = defaultLimit
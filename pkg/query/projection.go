@@ -0,0 +1,147 @@
+package query
+
+import (
+	"encoding/json"
+	"strings"
+
+	"gorm.io/gorm"
+
+	apperror "github.com/thienel/go-backend-template/pkg/error"
+)
+
+// Relation describes a related entity that clients may load with include=
+type Relation struct {
+	// Association is the GORM association name on the model, e.g. "StatusHistory"
+	Association string
+	// Order optionally orders the related rows, e.g. "created_at DESC"
+	Order string
+}
+
+// Projection declares what clients may select with fields= and load with include=
+type Projection struct {
+	// Fields maps selectable fields, named as in the JSON output, to their columns
+	Fields map[string]string
+	// Required lists columns that are always selected, such as the primary key that
+	// relations are loaded by
+	Required []string
+	// Relations maps include names, also used as JSON keys, to associations
+	Relations map[string]Relation
+}
+
+// ParseProjectionParams parses fields=a,b and include=x,y into opts, rejecting names
+// the projection does not allow
+func ParseProjectionParams(params map[string]string, projection Projection, opts *QueryOptions) error {
+	var fieldErrs []apperror.FieldError
+
+	for _, name := range splitList(params["fields"]) {
+		if _, ok := projection.Fields[name]; !ok {
+			fieldErrs = append(fieldErrs, apperror.FieldError{Field: "fields", Message: "Không thể chọn trường " + name})
+			continue
+		}
+		opts.Fields = append(opts.Fields, name)
+	}
+
+	for _, name := range splitList(params["include"]) {
+		if _, ok := projection.Relations[name]; !ok {
+			fieldErrs = append(fieldErrs, apperror.FieldError{Field: "include", Message: "Không thể tải kèm " + name})
+			continue
+		}
+		opts.Include = append(opts.Include, name)
+	}
+
+	if len(fieldErrs) > 0 {
+		return apperror.ErrValidation.WithFields(fieldErrs)
+	}
+	return nil
+}
+
+// ApplyProjection returns a GORM scope that narrows the SELECT to the requested fields and
+// preloads the requested relations
+func ApplyProjection(opts QueryOptions, projection Projection) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if len(opts.Fields) > 0 {
+			columns := append([]string{}, projection.Required...)
+			for _, name := range opts.Fields {
+				if column, ok := projection.Fields[name]; ok {
+					columns = appendUnique(columns, column)
+				}
+			}
+			db = db.Select(columns)
+		}
+
+		for _, name := range opts.Include {
+			relation, ok := projection.Relations[name]
+			if !ok {
+				continue
+			}
+			if relation.Order == "" {
+				db = db.Preload(relation.Association)
+				continue
+			}
+			order := relation.Order
+			db = db.Preload(relation.Association, func(tx *gorm.DB) *gorm.DB {
+				return tx.Order(order)
+			})
+		}
+		return db
+	}
+}
+
+// PickFields narrows the JSON encoding of v to the requested fields, included relations
+// and search highlights. Without requested fields v is returned unchanged.
+func PickFields(v any, opts QueryOptions) (any, error) {
+	if len(opts.Fields) == 0 {
+		return v, nil
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, err
+	}
+
+	picked := make(map[string]json.RawMessage, len(opts.Fields)+len(opts.Include))
+	for _, name := range append(append([]string{"highlights"}, opts.Fields...), opts.Include...) {
+		if value, ok := all[name]; ok {
+			picked[name] = value
+		}
+	}
+	return picked, nil
+}
+
+// selectColumns returns a GORM scope that adds columns to a narrowed SELECT, so that
+// values needed after the query, such as cursor sort keys, are always loaded
+func selectColumns(columns []string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if len(db.Statement.Selects) == 0 {
+			return db
+		}
+		selects := db.Statement.Selects
+		for _, column := range columns {
+			selects = appendUnique(selects, column)
+		}
+		return db.Select(selects)
+	}
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func appendUnique(items []string, item string) []string {
+	for _, existing := range items {
+		if existing == item {
+			return items
+		}
+	}
+	return append(items, item)
+}
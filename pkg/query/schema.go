@@ -0,0 +1,260 @@
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FieldType is the value type of a filterable field
+type FieldType string
+
+// Supported field types
+const (
+	FieldString FieldType = "string"
+	FieldInt    FieldType = "int"
+	FieldBool   FieldType = "bool"
+	FieldTime   FieldType = "time"
+	FieldEnum   FieldType = "enum"
+	FieldUUID   FieldType = "uuid"
+	FieldArray  FieldType = "array" // Postgres text array
+	FieldJSON   FieldType = "json"  // JSON document; JSONB on Postgres
+)
+
+// defaultOperators lists the operators a field accepts when it does not declare its own.
+// Every other operator, and any operator on array and JSON fields, must be enabled
+// through Field.Operators.
+var defaultOperators = map[FieldType][]string{
+	FieldString: {"eq", "ne", "like", "in", "nin"},
+	FieldInt:    {"eq", "ne", "gt", "gte", "lt", "lte", "in", "nin"},
+	FieldBool:   {"eq", "ne"},
+	FieldTime:   {"eq", "ne", "gt", "gte", "lt", "lte"},
+	FieldEnum:   {"eq", "ne", "in", "nin"},
+	FieldUUID:   {"eq", "ne", "in", "nin"},
+}
+
+// timeLayouts lists the accepted formats of time values, most specific first
+var timeLayouts = []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02"}
+
+// Field describes a field that can be filtered and sorted on
+type Field struct {
+	Type FieldType
+	// Column is the SQL column the field maps to; defaults to the field name
+	Column string
+	// Operators overrides the default operators of the field type
+	Operators []string
+	// Values lists the allowed values of an enum field
+	Values []string
+	// Virtual fields are accepted by the parser but handled by the repository itself
+	Virtual bool
+	// Nullable fields may hold NULL, which cursor pagination has to account for
+	Nullable bool
+	// Facetable fields can be grouped with facets= for counts per value
+	Facetable bool
+	// Aggregatable fields can be summarized with aggregate=min:field,max:field,count:field
+	Aggregatable bool
+}
+
+// Schema maps public field names to their definitions
+type Schema map[string]Field
+
+// Lookup returns the definition of a field
+func (s Schema) Lookup(name string) (Field, bool) {
+	field, ok := s[name]
+	return field, ok
+}
+
+// column returns the SQL column of a field, if it can be used in SQL
+func (s Schema) column(name string) (string, bool) {
+	field, ok := s[name]
+	if !ok || field.Virtual {
+		return "", false
+	}
+	if field.Column != "" {
+		return field.Column, true
+	}
+	return name, true
+}
+
+// AllowsOperator reports whether the field accepts the operator
+func (f Field) AllowsOperator(operator string) bool {
+	operators := f.Operators
+	if operators == nil {
+		operators = defaultOperators[f.Type]
+	}
+	for _, op := range operators {
+		if op == operator {
+			return true
+		}
+	}
+	return false
+}
+
+// coerce converts a raw value to the field type according to the kind of value the
+// operator takes. A raw string is split on commas where a list is expected; null is only
+// accepted by eq and ne.
+func (f Field) coerce(operator string, value any) (any, error) {
+	switch operatorValues[operator] {
+	case valueFlag:
+		if value == nil {
+			return true, nil
+		}
+		return Field{Type: FieldBool}.coerceScalar(value)
+	case valueList:
+		return f.coerceList(value)
+	case valueRange:
+		bounds, err := f.coerceList(value)
+		if err != nil {
+			return nil, err
+		}
+		if len(bounds) != 2 {
+			return nil, fmt.Errorf("Toán tử %s cần đúng 2 giá trị", operator)
+		}
+		return bounds, nil
+	}
+
+	if value == nil {
+		if operator == "eq" || operator == "ne" {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("Toán tử %s không nhận giá trị null", operator)
+	}
+
+	switch operator {
+	case "contains":
+		return f.coerceContains(value)
+	case "like", "clike", "startswith", "endswith":
+		if _, ok := value.([]any); ok {
+			return nil, fmt.Errorf("Toán tử %s không nhận danh sách", operator)
+		}
+		return fmt.Sprint(value), nil
+	case "regex", "iregex":
+		pattern := fmt.Sprint(value)
+		if _, err := regexp.Compile(pattern); err != nil {
+			return nil, fmt.Errorf("Biểu thức chính quy %q không hợp lệ", pattern)
+		}
+		return pattern, nil
+	}
+
+	if _, ok := value.([]any); ok {
+		return nil, fmt.Errorf("Toán tử %s không nhận danh sách", operator)
+	}
+	return f.coerceScalar(value)
+}
+
+func (f Field) coerceList(value any) ([]any, error) {
+	var items []any
+	switch v := value.(type) {
+	case []any:
+		items = v
+	case string:
+		for _, part := range strings.Split(v, ",") {
+			items = append(items, strings.TrimSpace(part))
+		}
+	case nil:
+	default:
+		items = []any{v}
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("Danh sách giá trị không được rỗng")
+	}
+
+	values := make([]any, len(items))
+	for i, item := range items {
+		coerced, err := f.coerceScalar(item)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = coerced
+	}
+	return values, nil
+}
+
+// coerceContains converts the value of a containment check: a list of elements for array
+// fields, a JSON document for JSON fields
+func (f Field) coerceContains(value any) (any, error) {
+	switch f.Type {
+	case FieldArray:
+		return f.coerceList(value)
+	case FieldJSON:
+		doc := fmt.Sprint(value)
+		if !json.Valid([]byte(doc)) {
+			return nil, fmt.Errorf("Giá trị %q không phải JSON hợp lệ", doc)
+		}
+		return doc, nil
+	default:
+		return nil, fmt.Errorf("Toán tử contains chỉ áp dụng cho trường mảng hoặc JSON")
+	}
+}
+
+func (f Field) coerceScalar(value any) (any, error) {
+	if value == nil {
+		return nil, fmt.Errorf("Danh sách không được chứa null")
+	}
+
+	switch f.Type {
+	case FieldInt:
+		switch v := value.(type) {
+		case int64:
+			return v, nil
+		case float64:
+			if v != math.Trunc(v) {
+				return nil, fmt.Errorf("Giá trị %v không phải số nguyên", v)
+			}
+			return int64(v), nil
+		case string:
+			i, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("Giá trị %q không phải số nguyên", v)
+			}
+			return i, nil
+		}
+	case FieldBool:
+		switch v := value.(type) {
+		case bool:
+			return v, nil
+		case string:
+			b, err := strconv.ParseBool(strings.TrimSpace(v))
+			if err != nil {
+				return nil, fmt.Errorf("Giá trị %q không phải true/false", v)
+			}
+			return b, nil
+		}
+	case FieldTime:
+		if s, ok := value.(string); ok {
+			for _, layout := range timeLayouts {
+				if t, err := time.Parse(layout, strings.TrimSpace(s)); err == nil {
+					return t, nil
+				}
+			}
+			return nil, fmt.Errorf("Giá trị %q không phải thời gian hợp lệ (RFC 3339 hoặc YYYY-MM-DD)", s)
+		}
+	case FieldEnum:
+		s := fmt.Sprint(value)
+		for _, allowed := range f.Values {
+			if strings.EqualFold(s, allowed) {
+				return allowed, nil
+			}
+		}
+		return nil, fmt.Errorf("Giá trị %q không hợp lệ, chấp nhận: %s", s, strings.Join(f.Values, ", "))
+	case FieldUUID:
+		// Canonical form, so that text columns compare equal to what the database stores
+		if s, ok := value.(string); ok {
+			id, err := uuid.Parse(strings.TrimSpace(s))
+			if err != nil {
+				return nil, fmt.Errorf("Giá trị %q không phải UUID hợp lệ", s)
+			}
+			return id.String(), nil
+		}
+	default:
+		return fmt.Sprint(value), nil
+	}
+
+	return nil, fmt.Errorf("Giá trị %v không đúng kiểu %s", value, f.Type)
+}
@@ -0,0 +1,93 @@
+package query
+
+import (
+	"errors"
+	"testing"
+
+	apperror "github.com/thienel/go-backend-template/pkg/error"
+)
+
+func TestParseQueryParamsRejectsValues(t *testing.T) {
+	tests := []struct {
+		name   string
+		params map[string]string
+		field  string
+	}{
+		{name: "int that is not a number", params: map[string]string{"age": "abc"}, field: "age"},
+		{name: "int that is fractional", params: map[string]string{"filter": "age = 1.5"}, field: "age"},
+		{name: "enum value not allowed", params: map[string]string{"status": "BOGUS"}, field: "status"},
+		{name: "invalid time", params: map[string]string{"created_at[gte]": "yesterday"}, field: "created_at"},
+		{name: "invalid UUID", params: map[string]string{"id": "42"}, field: "id"},
+		{name: "invalid UUID in list", params: map[string]string{"id[in]": "0190a0e0-0000-7000-8000-000000000001,42"}, field: "id"},
+		{name: "between with one bound", params: map[string]string{"created_at[between]": "2024-01-01"}, field: "created_at"},
+		{name: "null for a comparison", params: map[string]string{"filter": "age > null"}, field: "age"},
+		{name: "invalid regular expression", params: map[string]string{"name[regex]": "("}, field: "name"},
+		{name: "invalid JSON document", params: map[string]string{"meta[contains]": "{"}, field: "meta"},
+		{name: "operator not enabled for the field", params: map[string]string{"age[like]": "1"}, field: "age"},
+		{name: "unknown operator", params: map[string]string{"age[near]": "1"}, field: "age"},
+		{name: "unknown field in expression", params: map[string]string{"filter": "password = 'x'"}, field: "password"},
+		{name: "malformed expression", params: map[string]string{"filter": "age >"}, field: "filter"},
+		{name: "virtual field in OR", params: map[string]string{"filter": "q = 'x' or age = 1"}, field: "q"},
+		{name: "virtual field in NOT", params: map[string]string{"filter": "not (q = 'x')"}, field: "q"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseQueryParams(tt.params, testSchema)
+			var appErr *apperror.AppError
+			if !errors.As(err, &appErr) {
+				t.Fatalf("err = %v, want a validation error", err)
+			}
+			if appErr.Code != apperror.ErrValidation.Code {
+				t.Errorf("code = %v, want %v", appErr.Code, apperror.ErrValidation.Code)
+			}
+			if len(appErr.Fields) != 1 || appErr.Fields[0].Field != tt.field {
+				t.Errorf("fields = %+v, want one error on %q", appErr.Fields, tt.field)
+			}
+		})
+	}
+}
+
+func TestFieldCoerce(t *testing.T) {
+	tests := []struct {
+		name     string
+		field    Field
+		operator string
+		value    any
+		want     any
+		wantErr  bool
+	}{
+		{name: "int from string", field: Field{Type: FieldInt}, operator: "eq", value: " 42 ", want: int64(42)},
+		{name: "int from whole float", field: Field{Type: FieldInt}, operator: "eq", value: float64(42), want: int64(42)},
+		{name: "int from bool", field: Field{Type: FieldInt}, operator: "eq", value: true, wantErr: true},
+		{name: "bool", field: Field{Type: FieldBool}, operator: "eq", value: "TRUE", want: true},
+		{name: "bool from word", field: Field{Type: FieldBool}, operator: "eq", value: "yes", wantErr: true},
+		{name: "enum is case-insensitive", field: Field{Type: FieldEnum, Values: []string{"ACTIVE"}}, operator: "eq", value: "active", want: "ACTIVE"},
+		{name: "null for eq", field: Field{Type: FieldInt}, operator: "eq", value: nil, want: nil},
+		{name: "null in list", field: Field{Type: FieldInt}, operator: "in", value: []any{int64(1), nil}, wantErr: true},
+		{name: "empty list", field: Field{Type: FieldInt}, operator: "in", value: []any{}, wantErr: true},
+		{name: "flag defaults to true", field: Field{Type: FieldTime}, operator: "isnull", value: nil, want: true},
+		{name: "list for a scalar operator", field: Field{Type: FieldString}, operator: "like", value: []any{"a", "b"}, wantErr: true},
+		{name: "contains on a string field", field: Field{Type: FieldString}, operator: "contains", value: "a", wantErr: true},
+		{name: "UUID is canonicalized", field: Field{Type: FieldUUID}, operator: "eq", value: "0190A0E0-0000-7000-8000-000000000001", want: "0190a0e0-0000-7000-8000-000000000001"},
+		{name: "UUID from number", field: Field{Type: FieldUUID}, operator: "eq", value: int64(1), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.field.coerce(tt.operator, tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("coerce = %#v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("coerce: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("coerce = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
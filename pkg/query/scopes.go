@@ -3,6 +3,7 @@ package query
 import (
 	"fmt"
 	"regexp"
+	"strings"
 
 	"gorm.io/gorm"
 )
@@ -10,37 +11,34 @@ import (
 // validFieldName validates that a field name contains only safe characters
 var validFieldName = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
 
-// ApplyFilters returns a GORM scope that applies all filters dynamically
-func ApplyFilters(opts QueryOptions, allowedFields map[string]bool) func(*gorm.DB) *gorm.DB {
+// ApplyFilters returns a GORM scope that applies the filter expression tree dynamically
+func ApplyFilters(opts QueryOptions, schema Schema) func(*gorm.DB) *gorm.DB {
 	return func(db *gorm.DB) *gorm.DB {
-		for field, filter := range opts.Filters {
-			if !allowedFields[field] {
-				continue
-			}
-			if !validFieldName.MatchString(field) {
-				continue
-			}
-			db = applyFilter(db, field, filter)
+		sql, args, err := compileFilter(dialectOf(db), opts.Filter, schema)
+		if err != nil {
+			_ = db.AddError(err)
+			return db
 		}
-		return db
+		if sql == "" {
+			return db
+		}
+		return db.Where(sql, args...)
 	}
 }
 
 // ApplySort returns a GORM scope that applies all sort fields dynamically
-func ApplySort(opts QueryOptions, allowedFields map[string]bool) func(*gorm.DB) *gorm.DB {
+func ApplySort(opts QueryOptions, schema Schema) func(*gorm.DB) *gorm.DB {
 	return func(db *gorm.DB) *gorm.DB {
 		for _, sort := range opts.Sort {
-			if !allowedFields[sort.Field] {
-				continue
-			}
-			if !validFieldName.MatchString(sort.Field) {
+			column, ok := schema.column(sort.Field)
+			if !ok {
 				continue
 			}
 			direction := "ASC"
 			if sort.Desc {
 				direction = "DESC"
 			}
-			db = db.Order(fmt.Sprintf("%s %s", sort.Field, direction))
+			db = db.Order(fmt.Sprintf("%s %s", column, direction))
 		}
 		return db
 	}
@@ -60,28 +58,62 @@ func ApplyDefaultSort(opts QueryOptions, defaultField string, defaultDesc bool)
 	}
 }
 
-// applyFilter applies a single filter to the query based on operator
-func applyFilter(db *gorm.DB, field string, filter FilterValue) *gorm.DB {
-	switch filter.Operator {
-	case "eq":
-		return db.Where(fmt.Sprintf("%s = ?", field), filter.Value)
-	case "ne":
-		return db.Where(fmt.Sprintf("%s != ?", field), filter.Value)
-	case "gt":
-		return db.Where(fmt.Sprintf("%s > ?", field), filter.Value)
-	case "gte":
-		return db.Where(fmt.Sprintf("%s >= ?", field), filter.Value)
-	case "lt":
-		return db.Where(fmt.Sprintf("%s < ?", field), filter.Value)
-	case "lte":
-		return db.Where(fmt.Sprintf("%s <= ?", field), filter.Value)
-	case "like":
-		return db.Where(fmt.Sprintf("%s ILIKE ?", field), fmt.Sprintf("%%%v%%", filter.Value))
-	case "in":
-		return db.Where(fmt.Sprintf("%s IN ?", field), filter.Value)
-	case "nin":
-		return db.Where(fmt.Sprintf("%s NOT IN ?", field), filter.Value)
-	default:
+// ApplyDeletedScope returns a GORM scope that controls soft-deleted row visibility
+func ApplyDeletedScope(opts QueryOptions) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if opts.OnlyDeleted {
+			return db.Unscoped().Where("deleted_at IS NOT NULL")
+		}
+		if opts.IncludeDeleted {
+			return db.Unscoped()
+		}
 		return db
 	}
 }
+
+// compileFilter compiles an expression tree into a parenthesized SQL condition and its arguments.
+// Conditions on virtual or unknown fields are dropped, and so is a group left without
+// conditions. An unknown operator, or one the field or the dialect does not support, is
+// an error.
+func compileFilter(d dialect, expr FilterExpr, schema Schema) (string, []any, error) {
+	if !expr.IsGroup() {
+		column, ok := schema.column(expr.Field)
+		if !ok {
+			return "", nil, nil
+		}
+		field, _ := schema.Lookup(expr.Field)
+		if _, known := operatorValues[expr.Operator]; !known {
+			return "", nil, fmt.Errorf("unknown filter operator %q", expr.Operator)
+		}
+		if !field.AllowsOperator(expr.Operator) {
+			return "", nil, fmt.Errorf("filter operator %q is not enabled for field %q", expr.Operator, expr.Field)
+		}
+		return conditionSQL(d, column, field, expr.Operator, expr.Value)
+	}
+
+	var parts []string
+	var args []any
+	for _, child := range expr.Children {
+		sql, childArgs, err := compileFilter(d, child, schema)
+		if err != nil {
+			return "", nil, err
+		}
+		if sql == "" {
+			continue
+		}
+		parts = append(parts, sql)
+		args = append(args, childArgs...)
+	}
+	if len(parts) == 0 {
+		return "", nil, nil
+	}
+
+	switch expr.Logic {
+	case LogicOr:
+		return "(" + strings.Join(parts, " OR ") + ")", args, nil
+	case LogicNot:
+		return "NOT (" + strings.Join(parts, " AND ") + ")", args, nil
+	default:
+		return "(" + strings.Join(parts, " AND ") + ")", args, nil
+	}
+}
@@ -0,0 +1,182 @@
+package query
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// testSchema covers every field type and the operators that compile differently per dialect
+var testSchema = Schema{
+	"name":       {Type: FieldString, Operators: []string{"eq", "like", "clike", "startswith", "regex", "iregex", "in"}},
+	"age":        {Type: FieldInt},
+	"status":     {Type: FieldEnum, Values: []string{"ACTIVE", "LOCKED"}},
+	"created_at": {Type: FieldTime, Operators: []string{"between", "gte"}},
+	"deleted_at": {Type: FieldTime, Operators: []string{"isnull"}, Nullable: true},
+	"tags":       {Type: FieldArray, Operators: []string{"contains"}},
+	"meta":       {Type: FieldJSON, Operators: []string{"contains"}},
+	"id":         {Type: FieldUUID, Column: "public_id"},
+	"q":          {Type: FieldString, Virtual: true},
+}
+
+// compiled is the SQL a filter compiles to on one dialect; unsupported marks an error
+type compiled struct {
+	sql         string
+	args        []any
+	unsupported bool
+}
+
+func TestParseAndCompileFilter(t *testing.T) {
+	sameOnAll := func(c compiled) map[dialect]compiled {
+		return map[dialect]compiled{DialectPostgres: c, DialectMySQL: c, DialectSQLite: c}
+	}
+
+	tests := []struct {
+		name   string
+		params map[string]string
+		want   map[dialect]compiled
+	}{
+		{
+			name:   "conditions are and-ed with coerced values",
+			params: map[string]string{"age[gt]": "18", "status": "active"},
+			want:   sameOnAll(compiled{sql: "(age > ? AND status = ?)", args: []any{int64(18), "ACTIVE"}}),
+		},
+		{
+			name:   "expression with nesting and negation",
+			params: map[string]string{"filter": "age >= 18 and (status = 'LOCKED' or not name in ('a', 'b'))"},
+			want: sameOnAll(compiled{
+				sql:  "((age >= ? AND (status = ? OR NOT (name IN ?))))",
+				args: []any{int64(18), "LOCKED", []any{"a", "b"}},
+			}),
+		},
+		{
+			name:   "like escapes wildcards",
+			params: map[string]string{"name[like]": "50%_off"},
+			want: map[dialect]compiled{
+				DialectPostgres: {sql: "(name ILIKE ?)", args: []any{`%50\%\_off%`}},
+				DialectMySQL:    {sql: "(LOWER(name) LIKE LOWER(?))", args: []any{`%50\%\_off%`}},
+				DialectSQLite:   {sql: `(name LIKE ? ESCAPE '\')`, args: []any{`%50\%\_off%`}},
+			},
+		},
+		{
+			name:   "case-sensitive like",
+			params: map[string]string{"name[clike]": "Bob"},
+			want: map[dialect]compiled{
+				DialectPostgres: {sql: "(name LIKE ?)", args: []any{"%Bob%"}},
+				DialectMySQL:    {sql: "(CAST(name AS BINARY) LIKE CAST(? AS BINARY))", args: []any{"%Bob%"}},
+				DialectSQLite:   {sql: "(instr(name, ?) > 0)", args: []any{"Bob"}},
+			},
+		},
+		{
+			name:   "regular expression",
+			params: map[string]string{"name[regex]": "^a.*"},
+			want: map[dialect]compiled{
+				DialectPostgres: {sql: "(name ~ ?)", args: []any{"^a.*"}},
+				DialectMySQL:    {sql: "(REGEXP_LIKE(name, ?, 'c'))", args: []any{"^a.*"}},
+				DialectSQLite:   {unsupported: true},
+			},
+		},
+		{
+			name:   "case-insensitive regular expression",
+			params: map[string]string{"name[iregex]": "^a"},
+			want: map[dialect]compiled{
+				DialectPostgres: {sql: "(name ~* ?)", args: []any{"^a"}},
+				DialectMySQL:    {sql: "(REGEXP_LIKE(name, ?, 'i'))", args: []any{"^a"}},
+				DialectSQLite:   {unsupported: true},
+			},
+		},
+		{
+			name:   "between",
+			params: map[string]string{"created_at[between]": "2024-01-01,2024-12-31"},
+			want: sameOnAll(compiled{
+				sql:  "(created_at BETWEEN ? AND ?)",
+				args: []any{time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)},
+			}),
+		},
+		{
+			name:   "isnull false",
+			params: map[string]string{"deleted_at[isnull]": "false"},
+			want:   sameOnAll(compiled{sql: "(deleted_at IS NOT NULL)"}),
+		},
+		{
+			name:   "array containment",
+			params: map[string]string{"tags[contains]": "a,b"},
+			want: map[dialect]compiled{
+				DialectPostgres: {sql: "(tags @> CAST(ARRAY[?,?] AS text[]))", args: []any{"a", "b"}},
+				DialectMySQL:    {unsupported: true},
+				DialectSQLite:   {unsupported: true},
+			},
+		},
+		{
+			name:   "JSON containment",
+			params: map[string]string{"meta[contains]": `["x",1]`},
+			want: map[dialect]compiled{
+				DialectPostgres: {sql: "(meta @> CAST(? AS jsonb))", args: []any{`["x",1]`}},
+				DialectMySQL:    {sql: "(JSON_CONTAINS(meta, ?))", args: []any{`["x",1]`}},
+				DialectSQLite: {
+					sql:  "((EXISTS (SELECT 1 FROM json_each(meta) WHERE json_each.value = ?) AND EXISTS (SELECT 1 FROM json_each(meta) WHERE json_each.value = ?)))",
+					args: []any{"x", float64(1)},
+				},
+			},
+		},
+		{
+			name:   "UUID in canonical form on the mapped column",
+			params: map[string]string{"id": "0190A0E0-0000-7000-8000-000000000001"},
+			want:   sameOnAll(compiled{sql: "(public_id = ?)", args: []any{"0190a0e0-0000-7000-8000-000000000001"}}),
+		},
+		{
+			name:   "virtual fields are left to the repository",
+			params: map[string]string{"q": "abc"},
+			want:   sameOnAll(compiled{}),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := ParseQueryParams(tt.params, testSchema)
+			if err != nil {
+				t.Fatalf("ParseQueryParams: %v", err)
+			}
+
+			for d, want := range tt.want {
+				sql, args, err := compileFilter(d, opts.Filter, testSchema)
+				if want.unsupported {
+					if err == nil {
+						t.Errorf("%s: compiled to %q, want an error", d, sql)
+					}
+					continue
+				}
+				if err != nil {
+					t.Errorf("%s: %v", d, err)
+					continue
+				}
+				if sql != want.sql {
+					t.Errorf("%s: SQL = %q, want %q", d, sql, want.sql)
+				}
+				if len(args) != 0 || len(want.args) != 0 {
+					if !reflect.DeepEqual(args, want.args) {
+						t.Errorf("%s: args = %#v, want %#v", d, args, want.args)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestCompileFilterRejectsOperators(t *testing.T) {
+	tests := []struct {
+		name string
+		expr FilterExpr
+	}{
+		{name: "unknown operator", expr: Cond("age", "near", int64(1))},
+		{name: "operator not enabled for the field", expr: Cond("age", "like", "1")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := compileFilter(DialectPostgres, And(tt.expr), testSchema); err == nil {
+				t.Error("compileFilter succeeded, want an error")
+			}
+		})
+	}
+}
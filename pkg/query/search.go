@@ -0,0 +1,196 @@
+package query
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"gorm.io/gorm"
+)
+
+// SearchFilterField is the virtual filter field that carries the search term
+const SearchFilterField = "search"
+
+// SearchField is a column included in full-text search
+type SearchField struct {
+	Column string
+	// Weight ranks matches in this column: A (highest) to D
+	Weight string
+}
+
+// SearchConfig declares how an entity is searched with Postgres full-text search:
+// a generated tsvector column over the searchable fields with a GIN index, optionally
+// combined with pg_trgm similarity for typos and partial words. The column and indexes
+// themselves are created by a migration. Other databases fall back to matching every
+// word as a substring of one of the fields, without ranking or highlights.
+type SearchConfig struct {
+	Table string
+	// Language is the text search configuration, e.g. "simple" or "english"
+	Language     string
+	VectorColumn string
+	Fields       []SearchField
+	// TrigramColumns are also matched by trigram similarity; requires the pg_trgm extension,
+	// so leave it empty where the extension may be missing
+	TrigramColumns []string
+}
+
+// SearchTerm returns the search term of opts, if any
+func SearchTerm(opts QueryOptions) (string, bool) {
+	filter, ok := opts.FindFilter(SearchFilterField)
+	if !ok || filter.Value == nil {
+		return "", false
+	}
+	term := strings.TrimSpace(fmt.Sprint(filter.Value))
+	return term, term != ""
+}
+
+// searchWords splits free text into words, keeping only letters and digits
+func searchWords(term string) []string {
+	return strings.FieldsFunc(term, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// prefixQuery turns free text into a tsquery that matches every word as a prefix,
+// e.g. "john do" becomes "john:* & do:*"
+func prefixQuery(term string) string {
+	words := searchWords(term)
+	for i, word := range words {
+		words[i] = word + ":*"
+	}
+	return strings.Join(words, " & ")
+}
+
+// ApplySearch returns a GORM scope that keeps the rows matching term
+func ApplySearch(term string, cfg SearchConfig) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if d := dialectOf(db); !d.fullText() {
+			return applySubstringSearch(db, d, term, cfg)
+		}
+
+		var conditions []string
+		var args []any
+
+		if tsquery := prefixQuery(term); tsquery != "" {
+			conditions = append(conditions, fmt.Sprintf("%s @@ to_tsquery('%s', ?)", cfg.VectorColumn, cfg.Language))
+			args = append(args, tsquery)
+		}
+		for _, column := range cfg.TrigramColumns {
+			conditions = append(conditions, column+" % ?")
+			args = append(args, term)
+		}
+
+		if len(conditions) == 0 {
+			return db.Where("1 = 0")
+		}
+		return db.Where("("+strings.Join(conditions, " OR ")+")", args...)
+	}
+}
+
+// applySubstringSearch keeps the rows where every word of term is found, ignoring case,
+// in at least one of the searchable fields
+func applySubstringSearch(db *gorm.DB, d dialect, term string, cfg SearchConfig) *gorm.DB {
+	words := searchWords(term)
+	if len(words) == 0 || len(cfg.Fields) == 0 {
+		return db.Where("1 = 0")
+	}
+
+	var conditions []string
+	var args []any
+	for _, word := range words {
+		matches := make([]string, len(cfg.Fields))
+		for i, f := range cfg.Fields {
+			sql, fieldArgs := d.like(f.Column, "%"+likeEscaper.Replace(word)+"%", false)
+			matches[i] = sql
+			args = append(args, fieldArgs...)
+		}
+		conditions = append(conditions, "("+strings.Join(matches, " OR ")+")")
+	}
+	return db.Where(strings.Join(conditions, " AND "), args...)
+}
+
+// ApplySearchRank returns a GORM scope that orders the rows by relevance to term,
+// full-text rank first and trigram similarity second. GORM cannot bind arguments in
+// ORDER BY together with other sort columns, so the term is inlined; it is reduced to
+// letters, digits and spaces first, which makes the literal safe.
+func ApplySearchRank(term string, cfg SearchConfig) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		tsquery := prefixQuery(term)
+		if tsquery == "" || !dialectOf(db).fullText() {
+			return db
+		}
+
+		db = db.Order(fmt.Sprintf("ts_rank(%s, to_tsquery('%s', '%s')) DESC", cfg.VectorColumn, cfg.Language, tsquery))
+
+		if len(cfg.TrigramColumns) > 0 {
+			words := strings.Join(searchWords(term), " ")
+			similarities := make([]string, len(cfg.TrigramColumns))
+			for i, column := range cfg.TrigramColumns {
+				similarities[i] = fmt.Sprintf("similarity(%s, '%s')", column, words)
+			}
+			if len(similarities) == 1 {
+				db = db.Order(similarities[0] + " DESC")
+			} else {
+				db = db.Order("GREATEST(" + strings.Join(similarities, ", ") + ") DESC")
+			}
+		}
+		return db
+	}
+}
+
+// SearchHighlights returns, for each item, snippets of the searchable fields with the
+// matched words wrapped in <mark>; fields without a match are left out
+func SearchHighlights[T any](db *gorm.DB, cfg SearchConfig, term string, items []T) ([]map[string]string, error) {
+	tsquery := prefixQuery(term)
+	if tsquery == "" || len(items) == 0 || !dialectOf(db).fullText() {
+		return nil, nil
+	}
+
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(new(T)); err != nil {
+		return nil, err
+	}
+	primary := stmt.Schema.PrioritizedPrimaryField
+	if primary == nil {
+		return nil, fmt.Errorf("search: %s has no primary key", stmt.Schema.Name)
+	}
+
+	ids := make([]any, len(items))
+	for i := range items {
+		ids[i], _ = primary.ValueOf(db.Statement.Context, reflect.ValueOf(&items[i]).Elem())
+	}
+
+	selects := []string{primary.DBName + " AS id"}
+	var args []any
+	for _, f := range cfg.Fields {
+		selects = append(selects, fmt.Sprintf(
+			"ts_headline('%s', coalesce(%s, ''), to_tsquery('%s', ?), 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true') AS %s",
+			cfg.Language, f.Column, cfg.Language, f.Column))
+		args = append(args, tsquery)
+	}
+	args = append(args, ids)
+
+	var rows []map[string]any
+	sql := fmt.Sprintf("SELECT %s FROM %s WHERE %s IN ?", strings.Join(selects, ", "), cfg.Table, primary.DBName)
+	if err := db.Raw(sql, args...).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string]map[string]string, len(rows))
+	for _, row := range rows {
+		snippets := make(map[string]string)
+		for _, f := range cfg.Fields {
+			if snippet, ok := row[f.Column].(string); ok && strings.Contains(snippet, "<mark>") {
+				snippets[f.Column] = snippet
+			}
+		}
+		byID[fmt.Sprint(row["id"])] = snippets
+	}
+
+	highlights := make([]map[string]string, len(items))
+	for i, id := range ids {
+		highlights[i] = byID[fmt.Sprint(id)]
+	}
+	return highlights, nil
+}
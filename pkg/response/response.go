@@ -67,6 +67,18 @@ func ValidationError(c *gin.Context, fields []FieldError) {
 	})
 }
 
+// toFieldErrors converts application field errors to their response form
+func toFieldErrors(fields []apperror.FieldError) []FieldError {
+	if len(fields) == 0 {
+		return nil
+	}
+	result := make([]FieldError, len(fields))
+	for i, f := range fields {
+		result[i] = FieldError{Field: f.Field, Message: f.Message}
+	}
+	return result
+}
+
 // getStackTrace captures the stack trace for debugging
 func getStackTrace(skip int) string {
 	const maxStackLen = 2048
@@ -120,6 +132,7 @@ func WriteErrorResponse(c *gin.Context, err error) {
 			Error: &Error{
 				Code:    appErr.Code,
 				Message: appErr.Message,
+				Fields:  toFieldErrors(appErr.Fields),
 			},
 		})
 		return